@@ -9,6 +9,8 @@ const (
 	BadRequestCode    = "05"
 	ConflictCode      = "06"
 	UnprocessableCode = "07"
+	ForbiddenCode     = "08"
+	UnauthorizedCode  = "09"
 	InternalErrorCode = "99"
 
 	SuccessMessage       = "OK	Sukses memproses permintaan"
@@ -19,6 +21,8 @@ const (
 	BadRequestMessage    = "Bad Request	Validasi gagal (misalnya field kosong, format salah)"
 	ConflictMessage      = "Conflict	Terjadi konflik hirarki (misalnya parent sudah punya child unik tertentu)"
 	UnprocessableMessage = "Unprocessable Entity	Hirarki tidak valid (misalnya loop/circular reference)"
+	ForbiddenMessage     = "Forbidden	Role tidak memiliki izin untuk aksi ini"
+	UnauthorizedMessage  = "Unauthorized	Token tidak valid untuk endpoint ini"
 	InternalErrorMessage = "Internal Server Error	Error tak terduga pada server"
 )
 const (
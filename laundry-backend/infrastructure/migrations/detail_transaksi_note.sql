@@ -0,0 +1,5 @@
+-- Script to support multi-line orders in inquiry
+
+-- Add per-item note column to detail_transaksi
+ALTER TABLE detail_transaksi
+ADD COLUMN IF NOT EXISTS catatan TEXT;
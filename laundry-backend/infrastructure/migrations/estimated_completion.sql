@@ -0,0 +1,21 @@
+-- Script to estimate when an order will be ready
+
+-- Estimasi selesai per transaksi (item paling lama) dan per item, dihitung dari durasi_pengerjaan
+-- paket_layanan dengan melewati jam tutup dan hari libur outlet
+ALTER TABLE transaksi
+ADD COLUMN IF NOT EXISTS estimasi_selesai TIMESTAMP;
+
+ALTER TABLE detail_transaksi
+ADD COLUMN IF NOT EXISTS estimasi_selesai TIMESTAMP;
+
+-- Hari libur outlet; pada hari ini outlet dianggap tutup sepanjang hari
+CREATE TABLE IF NOT EXISTS hari_libur (
+    id_libur SERIAL PRIMARY KEY,
+    id_outlet INTEGER NOT NULL,
+    tanggal DATE NOT NULL,
+    keterangan VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (id_outlet, tanggal),
+    FOREIGN KEY (id_outlet) REFERENCES outlet(id_outlet) ON DELETE CASCADE
+);
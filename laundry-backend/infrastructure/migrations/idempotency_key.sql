@@ -0,0 +1,18 @@
+-- Script to store the outcome of requests sent with an Idempotency-Key header
+
+-- Satu baris per key per pemilik token (owner = "<aud>:<user_id>"); status_code kosong selama request masih diproses.
+-- Retry dengan key yang sama mendapat response yang tersimpan, body berbeda (request_hash) ditolak.
+CREATE TABLE IF NOT EXISTS idempotency_key (
+    id_idempotency_key SERIAL PRIMARY KEY,
+    owner VARCHAR(100) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INTEGER,
+    response_body TEXT,
+    expired_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (owner, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_key_expired ON idempotency_key(expired_at);
@@ -0,0 +1,23 @@
+-- Script to number invoices sequentially per outlet
+
+-- Format nomor invoice per brand, placeholder: {BRAND} kode_brand, {OUTLET} kode_outlet,
+-- {YYYY}/{YY}/{MM}/{DD} tanggal transaksi, {SEQ} atau {SEQ:n} nomor urut dengan n digit
+ALTER TABLE brand
+ADD COLUMN IF NOT EXISTS kode_brand VARCHAR(10) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS format_invoice VARCHAR(100) NOT NULL DEFAULT '{BRAND}-{OUTLET}-{YYMM}-{SEQ:5}';
+
+ALTER TABLE outlet
+ADD COLUMN IF NOT EXISTS kode_outlet VARCHAR(10) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_outlet_kode ON outlet(kode_outlet) WHERE kode_outlet <> '';
+
+-- Nomor urut terakhir per outlet per periode (periode mengikuti placeholder tanggal terkecil di format, kosong bila tidak ada).
+-- Baris dikunci oleh transaksi yang menaikkan nomornya sampai commit, sehingga nomor tidak bolong dan tidak dobel.
+CREATE TABLE IF NOT EXISTS urutan_invoice (
+    id_outlet INTEGER NOT NULL,
+    periode VARCHAR(8) NOT NULL,
+    nomor_terakhir INTEGER NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_outlet, periode),
+    FOREIGN KEY (id_outlet) REFERENCES outlet(id_outlet) ON DELETE CASCADE
+);
@@ -0,0 +1,22 @@
+-- Script to protect both logins against password guessing
+
+-- Percobaan gagal berturut-turut per username; saat mencapai batas, akun dikunci sampai locked_until
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+ALTER TABLE user_access ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_access ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+
+-- Audit setiap percobaan login; percobaan gagal per IP dihitung dari tabel ini
+CREATE TABLE IF NOT EXISTS login_audit (
+    id_login_audit SERIAL PRIMARY KEY,
+    login_type VARCHAR(20) NOT NULL CHECK (login_type IN ('users', 'user_access')),
+    username VARCHAR(100) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_audit_ip ON login_audit(ip_address, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_audit_username ON login_audit(username, created_at);
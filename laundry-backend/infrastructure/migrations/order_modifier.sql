@@ -0,0 +1,51 @@
+-- Script to add surcharge and add-on modifiers to order lines
+
+-- Modifier milik brand: surcharge (mis. express 6 jam, +50%) atau add-on (mis. parfum, hanger, plastik).
+-- Modifier dengan id_layanan hanya berlaku untuk layanan itu, dengan id_kategori untuk layanan di kategori itu,
+-- tanpa keduanya untuk semua layanan brand. durasi_pengerjaan menggantikan durasi layanan (layanan express)
+CREATE TABLE IF NOT EXISTS modifier_layanan (
+    id_modifier SERIAL PRIMARY KEY,
+    id_brand INTEGER NOT NULL,
+    id_layanan INTEGER,
+    id_kategori INTEGER,
+    nama VARCHAR(100) NOT NULL,
+    jenis VARCHAR(10) NOT NULL CHECK (jenis IN ('surcharge', 'addon')),
+    tipe_nilai VARCHAR(10) NOT NULL CHECK (tipe_nilai IN ('persen', 'flat')),
+    nilai DECIMAL(10, 2) NOT NULL CHECK (nilai >= 0),
+    durasi_pengerjaan INTEGER,
+    satuan_durasi VARCHAR(10) CHECK (satuan_durasi IN ('jam', 'hari')),
+    aktif BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (jenis = 'surcharge' OR tipe_nilai = 'flat'),
+    CHECK (id_layanan IS NULL OR id_kategori IS NULL),
+    FOREIGN KEY (id_brand) REFERENCES brand(id_brand) ON DELETE CASCADE,
+    FOREIGN KEY (id_layanan) REFERENCES paket_layanan(id_layanan) ON DELETE CASCADE,
+    FOREIGN KEY (id_kategori) REFERENCES kategori_layanan(id_kategori) ON DELETE CASCADE
+);
+
+-- harga_dasar = harga_satuan x kuantitas; subtotal = harga_dasar + total_biaya_tambahan
+ALTER TABLE detail_transaksi
+ADD COLUMN IF NOT EXISTS harga_dasar DECIMAL(15, 2),
+ADD COLUMN IF NOT EXISTS total_biaya_tambahan DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+UPDATE detail_transaksi SET harga_dasar = subtotal WHERE harga_dasar IS NULL;
+
+-- Salinan modifier saat transaksi dibuat, agar struk dan laporan tidak berubah saat modifier diubah atau dihapus
+CREATE TABLE IF NOT EXISTS detail_transaksi_modifier (
+    id SERIAL PRIMARY KEY,
+    id_detail INTEGER NOT NULL,
+    id_modifier INTEGER,
+    nama VARCHAR(100) NOT NULL,
+    jenis VARCHAR(10) NOT NULL,
+    tipe_nilai VARCHAR(10) NOT NULL,
+    nilai DECIMAL(10, 2) NOT NULL,
+    kuantitas DECIMAL(5, 2) NOT NULL DEFAULT 1,
+    jumlah DECIMAL(15, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_detail) REFERENCES detail_transaksi(id_detail) ON DELETE CASCADE,
+    FOREIGN KEY (id_modifier) REFERENCES modifier_layanan(id_modifier) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_modifier_layanan_brand ON modifier_layanan(id_brand);
+CREATE INDEX IF NOT EXISTS idx_detail_transaksi_modifier_detail ON detail_transaksi_modifier(id_detail);
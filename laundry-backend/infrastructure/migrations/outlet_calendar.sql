@@ -0,0 +1,34 @@
+-- Script to give outlets an operating calendar with national, brand and outlet holidays
+
+-- Jam operasional per hari (0 = Minggu ... 6 = Sabtu); hari tanpa baris memakai outlet.jam_buka/jam_tutup.
+-- jam_tutup sebelum atau sama dengan jam_buka berarti outlet tutup lewat tengah malam
+CREATE TABLE IF NOT EXISTS jam_operasional_outlet (
+    id_outlet INTEGER NOT NULL,
+    hari SMALLINT NOT NULL CHECK (hari BETWEEN 0 AND 6),
+    jam_buka TIME,
+    jam_tutup TIME,
+    tutup BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_outlet, hari),
+    CHECK (tutup OR (jam_buka IS NOT NULL AND jam_tutup IS NOT NULL)),
+    FOREIGN KEY (id_outlet) REFERENCES outlet(id_outlet) ON DELETE CASCADE
+);
+
+-- Hari libur nasional berlaku untuk semua outlet, libur brand untuk semua outlet brand tersebut
+ALTER TABLE hari_libur
+ALTER COLUMN id_outlet DROP NOT NULL,
+ADD COLUMN IF NOT EXISTS id_brand INTEGER REFERENCES brand(id_brand) ON DELETE CASCADE,
+ADD COLUMN IF NOT EXISTS jenis VARCHAR(10) NOT NULL DEFAULT 'outlet' CHECK (jenis IN ('nasional', 'brand', 'outlet'));
+
+ALTER TABLE hari_libur
+DROP CONSTRAINT IF EXISTS hari_libur_id_outlet_tanggal_key,
+DROP CONSTRAINT IF EXISTS hari_libur_jenis_referensi_check,
+ADD CONSTRAINT hari_libur_jenis_referensi_check CHECK (
+    (jenis = 'nasional' AND id_brand IS NULL AND id_outlet IS NULL) OR
+    (jenis = 'brand' AND id_brand IS NOT NULL AND id_outlet IS NULL) OR
+    (jenis = 'outlet' AND id_outlet IS NOT NULL AND id_brand IS NULL)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_hari_libur ON hari_libur(jenis, COALESCE(id_brand, 0), COALESCE(id_outlet, 0), tanggal);
+CREATE INDEX IF NOT EXISTS idx_hari_libur_tanggal ON hari_libur(tanggal);
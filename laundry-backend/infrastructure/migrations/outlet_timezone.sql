@@ -0,0 +1,6 @@
+-- Script to give every outlet its own time zone
+
+-- Zona waktu IANA outlet (Asia/Jakarta = WIB, Asia/Makassar = WITA, Asia/Jayapura = WIT);
+-- jam operasional, hari libur dan estimasi selesai dihitung dalam zona waktu ini
+ALTER TABLE outlet
+ADD COLUMN IF NOT EXISTS zona_waktu VARCHAR(50) NOT NULL DEFAULT 'Asia/Jakarta';
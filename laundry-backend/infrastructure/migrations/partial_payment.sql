@@ -0,0 +1,7 @@
+-- Script to support down payments and partial payments
+
+-- Aturan outlet: transaksi harus lunas sebelum status diambil
+ALTER TABLE outlet
+ADD COLUMN IF NOT EXISTS wajib_lunas_saat_diambil BOOLEAN DEFAULT true;
+
+-- uang_bayar dan uang_kembalian transaksi diperbarui dari SUM(jumlah_bayar) pembayaran yang sukses
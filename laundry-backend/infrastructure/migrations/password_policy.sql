@@ -0,0 +1,27 @@
+-- Script to enforce the password policy of user_access
+
+-- Akun dengan must_change_password hanya boleh memanggil endpoint ganti password
+ALTER TABLE user_access ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT false;
+
+-- Hash password yang pernah dipakai, untuk menolak pemakaian ulang N password terakhir
+CREATE TABLE IF NOT EXISTS password_history (
+    id_password_history SERIAL PRIMARY KEY,
+    id_access INTEGER NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_access) REFERENCES user_access(id_access) ON DELETE CASCADE
+);
+
+-- Token reset password sekali pakai yang diterbitkan admin, disimpan sebagai hash SHA-256
+CREATE TABLE IF NOT EXISTS password_reset_token (
+    id_password_reset SERIAL PRIMARY KEY,
+    id_access INTEGER NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expired_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_access) REFERENCES user_access(id_access) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_access ON password_history(id_access, created_at);
+CREATE INDEX IF NOT EXISTS idx_password_reset_token_access ON password_reset_token(id_access);
@@ -0,0 +1,22 @@
+-- Script to audit and deduplicate payment gateway callbacks
+
+-- Setiap callback mentah dari gateway dicatat, termasuk yang ditolak
+CREATE TABLE IF NOT EXISTS callback_pembayaran (
+    id_callback SERIAL PRIMARY KEY,
+    id_metode_pembayaran INTEGER,
+    id_transaksi INTEGER,
+    nomor_referensi_pembayaran VARCHAR(50),
+    payload TEXT NOT NULL,
+    signature VARCHAR(128),
+    signature_valid BOOLEAN DEFAULT false,
+    hasil VARCHAR(20) NOT NULL CHECK (hasil IN ('diproses', 'duplikat', 'ditolak')),
+    pesan TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_callback_pembayaran_referensi ON callback_pembayaran(nomor_referensi_pembayaran);
+
+-- Satu nomor referensi gateway hanya boleh tercatat sekali per metode pembayaran
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pembayaran_referensi_metode
+ON pembayaran(id_metode_pembayaran, nomor_referensi_partner)
+WHERE nomor_referensi_partner <> '';
@@ -0,0 +1,16 @@
+-- Script to apply merchant and admin fees of the payment method to transaksi totals
+
+-- merchant_fee bisa nominal tetap (flat) atau persentase (percent) dari subtotal layanan
+-- fee_bearer menentukan siapa yang menanggung biaya: pelanggan (customer) atau merchant
+ALTER TABLE metode_pembayaran
+ADD COLUMN IF NOT EXISTS merchant_fee_type VARCHAR(10) DEFAULT 'flat' CHECK (merchant_fee_type IN ('flat', 'percent')),
+ADD COLUMN IF NOT EXISTS fee_bearer VARCHAR(10) DEFAULT 'customer' CHECK (fee_bearer IN ('customer', 'merchant'));
+
+-- Rincian biaya transaksi; total_harga = subtotal_layanan (+ biaya bila ditanggung pelanggan)
+ALTER TABLE transaksi
+ADD COLUMN IF NOT EXISTS subtotal_layanan DECIMAL(15, 2),
+ADD COLUMN IF NOT EXISTS biaya_admin DECIMAL(15, 2) DEFAULT 0,
+ADD COLUMN IF NOT EXISTS biaya_merchant DECIMAL(15, 2) DEFAULT 0,
+ADD COLUMN IF NOT EXISTS biaya_ditanggung VARCHAR(10) DEFAULT 'customer' CHECK (biaya_ditanggung IN ('customer', 'merchant'));
+
+UPDATE transaksi SET subtotal_layanan = total_harga WHERE subtotal_layanan IS NULL;
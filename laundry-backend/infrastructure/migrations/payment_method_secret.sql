@@ -0,0 +1,5 @@
+-- Script to store payment method secrets encrypted
+
+-- s_key dan m_key disimpan dalam format enc:v1:<id key>:<data key terenkripsi>:<ciphertext>.
+-- Nilai lama yang masih plain text tetap terbaca dan dienkripsi ulang oleh
+-- POST /api/v1/payment-methods/secrets/rotate, jadi tidak ada perubahan kolom.
@@ -0,0 +1,10 @@
+-- Script to move the payment lifecycle into the pembayaran table
+
+-- Status pembayaran per baris: gagal, pending, sukses
+ALTER TABLE pembayaran
+ADD COLUMN IF NOT EXISTS status_pembayaran VARCHAR(20) DEFAULT 'pending'
+CHECK (status_pembayaran IN ('gagal', 'pending', 'sukses'));
+
+CREATE INDEX IF NOT EXISTS idx_pembayaran_referensi ON pembayaran(nomor_referensi_partner);
+
+-- Status lunas/belum lunas transaksi dihitung dari SUM(jumlah_bayar) pembayaran yang sukses
@@ -0,0 +1,33 @@
+-- Script to add pricing rules (minimum charge, weight rounding and tiered prices) per service and outlet
+
+-- Aturan harga layanan; aturan dengan id_outlet menggantikan aturan umum layanan (id_outlet NULL) di outlet itu.
+-- harga_satuan NULL memakai harga paket_layanan, kuantitas_minimum adalah kuantitas minimal yang ditagih (mis. 3 kg)
+-- dan pembulatan membulatkan kuantitas ke atas ke kelipatannya (mis. 0.5 kg)
+CREATE TABLE IF NOT EXISTS aturan_harga (
+    id_aturan SERIAL PRIMARY KEY,
+    id_layanan INTEGER NOT NULL,
+    id_outlet INTEGER,
+    harga_satuan DECIMAL(10, 2) CHECK (harga_satuan >= 0),
+    kuantitas_minimum DECIMAL(7, 2) NOT NULL DEFAULT 0 CHECK (kuantitas_minimum >= 0),
+    pembulatan DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK (pembulatan >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_layanan) REFERENCES paket_layanan(id_layanan) ON DELETE CASCADE,
+    FOREIGN KEY (id_outlet) REFERENCES outlet(id_outlet) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_aturan_harga ON aturan_harga(id_layanan, COALESCE(id_outlet, 0));
+
+-- Harga bertingkat: kuantitas di atas kuantitas_dari ditagih dengan harga_satuan tingkat tersebut
+CREATE TABLE IF NOT EXISTS tingkat_harga (
+    id_tingkat SERIAL PRIMARY KEY,
+    id_aturan INTEGER NOT NULL,
+    kuantitas_dari DECIMAL(7, 2) NOT NULL CHECK (kuantitas_dari > 0),
+    harga_satuan DECIMAL(10, 2) NOT NULL CHECK (harga_satuan >= 0),
+    UNIQUE (id_aturan, kuantitas_dari),
+    FOREIGN KEY (id_aturan) REFERENCES aturan_harga(id_aturan) ON DELETE CASCADE
+);
+
+-- Kuantitas yang ditagih setelah pembulatan dan minimum; kuantitas tetap menyimpan kuantitas yang diterima
+ALTER TABLE detail_transaksi
+ADD COLUMN IF NOT EXISTS kuantitas_ditagih DECIMAL(7, 2);
@@ -0,0 +1,17 @@
+-- Script to keep user_access login sessions server-side
+
+-- Refresh token disimpan sebagai hash SHA-256; setiap refresh mencabut token lama (revoked_at)
+-- dan mengisi replaced_by dengan token penggantinya
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id_refresh_token SERIAL PRIMARY KEY,
+    id_access INTEGER NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expired_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    replaced_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_access) REFERENCES user_access(id_access) ON DELETE CASCADE,
+    FOREIGN KEY (replaced_by) REFERENCES refresh_token(id_refresh_token)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_access ON refresh_token(id_access);
@@ -0,0 +1,21 @@
+-- Script to support cancelling transaksi with refunds
+
+-- Status dibatalkan sudah ada di CHECK status_transaksi (transaction_status_history.sql);
+-- alasan pembatalan dicatat di history_status_transaksi.keterangan
+
+-- Refund penuh atau sebagian atas baris pembayaran yang sukses
+CREATE TABLE IF NOT EXISTS refund_pembayaran (
+    id_refund SERIAL PRIMARY KEY,
+    id_pembayaran INTEGER NOT NULL,
+    id_transaksi INTEGER NOT NULL,
+    nomor_referensi_partner VARCHAR(50),
+    jumlah_refund DECIMAL(12, 2) NOT NULL CHECK (jumlah_refund >= 0),
+    status_refund VARCHAR(20) DEFAULT 'pending' CHECK (status_refund IN ('gagal', 'pending', 'sukses')),
+    alasan TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(100),
+    FOREIGN KEY (id_pembayaran) REFERENCES pembayaran(id_pembayaran) ON DELETE CASCADE,
+    FOREIGN KEY (id_transaksi) REFERENCES transaksi(id_transaksi) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_refund_pembayaran_transaksi ON refund_pembayaran(id_transaksi);
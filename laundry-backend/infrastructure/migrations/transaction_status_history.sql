@@ -0,0 +1,28 @@
+-- Script to enforce transaction status transitions from the application
+
+-- Status transaksi: diterima -> diproses -> selesai -> diambil, plus dibatalkan
+ALTER TABLE transaksi DROP CONSTRAINT IF EXISTS transaksi_status_transaksi_check;
+ALTER TABLE transaksi
+ADD CONSTRAINT transaksi_status_transaksi_check
+CHECK (status_transaksi IN ('diterima', 'diproses', 'selesai', 'diambil', 'dibatalkan'));
+
+ALTER TABLE history_status_transaksi DROP CONSTRAINT IF EXISTS history_status_transaksi_status_lama_check;
+ALTER TABLE history_status_transaksi
+ADD CONSTRAINT history_status_transaksi_status_lama_check
+CHECK (status_lama IN ('diterima', 'diproses', 'selesai', 'diambil', 'dibatalkan'));
+
+ALTER TABLE history_status_transaksi DROP CONSTRAINT IF EXISTS history_status_transaksi_status_baru_check;
+ALTER TABLE history_status_transaksi
+ADD CONSTRAINT history_status_transaksi_status_baru_check
+CHECK (status_baru IN ('diterima', 'diproses', 'selesai', 'diambil', 'dibatalkan'));
+
+-- Record who changed the status
+ALTER TABLE history_status_transaksi
+ADD COLUMN IF NOT EXISTS diubah_oleh VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_history_status_transaksi ON history_status_transaksi(id_transaksi);
+
+-- History is now written by the application inside the same DB transaction,
+-- drop the trigger so transitions are not recorded twice
+DROP TRIGGER IF EXISTS trigger_transaksi_update ON transaksi;
+DROP FUNCTION IF EXISTS trigger_history_status_transaksi();
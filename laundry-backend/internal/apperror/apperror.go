@@ -0,0 +1,89 @@
+package apperror
+
+import "errors"
+
+// Kind classifies a domain error so the delivery layer can pick the HTTP status and response code
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnprocessable
+	KindForbidden
+	KindUnauthorized
+	KindLocked
+	KindTooManyRequests
+	KindUpstream
+)
+
+// Error is a domain error returned by usecases; Message is safe to show to the client,
+// Err is the underlying cause, if any
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func Wrap(kind Kind, message string, err error) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+func Validation(message string) *Error {
+	return New(KindValidation, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message)
+}
+
+// Upstream wraps a failure of an external service such as a payment gateway
+func Upstream(message string, err error) *Error {
+	return Wrap(KindUpstream, message, err)
+}
+
+// Resolve returns the domain error carried by err, translating Postgres errors on the way;
+// anything else is reported as an internal error
+func Resolve(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	if pgErr := fromPostgres(err); pgErr != nil {
+		return pgErr
+	}
+	return Wrap(KindInternal, "internal server error", err)
+}
+
+// KindOf reports the kind of err, KindInternal when it is not a domain error
+func KindOf(err error) Kind {
+	return Resolve(err).Kind
+}
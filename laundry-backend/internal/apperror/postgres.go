@@ -0,0 +1,41 @@
+package apperror
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// fromPostgres translates constraint violations and bad input rejected by Postgres into domain errors,
+// so a duplicate or a dangling reference is answered as a conflict or not found instead of a server error.
+// It returns nil when err does not carry a *pq.Error it knows about.
+func fromPostgres(err error) *Error {
+	var pgErr *pq.Error
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	switch pgErr.Code.Name() {
+	case "unique_violation", "exclusion_violation":
+		return Wrap(KindConflict, "data already exists", detail(pgErr))
+	case "foreign_key_violation":
+		// a parent row that is still referenced cannot be deleted; a child cannot point to a missing parent
+		if strings.Contains(pgErr.Detail, "is still referenced") {
+			return Wrap(KindConflict, "data is still in use", detail(pgErr))
+		}
+		return Wrap(KindNotFound, "referenced data not found", detail(pgErr))
+	case "not_null_violation", "check_violation", "invalid_text_representation",
+		"string_data_right_truncation", "numeric_value_out_of_range", "invalid_datetime_format":
+		return Wrap(KindValidation, "invalid data", detail(pgErr))
+	}
+	return nil
+}
+
+// detail prefers the Postgres detail line (e.g. which key already exists) over the bare message
+func detail(pgErr *pq.Error) error {
+	if pgErr.Detail != "" {
+		return errors.New(pgErr.Detail)
+	}
+	return errors.New(pgErr.Message)
+}
@@ -24,10 +24,22 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	response, err := h.authUsecase.Login(request)
+	if err := c.Validate(&request); err != nil {
+		return ValidationErrorResponse(c, err)
+	}
+
+	response, err := h.authUsecase.Login(request, loginClient(c))
 	if err != nil {
-		return ErrorResponse(c, http.StatusUnauthorized, "Authentication failed", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Login successful", response)
-}
\ No newline at end of file
+}
+
+// loginClient identifies the client of a login request for throttling and the login audit
+func loginClient(c echo.Context) entities.LoginClient {
+	return entities.LoginClient{
+		IPAddress: c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+}
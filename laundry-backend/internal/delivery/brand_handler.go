@@ -30,11 +30,17 @@ func (h *BrandHandler) CreateBrand(c echo.Context) error {
 		utils.LoggMsg(svcName, "Failed to bind request", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
 	request.Name = strings.ToUpper(request.Name)
 	request.PICName = strings.ToUpper(request.PICName)
+	request.Code = strings.ToUpper(request.Code)
 	if err := h.brandUsecase.CreateBrand(request); err != nil {
 		utils.LoggMsg(svcName, "Failed to create brand", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to create brand", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusCreated, "Brand created successfully")
@@ -53,7 +59,7 @@ func (h *BrandHandler) GetBrandByID(c echo.Context) error {
 	brand, err := h.brandUsecase.GetBrandByID(id)
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get brand", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get brand", err.Error())
+		return err
 	}
 
 	if brand == nil {
@@ -77,7 +83,7 @@ func (h *BrandHandler) GetAllBrands(c echo.Context) error {
 	response, err := h.brandUsecase.GetAllBrandsDataTables(request)
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get brands", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get brands", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Brands retrieved successfully", response)
@@ -98,11 +104,17 @@ func (h *BrandHandler) UpdateBrand(c echo.Context) error {
 		utils.LoggMsg(svcName, "Invalid request format", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
 	request.Name = strings.ToUpper(request.Name)
 	request.PICName = strings.ToUpper(request.PICName)
+	request.Code = strings.ToUpper(request.Code)
 	if err := h.brandUsecase.UpdateBrand(id, request); err != nil {
 		utils.LoggMsg(svcName, "Failed to update brand", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to update brand", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Brand updated successfully")
@@ -120,7 +132,7 @@ func (h *BrandHandler) DeleteBrand(c echo.Context) error {
 
 	if err := h.brandUsecase.DeleteBrand(id); err != nil {
 		utils.LoggMsg(svcName, "Failed to delete brand", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to delete brand", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Brand deleted successfully")
@@ -30,11 +30,16 @@ func (h *CabangHandler) CreateCabang(c echo.Context) error {
 		utils.LoggMsg(svcName, "Invalid request format", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
 	request.Name = strings.ToUpper(request.Name)
 	request.PICName = strings.ToUpper(request.PICName)
 	if err := h.cabangUsecase.CreateCabang(request); err != nil {
 		utils.LoggMsg(svcName, "Failed to create cabang", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to create cabang", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusCreated, "Cabang created successfully")
@@ -53,7 +58,7 @@ func (h *CabangHandler) GetCabangByID(c echo.Context) error {
 	cabang, err := h.cabangUsecase.GetCabangByID(id)
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get cabang", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get cabang", err.Error())
+		return err
 	}
 
 	if cabang == nil {
@@ -77,7 +82,7 @@ func (h *CabangHandler) GetCabangsByBrandID(c echo.Context) error {
 	cabangs, err := h.cabangUsecase.GetCabangsByBrandID(brandID)
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get cabangs", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get cabangs", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Cabangs retrieved successfully", cabangs)
@@ -97,7 +102,7 @@ func (h *CabangHandler) GetAllCabangs(c echo.Context) error {
 	response, err := h.cabangUsecase.GetAllCabangsDataTables(request)
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get cabangs", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get cabangs", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Cabangs retrieved successfully", response)
@@ -118,11 +123,16 @@ func (h *CabangHandler) UpdateCabang(c echo.Context) error {
 		utils.LoggMsg(svcName, "Invalid request format", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
 	request.Name = strings.ToUpper(request.Name)
 	request.PICName = strings.ToUpper(request.PICName)
 	if err := h.cabangUsecase.UpdateCabang(id, request); err != nil {
 		utils.LoggMsg(svcName, "Failed to update cabang", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to update cabang", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Cabang updated successfully")
@@ -140,7 +150,7 @@ func (h *CabangHandler) DeleteCabang(c echo.Context) error {
 
 	if err := h.cabangUsecase.DeleteCabang(id); err != nil {
 		utils.LoggMsg(svcName, "Failed to delete cabang", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to delete cabang", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Cabang deleted successfully")
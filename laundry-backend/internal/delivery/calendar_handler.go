@@ -0,0 +1,177 @@
+package delivery
+
+import (
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
+	"laundry-backend/internal/usecases"
+	"laundry-backend/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CalendarHandler struct {
+	calendarUsecase usecases.CalendarUsecase
+}
+
+func NewCalendarHandler(calendarUsecase usecases.CalendarUsecase) *CalendarHandler {
+	return &CalendarHandler{
+		calendarUsecase: calendarUsecase,
+	}
+}
+
+func (h *CalendarHandler) GetOpeningHours(c echo.Context) error {
+	var (
+		svcName = "GetOpeningHours"
+	)
+	outletID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid outlet ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid outlet ID", err.Error())
+	}
+
+	hours, err := h.calendarUsecase.GetOpeningHours(outletID, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to get opening hours", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Opening hours retrieved successfully", hours)
+}
+
+func (h *CalendarHandler) UpdateOpeningHours(c echo.Context) error {
+	var (
+		svcName = "UpdateOpeningHours"
+		request entities.OpeningHoursRequest
+	)
+	outletID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid outlet ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid outlet ID", err.Error())
+	}
+
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Failed to bind request", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	hours, err := h.calendarUsecase.UpdateOpeningHours(outletID, request, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to update opening hours", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Opening hours updated successfully", hours)
+}
+
+func (h *CalendarHandler) GetOutletHolidays(c echo.Context) error {
+	var (
+		svcName = "GetOutletHolidays"
+		from    time.Time
+		to      time.Time
+	)
+	outletID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid outlet ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid outlet ID", err.Error())
+	}
+	// dates left out are filled in from the outlet's today
+	if value := c.QueryParam("dari"); value != "" {
+		from, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.LoggMsg(svcName, "Invalid start date", err)
+			return ErrorResponse(c, http.StatusBadRequest, "Invalid start date", err.Error())
+		}
+	}
+	if value := c.QueryParam("sampai"); value != "" {
+		to, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.LoggMsg(svcName, "Invalid end date", err)
+			return ErrorResponse(c, http.StatusBadRequest, "Invalid end date", err.Error())
+		}
+	}
+
+	holidays, err := h.calendarUsecase.GetOutletHolidays(outletID, from, to, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to get holidays", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Holidays retrieved successfully", holidays)
+}
+
+func (h *CalendarHandler) GetAvailability(c echo.Context) error {
+	var (
+		svcName = "GetAvailability"
+		at      = time.Now()
+	)
+	outletID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid outlet ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid outlet ID", err.Error())
+	}
+	if value := c.QueryParam("waktu"); value != "" {
+		at, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.LoggMsg(svcName, "Invalid time", err)
+			return ErrorResponse(c, http.StatusBadRequest, "Invalid time", err.Error())
+		}
+	}
+
+	availability, err := h.calendarUsecase.GetAvailability(outletID, at, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to get availability", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Availability retrieved successfully", availability)
+}
+
+func (h *CalendarHandler) CreateHoliday(c echo.Context) error {
+	var (
+		svcName = "CreateHoliday"
+		request entities.CreateHolidayRequest
+	)
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Failed to bind request", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	holiday, err := h.calendarUsecase.CreateHoliday(request, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to create holiday", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusCreated, "Holiday created successfully", holiday)
+}
+
+func (h *CalendarHandler) DeleteHoliday(c echo.Context) error {
+	var (
+		svcName = "DeleteHoliday"
+	)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid holiday ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid holiday ID", err.Error())
+	}
+
+	if err := h.calendarUsecase.DeleteHoliday(id, middleware.AccessScope(c)); err != nil {
+		utils.LoggMsg(svcName, "Failed to delete holiday", err)
+		return err
+	}
+
+	return MessageResponse(c, http.StatusOK, "Holiday deleted successfully")
+}
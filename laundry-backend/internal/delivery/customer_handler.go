@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
 	"laundry-backend/internal/usecases"
 	"laundry-backend/internal/utils"
 	"net/http"
@@ -31,16 +32,21 @@ func (h *CustomerHandler) CreateCustomer(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
 	// Validate required fields
 	if request.Name == "" {
 		utils.LoggMsg(svcName, "Name is required", nil)
 		return ErrorResponse(c, http.StatusBadRequest, "Name is required", "")
 	}
 	request.Name = strings.ToUpper(request.Name)
-	err := h.customerUsecase.CreateCustomer(request)
+	err := h.customerUsecase.CreateCustomer(request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to create customer", err)
-		return ErrorResponse(c, http.StatusBadRequest, "Failed to create customer", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusCreated, "Customer created successfully")
@@ -56,10 +62,10 @@ func (h *CustomerHandler) GetCustomerByID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid customer ID", err.Error())
 	}
 
-	customer, err := h.customerUsecase.GetCustomerByID(id)
+	customer, err := h.customerUsecase.GetCustomerByID(id, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get customer", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get customer", err.Error())
+		return err
 	}
 
 	if customer == nil {
@@ -80,10 +86,10 @@ func (h *CustomerHandler) GetCustomersByOutletID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid outlet ID", err.Error())
 	}
 
-	customers, err := h.customerUsecase.GetCustomersByOutletID(outletID)
+	customers, err := h.customerUsecase.GetCustomersByOutletID(outletID, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get customersID", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get customers", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Customers retrieved successfully", customers)
@@ -99,10 +105,10 @@ func (h *CustomerHandler) GetAllCustomers(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	response, err := h.customerUsecase.GetAllCustomersDataTables(request)
+	response, err := h.customerUsecase.GetAllCustomersDataTables(request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get customers", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get customers", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Customers retrieved successfully", response)
@@ -118,10 +124,10 @@ func (h *CustomerHandler) GetAllCustomersDataTables(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	response, err := h.customerUsecase.GetAllCustomersDataTables(request)
+	response, err := h.customerUsecase.GetAllCustomersDataTables(request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get customers", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get customers", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Customers retrieved successfully", response)
@@ -142,11 +148,16 @@ func (h *CustomerHandler) UpdateCustomer(c echo.Context) error {
 		utils.LoggMsg(svcName, "Invalid request format", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
 	request.Name = strings.ToUpper(request.Name)
-	err = h.customerUsecase.UpdateCustomer(id, request)
+	err = h.customerUsecase.UpdateCustomer(id, request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to update customer", err)
-		return ErrorResponse(c, http.StatusBadRequest, "Failed to update customer", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Customer updated successfully")
@@ -162,10 +173,10 @@ func (h *CustomerHandler) DeleteCustomer(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid customer ID", err.Error())
 	}
 
-	err = h.customerUsecase.DeleteCustomer(id)
+	err = h.customerUsecase.DeleteCustomer(id, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to delete customer", err)
-		return ErrorResponse(c, http.StatusBadRequest, "Failed to delete customer", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Customer deleted successfully")
@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
 	"laundry-backend/internal/usecases"
 	"laundry-backend/internal/utils"
 	"net/http"
@@ -30,16 +31,21 @@ func (h *EmployeeHandler) CreateEmployee(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
 	// Validate required fields
 	if request.NIK == "" || request.Name == "" {
 		utils.LoggMsg(svcName, "NIK and name are required", nil)
 		return ErrorResponse(c, http.StatusBadRequest, "NIK and name are required", "")
 	}
 
-	err := h.employeeUsecase.CreateEmployee(request)
+	err := h.employeeUsecase.CreateEmployee(request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to create employee", err)
-		return ErrorResponse(c, http.StatusBadRequest, "Failed to create employee", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusCreated, "Employee created successfully")
@@ -55,10 +61,10 @@ func (h *EmployeeHandler) GetEmployeeByID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid employee ID", err.Error())
 	}
 
-	employee, err := h.employeeUsecase.GetEmployeeByID(id)
+	employee, err := h.employeeUsecase.GetEmployeeByID(id, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get employee", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get employee", err.Error())
+		return err
 	}
 
 	if employee == nil {
@@ -79,10 +85,10 @@ func (h *EmployeeHandler) GetAllEmployees(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	response, err := h.employeeUsecase.GetAllEmployeesDataTables(request)
+	response, err := h.employeeUsecase.GetAllEmployeesDataTables(request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get employees", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get employees", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Employees retrieved successfully", response)
@@ -98,10 +104,10 @@ func (h *EmployeeHandler) GetAllEmployeesDataTables(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	response, err := h.employeeUsecase.GetAllEmployeesDataTables(request)
+	response, err := h.employeeUsecase.GetAllEmployeesDataTables(request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get employees", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get employees", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Employees retrieved successfully", response)
@@ -123,10 +129,15 @@ func (h *EmployeeHandler) UpdateEmployee(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	err = h.employeeUsecase.UpdateEmployee(id, request)
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	err = h.employeeUsecase.UpdateEmployee(id, request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to update employee", err)
-		return ErrorResponse(c, http.StatusBadRequest, "Failed to update employee", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Employee updated successfully")
@@ -142,10 +153,10 @@ func (h *EmployeeHandler) DeleteEmployee(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid employee ID", err.Error())
 	}
 
-	err = h.employeeUsecase.DeleteEmployee(id)
+	err = h.employeeUsecase.DeleteEmployee(id, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to delete employee", err)
-		return ErrorResponse(c, http.StatusBadRequest, "Failed to delete employee", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Employee deleted successfully")
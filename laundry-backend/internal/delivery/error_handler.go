@@ -0,0 +1,80 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"laundry-backend/internal/apperror"
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// kindStatus is the HTTP status answered for each domain error kind
+var kindStatus = map[apperror.Kind]int{
+	apperror.KindInternal:        http.StatusInternalServerError,
+	apperror.KindNotFound:        http.StatusNotFound,
+	apperror.KindConflict:        http.StatusConflict,
+	apperror.KindValidation:      http.StatusBadRequest,
+	apperror.KindUnprocessable:   http.StatusUnprocessableEntity,
+	apperror.KindForbidden:       http.StatusForbidden,
+	apperror.KindUnauthorized:    http.StatusUnauthorized,
+	apperror.KindLocked:          http.StatusLocked,
+	apperror.KindTooManyRequests: http.StatusTooManyRequests,
+	apperror.KindUpstream:        http.StatusBadGateway,
+}
+
+// HTTPErrorHandler is echo's HTTPErrorHandler: every error returned by a handler or middleware
+// is written as an APIResponse with the HTTP status and response code of its kind
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	var (
+		validationErr *ValidationError
+		httpErr       *echo.HTTPError
+		response      entities.APIResponse
+	)
+	switch {
+	case errors.As(err, &validationErr):
+		ValidationErrorResponse(c, validationErr)
+		return
+	case errors.As(err, &httpErr):
+		response = entities.APIResponse{
+			Status:  httpErr.Code,
+			Message: fmt.Sprint(httpErr.Message),
+		}
+		if httpErr.Internal != nil {
+			response.Error = httpErr.Internal.Error()
+		}
+	default:
+		appErr := apperror.Resolve(err)
+		response = entities.APIResponse{
+			Status:  kindStatus[appErr.Kind],
+			Message: appErr.Message,
+			Error:   err.Error(),
+		}
+		// a translated database error explains itself better than the raw driver message
+		if !errors.Is(err, appErr) {
+			response.Error = appErr.Error()
+		}
+	}
+	response.Code = responseCode(response.Status)
+
+	// the cause of a server error, such as the SQL that failed, is only logged, never sent to the client
+	if response.Status >= http.StatusInternalServerError {
+		utils.LoggMsg("HTTPErrorHandler", c.Request().Method+" "+c.Path(), err)
+		response.Error = response.Message
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(response.Status)
+	} else {
+		err = c.JSON(response.Status, response)
+	}
+	if err != nil {
+		utils.LoggMsg("HTTPErrorHandler", "Failed to write error response", err)
+	}
+}
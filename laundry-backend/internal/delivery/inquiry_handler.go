@@ -3,6 +3,7 @@ package delivery
 import (
 	"fmt"
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
 	"laundry-backend/internal/usecases"
 	"laundry-backend/internal/utils"
 	"net/http"
@@ -31,21 +32,69 @@ func (h *InquiryHandler) ProcessInquiry(c echo.Context) error {
 		utils.LoggMsg(svcName, "Failed to bind request", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
 	// Ambil token dari context
 	user := c.Get("user").(*jwt.Token)
 	claims := user.Claims.(jwt.MapClaims)
 
-	request.UserID = int(claims["user_id"].(float64)) // JSON number → float64 → int
+	// an administrator token carries a users id, not the user_access the order is taken by
+	userID, ok := middleware.StaffUserID(c)
+	if !ok {
+		utils.LoggMsg(svcName, "Inquiry taken without a staff account", nil)
+		return ErrorResponse(c, http.StatusForbidden, "Forbidden", "inquiries can only be taken by staff accounts")
+	}
+	request.UserID = userID
+	if len(request.Items) == 0 {
+		utils.LoggMsg(svcName, "Items cannot be empty", nil)
+		return ErrorResponse(c, http.StatusBadRequest, "Items cannot be empty", "")
+	}
 	if request.PaymentMethodID == 0 {
 		utils.LoggMsg(svcName, "Invalid Payment Method", nil)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid Payment Method", "")
 	}
-	response, err := h.inquiryUsecase.ProcessInquiry(request, claims)
+	response, err := h.inquiryUsecase.ProcessInquiry(request, claims, middleware.AccessScope(c))
 	if err != nil {
 		fmt.Printf("Failed to process inquiry: %v\n", err)
-		return ErrorResponse(c, http.StatusBadRequest, "Failed to process inquiry", err.Error())
+		return err
 	}
 
 	fmt.Printf("=== PROCESS INQUIRY HANDLER END ===\n")
 	return SuccessResponse(c, http.StatusOK, "Inquiry processed successfully", response)
 }
+
+// QuoteInquiry prices the items of an inquiry without saving a transaksi
+func (h *InquiryHandler) QuoteInquiry(c echo.Context) error {
+	var (
+		request entities.QuoteRequest
+		svcName = "QuoteInquiry"
+	)
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Failed to bind request", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	userID, ok := middleware.StaffUserID(c)
+	if !ok {
+		utils.LoggMsg(svcName, "Inquiry quoted without a staff account", nil)
+		return ErrorResponse(c, http.StatusForbidden, "Forbidden", "inquiries can only be taken by staff accounts")
+	}
+	request.UserID = userID
+
+	quote, err := h.inquiryUsecase.QuoteInquiry(request)
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to quote inquiry", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Inquiry quoted successfully", quote)
+}
@@ -0,0 +1,138 @@
+package delivery
+
+import (
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
+	"laundry-backend/internal/usecases"
+	"laundry-backend/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ModifierHandler struct {
+	modifierUsecase usecases.ModifierUsecase
+}
+
+func NewModifierHandler(modifierUsecase usecases.ModifierUsecase) *ModifierHandler {
+	return &ModifierHandler{
+		modifierUsecase: modifierUsecase,
+	}
+}
+
+func (h *ModifierHandler) CreateModifier(c echo.Context) error {
+	var (
+		svcName = "CreateModifier"
+		request entities.CreateModifierRequest
+	)
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Failed to bind request", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	modifier, err := h.modifierUsecase.CreateModifier(request, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to create modifier", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusCreated, "Modifier created successfully", modifier)
+}
+
+func (h *ModifierHandler) GetModifierByID(c echo.Context) error {
+	var (
+		svcName = "GetModifierByID"
+	)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid modifier ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid modifier ID", err.Error())
+	}
+
+	modifier, err := h.modifierUsecase.GetModifierByID(id, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to get modifier", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Modifier retrieved successfully", modifier)
+}
+
+// GetModifiers lists the modifiers of a brand (id_brand), or the ones that can be selected for a service (id_layanan)
+func (h *ModifierHandler) GetModifiers(c echo.Context) error {
+	var (
+		svcName = "GetModifiers"
+		filter  entities.ModifierFilter
+		err     error
+	)
+	if filter.BrandID, err = strconv.Atoi(queryParamOrDefault(c, "id_brand", "0")); err != nil {
+		utils.LoggMsg(svcName, "Invalid brand ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid brand ID", err.Error())
+	}
+	if filter.ServiceID, err = strconv.Atoi(queryParamOrDefault(c, "id_layanan", "0")); err != nil {
+		utils.LoggMsg(svcName, "Invalid service ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid service ID", err.Error())
+	}
+
+	modifiers, err := h.modifierUsecase.GetModifiers(filter, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to get modifiers", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Modifiers retrieved successfully", modifiers)
+}
+
+func (h *ModifierHandler) UpdateModifier(c echo.Context) error {
+	var (
+		svcName = "UpdateModifier"
+		request entities.UpdateModifierRequest
+	)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid modifier ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid modifier ID", err.Error())
+	}
+
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Failed to bind request", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	modifier, err := h.modifierUsecase.UpdateModifier(id, request, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to update modifier", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Modifier updated successfully", modifier)
+}
+
+func (h *ModifierHandler) DeleteModifier(c echo.Context) error {
+	var (
+		svcName = "DeleteModifier"
+	)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid modifier ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid modifier ID", err.Error())
+	}
+
+	if err := h.modifierUsecase.DeleteModifier(id, middleware.AccessScope(c)); err != nil {
+		utils.LoggMsg(svcName, "Failed to delete modifier", err)
+		return err
+	}
+
+	return MessageResponse(c, http.StatusOK, "Modifier deleted successfully")
+}
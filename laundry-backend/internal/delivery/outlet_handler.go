@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
 	"laundry-backend/internal/usecases"
 	"laundry-backend/internal/utils"
 	"net/http"
@@ -31,11 +32,17 @@ func (h *OutletHandler) CreateOutlet(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
 	request.Name = strings.ToUpper(request.Name)
 	request.PICName = strings.ToUpper(request.PICName)
-	if err := h.outletUsecase.CreateOutlet(request); err != nil {
+	request.Code = strings.ToUpper(request.Code)
+	if err := h.outletUsecase.CreateOutlet(request, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to create outlet", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to create outlet", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusCreated, "Outlet created successfully")
@@ -51,10 +58,10 @@ func (h *OutletHandler) GetOutletByID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid outlet ID", err.Error())
 	}
 
-	outlet, err := h.outletUsecase.GetOutletByID(id)
+	outlet, err := h.outletUsecase.GetOutletByID(id, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get outlet", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get outlet", err.Error())
+		return err
 	}
 
 	if outlet == nil {
@@ -75,10 +82,10 @@ func (h *OutletHandler) GetOutletsByCabangID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid cabang ID", err.Error())
 	}
 
-	outlets, err := h.outletUsecase.GetOutletsByCabangID(cabangID)
+	outlets, err := h.outletUsecase.GetOutletsByCabangID(cabangID, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get outlets", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get outlets", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Outlets retrieved successfully", outlets)
@@ -100,10 +107,10 @@ func (h *OutletHandler) GetAllOutlets(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	response, err := h.outletUsecase.GetAllOutletsDataTables(request)
+	response, err := h.outletUsecase.GetAllOutletsDataTables(request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get outlets", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get outlets", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Outlets retrieved successfully", response)
@@ -124,11 +131,17 @@ func (h *OutletHandler) UpdateOutlet(c echo.Context) error {
 		utils.LoggMsg(svcName, "Invalid request format", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
 	request.Name = strings.ToUpper(request.Name)
 	request.PICName = strings.ToUpper(request.PICName)
-	if err := h.outletUsecase.UpdateOutlet(id, request); err != nil {
+	request.Code = strings.ToUpper(request.Code)
+	if err := h.outletUsecase.UpdateOutlet(id, request, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to update outlet", nil)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to update outlet", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Outlet updated successfully")
@@ -144,9 +157,9 @@ func (h *OutletHandler) DeleteOutlet(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid outlet ID", err.Error())
 	}
 
-	if err := h.outletUsecase.DeleteOutlet(id); err != nil {
+	if err := h.outletUsecase.DeleteOutlet(id, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to delete outlet", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to delete outlet", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Outlet deleted successfully")
@@ -0,0 +1,147 @@
+package delivery
+
+import (
+	"io"
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
+	"laundry-backend/internal/usecases"
+	"laundry-backend/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PaymentHandler struct {
+	paymentUsecase usecases.PaymentUsecase
+}
+
+func NewPaymentHandler(paymentUsecase usecases.PaymentUsecase) *PaymentHandler {
+	return &PaymentHandler{
+		paymentUsecase: paymentUsecase,
+	}
+}
+
+func (h *PaymentHandler) CreatePayment(c echo.Context) error {
+	var (
+		svcName = "CreatePayment"
+		request entities.CreatePaymentRequest
+	)
+	transactionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid transaction ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid transaction ID", err.Error())
+	}
+
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Failed to bind request", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	payment, err := h.paymentUsecase.CreatePayment(transactionID, request, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to create payment", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusCreated, "Payment created successfully", payment)
+}
+
+func (h *PaymentHandler) GetPaymentsByTransactionID(c echo.Context) error {
+	var (
+		svcName = "GetPaymentsByTransactionID"
+	)
+	transactionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid transaction ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid transaction ID", err.Error())
+	}
+
+	response, err := h.paymentUsecase.GetPaymentsByTransactionID(transactionID, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to get payments", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Payments retrieved successfully", response)
+}
+
+func (h *PaymentHandler) UpdatePaymentStatus(c echo.Context) error {
+	var (
+		svcName = "UpdatePaymentStatus"
+		request entities.UpdatePaymentStatusRequest
+	)
+	paymentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid payment ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID", err.Error())
+	}
+
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Failed to bind request", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	if err := h.paymentUsecase.UpdatePaymentStatus(paymentID, request, middleware.AccessScope(c)); err != nil {
+		utils.LoggMsg(svcName, "Failed to update payment status", err)
+		return err
+	}
+
+	return MessageResponse(c, http.StatusOK, "Payment status updated successfully")
+}
+
+func (h *PaymentHandler) SyncPaymentStatus(c echo.Context) error {
+	var (
+		svcName = "SyncPaymentStatus"
+	)
+	paymentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid payment ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID", err.Error())
+	}
+
+	payment, err := h.paymentUsecase.SyncPaymentStatus(paymentID, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to sync payment status", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Payment status synchronized successfully", payment)
+}
+
+// ProcessPaymentCallback is called by the payment gateway, not by staff: it is authenticated by the
+// X-Merchant-Key header and the X-Signature HMAC-SHA256 of the raw body instead of a JWT
+func (h *PaymentHandler) ProcessPaymentCallback(c echo.Context) error {
+	var (
+		svcName = "ProcessPaymentCallback"
+	)
+
+	payload, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to read request body", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	callbackLog, err := h.paymentUsecase.ProcessPaymentCallback(payload,
+		c.Request().Header.Get("X-Signature"), c.Request().Header.Get("X-Merchant-Key"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to process payment callback", err)
+		return err
+	}
+
+	if callbackLog.Result == entities.CallbackResultDuplicate {
+		return MessageResponse(c, http.StatusOK, "Payment callback already processed")
+	}
+
+	return MessageResponse(c, http.StatusOK, "Payment callback processed successfully")
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 )
 
@@ -30,10 +31,15 @@ func (h *PaymentMethodHandler) CreatePaymentMethod(c echo.Context) error {
 		utils.LoggMsg(svcName, "Failed to bind request", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
 	request.NamaMetode = strings.ToUpper(request.NamaMetode)
 	if err := h.paymentMethodUsecase.CreatePaymentMethod(request); err != nil {
 		utils.LoggMsg(svcName, "Failed to create payment method", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to create payment method", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusCreated, "Payment method created successfully")
@@ -52,7 +58,7 @@ func (h *PaymentMethodHandler) GetPaymentMethodByID(c echo.Context) error {
 	paymentMethod, err := h.paymentMethodUsecase.GetPaymentMethodByID(id)
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get payment method", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get payment method", err.Error())
+		return err
 	}
 
 	if paymentMethod == nil {
@@ -76,7 +82,7 @@ func (h *PaymentMethodHandler) GetAllPaymentMethods(c echo.Context) error {
 	response, err := h.paymentMethodUsecase.GetAllPaymentMethodsDataTables(request)
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get payment methods", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get payment methods", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Payment methods retrieved successfully", response)
@@ -97,15 +103,68 @@ func (h *PaymentMethodHandler) UpdatePaymentMethod(c echo.Context) error {
 		utils.LoggMsg(svcName, "Invalid request format", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
 	request.NamaMetode = strings.ToUpper(request.NamaMetode)
 	if err := h.paymentMethodUsecase.UpdatePaymentMethod(id, request); err != nil {
 		utils.LoggMsg(svcName, "Failed to update payment method", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to update payment method", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Payment method updated successfully")
 }
 
+func (h *PaymentMethodHandler) UpdatePaymentMethodSecrets(c echo.Context) error {
+	var (
+		svcName = "UpdatePaymentMethodSecrets"
+		request entities.UpdatePaymentMethodSecretsRequest
+	)
+	claims := c.Get("user").(*jwt.Token).Claims.(jwt.MapClaims)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid payment method ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid payment method ID", err.Error())
+	}
+
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request format", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+	request.UpdatedBy, _ = claims["username"].(string)
+
+	if err := h.paymentMethodUsecase.UpdatePaymentMethodSecrets(id, request); err != nil {
+		utils.LoggMsg(svcName, "Failed to update payment method secrets", err)
+		return err
+	}
+
+	return MessageResponse(c, http.StatusOK, "Payment method secrets updated successfully")
+}
+
+func (h *PaymentMethodHandler) RotatePaymentMethodSecrets(c echo.Context) error {
+	var (
+		svcName = "RotatePaymentMethodSecrets"
+	)
+	claims := c.Get("user").(*jwt.Token).Claims.(jwt.MapClaims)
+	updatedBy, _ := claims["username"].(string)
+
+	response, err := h.paymentMethodUsecase.RotatePaymentMethodSecrets(updatedBy)
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to rotate payment method secrets", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Payment method secrets rotated successfully", response)
+}
+
 func (h *PaymentMethodHandler) DeletePaymentMethod(c echo.Context) error {
 	var (
 		svcName = "DeletePaymentMethod"
@@ -118,7 +177,7 @@ func (h *PaymentMethodHandler) DeletePaymentMethod(c echo.Context) error {
 
 	if err := h.paymentMethodUsecase.DeletePaymentMethod(id); err != nil {
 		utils.LoggMsg(svcName, "Failed to delete payment method", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to delete payment method", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Payment method deleted successfully")
@@ -0,0 +1,89 @@
+package delivery
+
+import (
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
+	"laundry-backend/internal/usecases"
+	"laundry-backend/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PricingRuleHandler struct {
+	pricingRuleUsecase usecases.PricingRuleUsecase
+}
+
+func NewPricingRuleHandler(pricingRuleUsecase usecases.PricingRuleUsecase) *PricingRuleHandler {
+	return &PricingRuleHandler{
+		pricingRuleUsecase: pricingRuleUsecase,
+	}
+}
+
+func (h *PricingRuleHandler) GetPricingRules(c echo.Context) error {
+	var (
+		svcName = "GetPricingRules"
+	)
+	serviceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid service ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid service ID", err.Error())
+	}
+
+	rules, err := h.pricingRuleUsecase.GetPricingRules(serviceID, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to get pricing rules", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Pricing rules retrieved successfully", rules)
+}
+
+func (h *PricingRuleHandler) SavePricingRule(c echo.Context) error {
+	var (
+		svcName = "SavePricingRule"
+		request entities.SavePricingRuleRequest
+	)
+	serviceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid service ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid service ID", err.Error())
+	}
+
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Failed to bind request", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	rule, err := h.pricingRuleUsecase.SavePricingRule(serviceID, request, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to save pricing rule", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Pricing rule saved successfully", rule)
+}
+
+func (h *PricingRuleHandler) DeletePricingRule(c echo.Context) error {
+	var (
+		svcName = "DeletePricingRule"
+	)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid pricing rule ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid pricing rule ID", err.Error())
+	}
+
+	if err := h.pricingRuleUsecase.DeletePricingRule(id, middleware.AccessScope(c)); err != nil {
+		utils.LoggMsg(svcName, "Failed to delete pricing rule", err)
+		return err
+	}
+
+	return MessageResponse(c, http.StatusOK, "Pricing rule deleted successfully")
+}
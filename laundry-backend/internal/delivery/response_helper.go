@@ -1,7 +1,9 @@
 package delivery
 
 import (
+	"laundry-backend/constant"
 	"laundry-backend/internal/entities"
+	"net/http"
 
 	"github.com/labstack/echo/v4"
 )
@@ -20,6 +22,7 @@ func SuccessResponse(c echo.Context, statusCode int, message string, data interf
 func ErrorResponse(c echo.Context, statusCode int, message string, err string) error {
 	response := entities.APIResponse{
 		Status:  statusCode,
+		Code:    responseCode(statusCode),
 		Message: message,
 		Error:   err,
 	}
@@ -34,3 +37,23 @@ func MessageResponse(c echo.Context, statusCode int, message string) error {
 	}
 	return c.JSON(statusCode, response)
 }
+
+// responseCode maps an HTTP error status to the response code of constant/code.go
+func responseCode(statusCode int) string {
+	switch statusCode {
+	case http.StatusNotFound:
+		return constant.NotFoundCode
+	case http.StatusConflict:
+		return constant.ConflictCode
+	case http.StatusUnprocessableEntity:
+		return constant.UnprocessableCode
+	case http.StatusForbidden:
+		return constant.ForbiddenCode
+	case http.StatusUnauthorized, http.StatusLocked, http.StatusTooManyRequests:
+		return constant.UnauthorizedCode
+	}
+	if statusCode >= http.StatusInternalServerError {
+		return constant.InternalErrorCode
+	}
+	return constant.BadRequestCode
+}
@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
 	"laundry-backend/internal/usecases"
 	"laundry-backend/internal/utils"
 	"net/http"
@@ -32,11 +33,16 @@ func (h *ServiceCategoryHandler) CreateServiceCategory(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
 	request.Name = strings.ToUpper(request.Name)
 
-	if err := h.serviceCategoryUsecase.CreateServiceCategory(request); err != nil {
+	if err := h.serviceCategoryUsecase.CreateServiceCategory(request, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to create service category", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to create service category", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusCreated, "Service category created successfully")
@@ -56,7 +62,7 @@ func (h *ServiceCategoryHandler) GetServiceCategoryByID(c echo.Context) error {
 	category, err := h.serviceCategoryUsecase.GetServiceCategoryByID(id)
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get service category", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get service category", err.Error())
+		return err
 	}
 
 	if category == nil {
@@ -81,7 +87,7 @@ func (h *ServiceCategoryHandler) GetAllServiceCategories(c echo.Context) error {
 	response, err := h.serviceCategoryUsecase.GetAllServiceCategoriesDataTables(request)
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get service categories", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get service categories", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Service categories retrieved successfully", response)
@@ -104,11 +110,16 @@ func (h *ServiceCategoryHandler) UpdateServiceCategory(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
 	request.Name = strings.ToUpper(request.Name)
 
-	if err := h.serviceCategoryUsecase.UpdateServiceCategory(id, request); err != nil {
+	if err := h.serviceCategoryUsecase.UpdateServiceCategory(id, request, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to update service category", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to update service category", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Service category updated successfully")
@@ -125,9 +136,9 @@ func (h *ServiceCategoryHandler) DeleteServiceCategory(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid service category ID", err.Error())
 	}
 
-	if err := h.serviceCategoryUsecase.DeleteServiceCategory(id); err != nil {
+	if err := h.serviceCategoryUsecase.DeleteServiceCategory(id, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to delete service category", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to delete service category", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Service category deleted successfully")
@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
 	"laundry-backend/internal/usecases"
 	"laundry-backend/internal/utils"
 	"net/http"
@@ -30,11 +31,16 @@ func (h *ServiceHandler) CreateService(c echo.Context) error {
 		utils.LoggMsg(svcName, "Failed to bind request", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
 	request.Name = strings.ToUpper(request.Name)
 
-	if err := h.serviceUsecase.CreateService(request); err != nil {
+	if err := h.serviceUsecase.CreateService(request, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to create service", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to create service", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusCreated, "Service created successfully")
@@ -50,10 +56,10 @@ func (h *ServiceHandler) GetServiceByID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid service ID", err.Error())
 	}
 
-	service, err := h.serviceUsecase.GetServiceByID(id)
+	service, err := h.serviceUsecase.GetServiceByID(id, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get service", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get service", err.Error())
+		return err
 	}
 
 	if service == nil {
@@ -74,10 +80,10 @@ func (h *ServiceHandler) GetAllServices(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	response, err := h.serviceUsecase.GetAllServicesDataTables(request)
+	response, err := h.serviceUsecase.GetAllServicesDataTables(request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get services", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get services", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Services retrieved successfully", response)
@@ -98,11 +104,16 @@ func (h *ServiceHandler) UpdateService(c echo.Context) error {
 		utils.LoggMsg(svcName, "Invalid request format", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
 	request.Name = strings.ToUpper(request.Name)
 
-	if err := h.serviceUsecase.UpdateService(id, request); err != nil {
+	if err := h.serviceUsecase.UpdateService(id, request, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to update service", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to update service", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Service updated successfully")
@@ -118,9 +129,9 @@ func (h *ServiceHandler) DeleteService(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid service ID", err.Error())
 	}
 
-	if err := h.serviceUsecase.DeleteService(id); err != nil {
+	if err := h.serviceUsecase.DeleteService(id, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to delete service", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to delete service", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "Service deleted successfully")
@@ -136,10 +147,10 @@ func (h *ServiceHandler) GetServicesByCategoryID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err.Error())
 	}
 
-	services, err := h.serviceUsecase.GetServicesByCategoryID(categoryID)
+	services, err := h.serviceUsecase.GetServicesByCategoryID(categoryID, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get services by category", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get services by category", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Services retrieved successfully", services)
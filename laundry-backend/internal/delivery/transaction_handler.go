@@ -2,11 +2,14 @@ package delivery
 
 import (
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
 	"laundry-backend/internal/usecases"
 	"laundry-backend/internal/utils"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 )
 
@@ -30,10 +33,10 @@ func (h *TransactionHandler) GetAllTransactions(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	response, err := h.transactionUsecase.GetAllTransactionsDataTables(request)
+	response, err := h.transactionUsecase.GetAllTransactionsDataTables(request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get transactions", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get transactions", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", response)
@@ -49,10 +52,10 @@ func (h *TransactionHandler) GetTransactionByID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid transaction ID", err.Error())
 	}
 
-	transaction, err := h.transactionUsecase.GetTransactionByID(id)
+	transaction, err := h.transactionUsecase.GetTransactionByID(id, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get transaction", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get transaction", err.Error())
+		return err
 	}
 
 	if transaction == nil {
@@ -73,10 +76,10 @@ func (h *TransactionHandler) GetTransactionsByOutletID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid outlet ID", err.Error())
 	}
 
-	transactions, err := h.transactionUsecase.GetTransactionsByOutletID(outletID)
+	transactions, err := h.transactionUsecase.GetTransactionsByOutletID(outletID, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get transactions by outlet", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get transactions by outlet", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", transactions)
@@ -92,15 +95,34 @@ func (h *TransactionHandler) GetTransactionDetails(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid transaction ID", err.Error())
 	}
 
-	details, err := h.transactionUsecase.GetTransactionDetails(transactionID)
+	details, err := h.transactionUsecase.GetTransactionDetails(transactionID, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get transaction details", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get transaction details", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "Transaction details retrieved successfully", details)
 }
 
+func (h *TransactionHandler) GetTransactionHistory(c echo.Context) error {
+	var (
+		svcName = "GetTransactionHistory"
+	)
+	transactionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid transaction ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid transaction ID", err.Error())
+	}
+
+	timeline, err := h.transactionUsecase.GetTransactionHistory(transactionID, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to get transaction history", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Transaction history retrieved successfully", timeline)
+}
+
 func (h *TransactionHandler) UpdateTransactionStatus(c echo.Context) error {
 	var (
 		svcName = "UpdateTransactionStatus"
@@ -118,20 +140,29 @@ func (h *TransactionHandler) UpdateTransactionStatus(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	if err := h.transactionUsecase.UpdateTransactionStatus(transactionID, request); err != nil {
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	// Ambil token dari context
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+
+	history, err := h.transactionUsecase.UpdateTransactionStatus(transactionID, request, claims, middleware.AccessScope(c))
+	if err != nil {
 		utils.LoggMsg(svcName, "Failed to update transaction status", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to update transaction status", err.Error())
+		return err
 	}
 
-	return MessageResponse(c, http.StatusOK, "Transaction status updated successfully")
+	return SuccessResponse(c, http.StatusOK, "Transaction status updated successfully", history)
 }
 
-func (h *TransactionHandler) UpdatePaymentStatus(c echo.Context) error {
+func (h *TransactionHandler) CancelTransaction(c echo.Context) error {
 	var (
-		svcName = "UpdatePaymentStatus"
-		request entities.UpdatePaymentStatusRequest
+		svcName = "CancelTransaction"
+		request entities.CancelTransactionRequest
 	)
-	
 	transactionID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		utils.LoggMsg(svcName, "Invalid transaction ID", err)
@@ -143,29 +174,57 @@ func (h *TransactionHandler) UpdatePaymentStatus(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	if err := h.transactionUsecase.UpdatePaymentStatus(transactionID, request); err != nil {
-		utils.LoggMsg(svcName, "Failed to update payment status", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to update payment status", err.Error())
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
 	}
 
-	return MessageResponse(c, http.StatusOK, "Payment status updated successfully")
+	claims := c.Get("user").(*jwt.Token).Claims.(jwt.MapClaims)
+	response, err := h.transactionUsecase.CancelTransaction(transactionID, request, claims, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to cancel transaction", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Transaction cancelled successfully", response)
 }
 
-func (h *TransactionHandler) ProcessPaymentCallback(c echo.Context) error {
+// GetRevenueReport sums orders entered between ?dari= and ?sampai= (YYYY-MM-DD, default today),
+// optionally for one ?id_outlet=; cancelled orders are excluded from the sums
+func (h *TransactionHandler) GetRevenueReport(c echo.Context) error {
 	var (
-		svcName = "ProcessPaymentCallback"
-		request entities.PaymentCallbackRequest
+		svcName = "GetRevenueReport"
+		today   = time.Now().Format("2006-01-02")
 	)
-
-	if err := c.Bind(&request); err != nil {
-		utils.LoggMsg(svcName, "Failed to bind request", err)
-		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	from, err := time.ParseInLocation("2006-01-02", queryParamOrDefault(c, "dari", today), time.Local)
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid start date", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid start date", err.Error())
+	}
+	to, err := time.ParseInLocation("2006-01-02", queryParamOrDefault(c, "sampai", today), time.Local)
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid end date", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid end date", err.Error())
+	}
+	outletID, err := strconv.Atoi(queryParamOrDefault(c, "id_outlet", "0"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid outlet ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid outlet ID", err.Error())
 	}
 
-	if err := h.transactionUsecase.ProcessPaymentCallback(request); err != nil {
-		utils.LoggMsg(svcName, "Failed to process payment callback", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to process payment callback", err.Error())
+	report, err := h.transactionUsecase.GetRevenueReport(outletID, from, to, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to get revenue report", err)
+		return err
 	}
 
-	return MessageResponse(c, http.StatusOK, "Payment callback processed successfully")
-}
\ No newline at end of file
+	return SuccessResponse(c, http.StatusOK, "Revenue report retrieved successfully", report)
+}
+
+// queryParamOrDefault returns the query parameter name, or def when it is absent
+func queryParamOrDefault(c echo.Context, name, def string) string {
+	if value := c.QueryParam(name); value != "" {
+		return value
+	}
+	return def
+}
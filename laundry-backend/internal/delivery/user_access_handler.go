@@ -3,6 +3,7 @@ package delivery
 import (
 	"fmt"
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/middleware"
 	"laundry-backend/internal/usecases"
 	"laundry-backend/internal/utils"
 	"net/http"
@@ -13,16 +14,13 @@ import (
 
 type UserAccessHandler struct {
 	userAccessUsecase usecases.UserAccessUsecase
-	jwtSecret         string
 }
 
 func NewUserAccessHandler(
 	userAccessUsecase usecases.UserAccessUsecase,
-	jwtSecret string,
 ) *UserAccessHandler {
 	return &UserAccessHandler{
 		userAccessUsecase: userAccessUsecase,
-		jwtSecret:         jwtSecret,
 	}
 }
 
@@ -37,9 +35,14 @@ func (h *UserAccessHandler) CreateUserAccess(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	if err := h.userAccessUsecase.CreateUserAccess(request); err != nil {
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	if err := h.userAccessUsecase.CreateUserAccess(request, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to create user access", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to create user access", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusCreated, "User access created successfully")
@@ -56,14 +59,10 @@ func (h *UserAccessHandler) GetUserAccessByID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid user access ID", err.Error())
 	}
 
-	access, err := h.userAccessUsecase.GetUserAccessByID(id)
+	access, err := h.userAccessUsecase.GetUserAccessByID(id, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get user access", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get user access", err.Error())
-	}
-
-	if access == nil {
-		return ErrorResponse(c, http.StatusNotFound, "User access not found", "")
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "User access retrieved successfully", access)
@@ -74,10 +73,10 @@ func (h *UserAccessHandler) GetAllUserAccess(c echo.Context) error {
 		svcName = "GetAllUserAccess"
 	)
 
-	accesses, err := h.userAccessUsecase.GetAllUserAccess()
+	accesses, err := h.userAccessUsecase.GetAllUserAccess(middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get all user access", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get all user access", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "User accesses retrieved successfully", accesses)
@@ -94,10 +93,10 @@ func (h *UserAccessHandler) GetAllUserAccessDataTables(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	response, err := h.userAccessUsecase.GetAllUserAccessDataTables(request)
+	response, err := h.userAccessUsecase.GetAllUserAccessDataTables(request, middleware.AccessScope(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to get user access data tables", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to get user access data tables", err.Error())
+		return err
 	}
 
 	return SuccessResponse(c, http.StatusOK, "User accesses retrieved successfully", response)
@@ -120,9 +119,14 @@ func (h *UserAccessHandler) UpdateUserAccess(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	if err := h.userAccessUsecase.UpdateUserAccess(id, request); err != nil {
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	if err := h.userAccessUsecase.UpdateUserAccess(id, request, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to update user access", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to update user access", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "User access updated successfully")
@@ -145,9 +149,18 @@ func (h *UserAccessHandler) UpdateUserPassword(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
 
-	if err := h.userAccessUsecase.UpdateUserPassword(id, request); err != nil {
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	// an account changing its own password needs no right to manage it
+	callerID, isStaff := middleware.StaffUserID(c)
+	self := isStaff && callerID == id
+
+	if err := h.userAccessUsecase.UpdateUserPassword(id, request, self, middleware.AccessScope(c), loginClient(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to update user password", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to update user password", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "User password updated successfully")
@@ -164,9 +177,9 @@ func (h *UserAccessHandler) DeleteUserAccess(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid user access ID", err.Error())
 	}
 
-	if err := h.userAccessUsecase.DeleteUserAccess(id); err != nil {
+	if err := h.userAccessUsecase.DeleteUserAccess(id, middleware.AccessScope(c)); err != nil {
 		utils.LoggMsg(svcName, "Failed to delete user access", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to delete user access", err.Error())
+		return err
 	}
 
 	return MessageResponse(c, http.StatusOK, "User access deleted successfully")
@@ -182,15 +195,16 @@ func (h *UserAccessHandler) UserLogin(c echo.Context) error {
 		utils.LoggMsg(svcName, "Failed to bind request", err)
 		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
 	}
-	if request.Username == "" || request.Password == "" {
-		utils.LoggMsg(svcName, "Username and password are required", nil)
-		return ErrorResponse(c, http.StatusBadRequest, "Username and password are required", "")
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
 	}
 	fmt.Println("::::::", request)
-	response, err := h.userAccessUsecase.AuthenticateUser(request)
+	response, err := h.userAccessUsecase.AuthenticateUser(request, loginClient(c))
 	if err != nil {
 		utils.LoggMsg(svcName, "Failed to authenticate user", err)
-		return ErrorResponse(c, http.StatusInternalServerError, "Failed to authenticate user", err.Error())
+		return err
 	}
 
 	if response == nil {
@@ -199,3 +213,135 @@ func (h *UserAccessHandler) UserLogin(c echo.Context) error {
 
 	return SuccessResponse(c, http.StatusOK, "Login successful", response)
 }
+
+func (h *UserAccessHandler) RefreshToken(c echo.Context) error {
+	var (
+		request entities.RefreshTokenRequest
+		svcName = "RefreshToken"
+	)
+
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Failed to bind request", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	response, err := h.userAccessUsecase.RefreshToken(request)
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to refresh token", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Token refreshed successfully", response)
+}
+
+func (h *UserAccessHandler) Logout(c echo.Context) error {
+	var (
+		request entities.RefreshTokenRequest
+		svcName = "Logout"
+	)
+
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Failed to bind request", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	if err := h.userAccessUsecase.Logout(request); err != nil {
+		utils.LoggMsg(svcName, "Failed to logout", err)
+		return err
+	}
+
+	return MessageResponse(c, http.StatusOK, "Logout successful")
+}
+
+func (h *UserAccessHandler) RevokeSessions(c echo.Context) error {
+	var (
+		svcName = "RevokeSessions"
+	)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid user access ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid user access ID", err.Error())
+	}
+
+	response, err := h.userAccessUsecase.RevokeSessions(id, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to revoke sessions", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusOK, "Sessions revoked successfully", response)
+}
+
+func (h *UserAccessHandler) UnlockUserAccess(c echo.Context) error {
+	var (
+		svcName = "UnlockUserAccess"
+	)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid user access ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid user access ID", err.Error())
+	}
+
+	if err := h.userAccessUsecase.UnlockUserAccess(id, middleware.AccessScope(c)); err != nil {
+		utils.LoggMsg(svcName, "Failed to unlock user access", err)
+		return err
+	}
+
+	return MessageResponse(c, http.StatusOK, "User access unlocked successfully")
+}
+
+func (h *UserAccessHandler) IssuePasswordReset(c echo.Context) error {
+	var (
+		svcName = "IssuePasswordReset"
+	)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.LoggMsg(svcName, "Invalid user access ID", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid user access ID", err.Error())
+	}
+
+	response, err := h.userAccessUsecase.IssuePasswordReset(id, middleware.AccessScope(c))
+	if err != nil {
+		utils.LoggMsg(svcName, "Failed to issue password reset", err)
+		return err
+	}
+
+	return SuccessResponse(c, http.StatusCreated, "Password reset issued successfully", response)
+}
+
+func (h *UserAccessHandler) ResetPassword(c echo.Context) error {
+	var (
+		request entities.ResetPasswordRequest
+		svcName = "ResetPassword"
+	)
+
+	if err := c.Bind(&request); err != nil {
+		utils.LoggMsg(svcName, "Failed to bind request", err)
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		utils.LoggMsg(svcName, "Invalid request", err)
+		return ValidationErrorResponse(c, err)
+	}
+
+	if err := h.userAccessUsecase.ResetPassword(request); err != nil {
+		utils.LoggMsg(svcName, "Failed to reset password", err)
+		return err
+	}
+
+	return MessageResponse(c, http.StatusOK, "Password reset successfully")
+}
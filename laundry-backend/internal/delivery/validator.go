@@ -0,0 +1,172 @@
+package delivery
+
+import (
+	"errors"
+	"laundry-backend/constant"
+	"laundry-backend/internal/entities"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+	"github.com/labstack/echo/v4"
+)
+
+// phonePattern accepts Indonesian phone numbers written as 08xx, 628xx or +628xx, landlines included
+var phonePattern = regexp.MustCompile(`^(\+62|62|0)[1-9][0-9]{7,12}$`)
+
+// clockPattern accepts a time of day written as HH:MM or HH:MM:SS, as stored in TIME columns
+var clockPattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9](:[0-5][0-9])?$`)
+
+// RequestValidator is the echo.Validator behind c.Validate; rules come from the validate tags of the request entities
+type RequestValidator struct {
+	validate *validator.Validate
+	transID  ut.Translator
+	transEN  ut.Translator
+}
+
+// NewRequestValidator builds the validator with Indonesian and English messages and the custom phone and clock rules
+func NewRequestValidator() (*RequestValidator, error) {
+	validate := validator.New()
+
+	// Report fields by their JSON name, the name clients know
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	if err := validate.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		return phonePattern.MatchString(fl.Field().String())
+	}); err != nil {
+		return nil, err
+	}
+	if err := validate.RegisterValidation("clock", func(fl validator.FieldLevel) bool {
+		return clockPattern.MatchString(fl.Field().String())
+	}); err != nil {
+		return nil, err
+	}
+
+	universal := ut.New(en.New(), en.New(), id.New())
+	transEN, _ := universal.GetTranslator("en")
+	transID, _ := universal.GetTranslator("id")
+
+	if err := enTranslations.RegisterDefaultTranslations(validate, transEN); err != nil {
+		return nil, err
+	}
+	if err := idTranslations.RegisterDefaultTranslations(validate, transID); err != nil {
+		return nil, err
+	}
+
+	custom := []struct {
+		trans ut.Translator
+		tag   string
+		text  string
+	}{
+		{transEN, "phone", "{0} must be a valid phone number"},
+		{transID, "phone", "{0} harus berupa nomor telepon yang valid"},
+		{transEN, "clock", "{0} must be a time in HH:MM format"},
+		{transID, "clock", "{0} harus berupa jam dengan format HH:MM"},
+		{transEN, "timezone", "{0} must be an IANA time zone such as Asia/Jakarta"},
+		{transID, "timezone", "{0} harus berupa zona waktu IANA seperti Asia/Jakarta"},
+		{transEN, "required_with", "{0} is required when {1} is set"},
+		{transID, "required_with", "{0} wajib diisi jika {1} diisi"},
+		{transID, "datetime", "{0} tidak sesuai dengan format {1}"},
+	}
+	for _, t := range custom {
+		if err := registerTranslation(validate, t.trans, t.tag, t.text); err != nil {
+			return nil, err
+		}
+	}
+
+	return &RequestValidator{
+		validate: validate,
+		transID:  transID,
+		transEN:  transEN,
+	}, nil
+}
+
+func registerTranslation(validate *validator.Validate, trans ut.Translator, tag, text string) error {
+	return validate.RegisterTranslation(tag, trans,
+		func(ut ut.Translator) error {
+			return ut.Add(tag, text, true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			message, err := ut.T(tag, fe.Field(), fe.Param())
+			if err != nil {
+				return fe.Error()
+			}
+			return message
+		},
+	)
+}
+
+// Validate checks i against its validate tags and returns a *ValidationError listing every failing field
+func (v *RequestValidator) Validate(i interface{}) error {
+	err := v.validate.Struct(i)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+
+	result := &ValidationError{}
+	for _, fe := range fieldErrors {
+		result.Fields = append(result.Fields, entities.FieldError{
+			Field:     fieldPath(fe),
+			Rule:      fe.Tag(),
+			MessageID: fe.Translate(v.transID),
+			MessageEN: fe.Translate(v.transEN),
+		})
+	}
+	return result
+}
+
+// fieldPath is the JSON path of a failing field without the request struct name, e.g. items[0].jumlah
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// ValidationError lists the request fields that failed validation
+type ValidationError struct {
+	Fields []entities.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.MessageEN)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ValidationErrorResponse answers 400 with the per-field errors of c.Validate in the result
+func ValidationErrorResponse(c echo.Context, err error) error {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return ErrorResponse(c, http.StatusBadRequest, "Invalid request", err.Error())
+	}
+
+	return c.JSON(http.StatusBadRequest, entities.APIResponse{
+		Status:  http.StatusBadRequest,
+		Code:    constant.BadRequestCode,
+		Message: "Validasi gagal / Validation failed",
+		Result:  validationErr.Fields,
+		Error:   validationErr.Error(),
+	})
+}
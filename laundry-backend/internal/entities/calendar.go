@@ -0,0 +1,52 @@
+package entities
+
+import "time"
+
+// Holiday types of hari_libur.jenis
+const (
+	HolidayNational = "nasional"
+	HolidayBrand    = "brand"
+	HolidayOutlet   = "outlet"
+)
+
+// Reasons an outlet is closed, in OutletAvailability.Reason
+const (
+	ClosedHoliday      = "hari_libur"
+	ClosedDay          = "hari_tutup"
+	ClosedOutsideHours = "di_luar_jam_operasional"
+)
+
+// OpeningHours is when an outlet is open on one weekday (0 = Minggu ... 6 = Sabtu).
+// A CloseTime before OpenTime means the outlet closes after midnight; the same time, or none, means open all day.
+type OpeningHours struct {
+	OutletID  int    `json:"id_outlet"`
+	Weekday   int    `json:"hari"`
+	OpenTime  string `json:"jam_buka"`
+	CloseTime string `json:"jam_tutup"`
+	Closed    bool   `json:"tutup"`
+}
+
+// Holiday is a day an outlet is closed: every outlet for a national holiday, the outlets of BrandID
+// for a brand holiday and OutletID alone for an outlet holiday. Date is formatted as 2006-01-02.
+type Holiday struct {
+	ID        int       `json:"id"`
+	Type      string    `json:"jenis"`
+	BrandID   *int      `json:"id_brand"`
+	OutletID  *int      `json:"id_outlet"`
+	Date      string    `json:"tanggal"`
+	Name      string    `json:"keterangan"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OutletAvailability tells whether an outlet is open at a given time. ClosesAt is set while it is open, unless it never closes;
+// Reason and OpensAt are set while it is closed, Holiday when it is closed for a holiday.
+type OutletAvailability struct {
+	OutletID int        `json:"id_outlet"`
+	At       time.Time  `json:"waktu"`
+	Open     bool       `json:"buka"`
+	Reason   string     `json:"alasan,omitempty"`
+	Holiday  *Holiday   `json:"hari_libur,omitempty"`
+	ClosesAt *time.Time `json:"tutup_pada,omitempty"`
+	OpensAt  *time.Time `json:"buka_pada,omitempty"`
+}
@@ -5,25 +5,30 @@ import (
 )
 
 type User struct {
-	ID        int       `json:"id"`
-	Email     string    `json:"email"`
-	Password  string    `json:"password"`
-	Name      string    `json:"name"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       int    `json:"id"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	// FailedLoginAttempts and LockedUntil throttle password guessing, see login_audit
+	FailedLoginAttempts int        `json:"-"`
+	LockedUntil         *time.Time `json:"-"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 type Brand struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	PICName     string    `json:"pic_name"`
-	PICEmail    string    `json:"pic_email"`
-	PICTelepon  string    `json:"pic_telepon"`
-	LogoURL     string    `json:"logo_url"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	PICName       string    `json:"pic_name"`
+	PICEmail      string    `json:"pic_email"`
+	PICTelepon    string    `json:"pic_telepon"`
+	LogoURL       string    `json:"logo_url"`
+	Code          string    `json:"kode_brand"`
+	InvoiceFormat string    `json:"format_invoice"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type Cabang struct {
@@ -45,58 +50,92 @@ type Cabang struct {
 }
 
 type Outlet struct {
-	ID         int       `json:"id"`
-	CabangID   int       `json:"cabang_id"`
-	Name       string    `json:"name"`
-	Address    string    `json:"address"`
-	City       string    `json:"city"`
-	Province   string    `json:"province"`
-	PostalCode string    `json:"postal_code"`
-	Phone      string    `json:"phone"`
-	Email      string    `json:"email"`
-	Latitude   *float64  `json:"latitude"`
-	Longitude  *float64  `json:"longitude"`
-	OpenTime   string    `json:"open_time"`
-	CloseTime  string    `json:"close_time"`
-	PICName    string    `json:"pic_name"`
-	PICEmail   string    `json:"pic_email"`
-	PICTelepon string    `json:"pic_telepon"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID                  int       `json:"id"`
+	CabangID            int       `json:"cabang_id"`
+	Name                string    `json:"name"`
+	Address             string    `json:"address"`
+	City                string    `json:"city"`
+	Province            string    `json:"province"`
+	PostalCode          string    `json:"postal_code"`
+	Phone               string    `json:"phone"`
+	Email               string    `json:"email"`
+	Latitude            *float64  `json:"latitude"`
+	Longitude           *float64  `json:"longitude"`
+	OpenTime            string    `json:"open_time"`
+	CloseTime           string    `json:"close_time"`
+	PICName             string    `json:"pic_name"`
+	PICEmail            string    `json:"pic_email"`
+	PICTelepon          string    `json:"pic_telepon"`
+	RequirePaidOnPickup bool      `json:"wajib_lunas_saat_diambil"`
+	Code                string    `json:"kode_outlet"`
+	Timezone            string    `json:"zona_waktu"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// DefaultOutletTimezone is the IANA time zone of an outlet registered without one (WIB)
+const DefaultOutletTimezone = "Asia/Jakarta"
+
+// DefaultInvoiceFormat numbers invoices per outlet per month, e.g. BRD-OTL1-2610-00042
+const DefaultInvoiceFormat = "{BRAND}-{OUTLET}-{YYMM}-{SEQ:5}"
+
+// InvoiceSettings is what a new nomor_invoice of an outlet is built from.
+// Format placeholders: {BRAND}, {OUTLET}, dates such as {YYYY}, {YYMM} or {YYMMDD}, and {SEQ} or {SEQ:n} zero-padded to n digits.
+type InvoiceSettings struct {
+	OutletID   int
+	OutletCode string
+	BrandCode  string
+	Format     string
 }
 
 type Transaction struct {
-	ID             int        `json:"id"`
-	CustomerID     int        `json:"id_pelanggan"`
-	OutletID       int        `json:"id_outlet"`
-	UserID         *int       `json:"id_access"`
-	InvoiceNumber  string     `json:"nomor_invoice"`
-	EntryDate      *time.Time `json:"tanggal_masuk"`
-	CompletionDate *time.Time `json:"tanggal_selesai"`
-	PickupDate     *time.Time `json:"tanggal_diambil"`
-	TotalPrice     float64    `json:"total_harga"`
-	PaidAmount     float64    `json:"uang_bayar"`
-	ChangeAmount   float64    `json:"uang_kembalian"`
-	Status         string     `json:"status_transaksi"`
-	Note           string     `json:"catatan"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
-	CreatedBy      *string    `json:"created_by"`
-	UpdatedBy      *string    `json:"updated_by"`
+	ID              int        `json:"id"`
+	CustomerID      int        `json:"id_pelanggan"`
+	OutletID        int        `json:"id_outlet"`
+	UserID          *int       `json:"id_access"`
+	InvoiceNumber   string     `json:"nomor_invoice"`
+	EntryDate       *time.Time `json:"tanggal_masuk"`
+	CompletionDate  *time.Time `json:"tanggal_selesai"`
+	PickupDate      *time.Time `json:"tanggal_diambil"`
+	EstimatedReady  *time.Time `json:"estimasi_selesai"`
+	TotalPrice      float64    `json:"total_harga"`
+	ServiceSubtotal float64    `json:"subtotal_layanan"`
+	AdminFee        float64    `json:"biaya_admin"`
+	MerchantFee     float64    `json:"biaya_merchant"`
+	FeeBearer       string     `json:"biaya_ditanggung"`
+	PaidAmount      float64    `json:"uang_bayar"`
+	ChangeAmount    float64    `json:"uang_kembalian"`
+	Status          string     `json:"status_transaksi"`
+	TotalPaid       float64    `json:"total_dibayar"`
+	Outstanding     float64    `json:"sisa_tagihan"`
+	PaymentStatus   string     `json:"status_pembayaran"`
+	Note            string     `json:"catatan"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CreatedBy       *string    `json:"created_by"`
+	UpdatedBy       *string    `json:"updated_by"`
 }
 
 type TransactionDetail struct {
-	ID            int       `json:"id"`
-	TransactionID int       `json:"id_transaksi"`
-	ServiceID     int       `json:"id_layanan"`
-	Quantity      *float64  `json:"kuantitas"`
-	Price         *float64  `json:"harga_satuan"`
-	Subtotal      *float64  `json:"subtotal"`
-	Status        *float64  `json:"status_pengerjaan"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	CreatedBy     *string   `json:"created_by"`
-	UpdatedBy     *string   `json:"updated_by"`
+	ID              int        `json:"id"`
+	TransactionID   int        `json:"id_transaksi"`
+	ServiceID       int        `json:"id_layanan"`
+	Quantity        *float64   `json:"kuantitas"`
+	ChargedQuantity *float64   `json:"kuantitas_ditagih"`
+	Price           *float64   `json:"harga_satuan"`
+	BasePrice       *float64   `json:"harga_dasar"`
+	SurchargeTotal  float64    `json:"total_biaya_tambahan"`
+	Subtotal        *float64   `json:"subtotal"`
+	EstimatedReady  *time.Time `json:"estimasi_selesai"`
+	Status          *float64   `json:"status_pengerjaan"`
+	Note            string     `json:"catatan"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CreatedBy       *string    `json:"created_by"`
+	UpdatedBy       *string    `json:"updated_by"`
+	// ChargedQuantity is Quantity after the rounding and minimum of the pricing rule, BasePrice its price;
+	// Modifiers are the surcharges and add-ons of the line and Subtotal is BasePrice plus SurchargeTotal
+	Modifiers []TransactionDetailModifier `json:"modifier"`
 }
 
 type Employee struct {
@@ -128,6 +167,16 @@ type Customer struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Status of a single pembayaran row and the paid/unpaid state derived for a transaksi
+const (
+	PaymentStatusFailed  = "gagal"
+	PaymentStatusPending = "pending"
+	PaymentStatusSuccess = "sukses"
+
+	PaymentStatusPaid   = "lunas"
+	PaymentStatusUnpaid = "belum lunas"
+)
+
 type Payment struct {
 	ID                   int        `json:"id_pembayaran"`
 	TransactionID        int        `json:"id_transaksi"`
@@ -138,11 +187,70 @@ type Payment struct {
 	PartnerReferenceNo   string     `json:"nomor_referensi_partner"`
 	PartnerStatusCode    string     `json:"status_code_partner"`
 	PartnerStatusMessage string     `json:"status_message_partner"`
+	Status               string     `json:"status_pembayaran"`
 	Note                 string     `json:"catatan"`
 	CreatedAt            time.Time  `json:"created_at"`
 	UpdatedAt            time.Time  `json:"updated_at"`
 }
 
+// PaymentCharge is a charge opened at a payment gateway, with the link or QR payload the customer pays with
+type PaymentCharge struct {
+	ReferenceNo string     `json:"nomor_referensi_partner"`
+	Amount      float64    `json:"jumlah_bayar"`
+	Status      string     `json:"status_pembayaran"`
+	PaymentURL  string     `json:"tautan_pembayaran,omitempty"`
+	QRString    string     `json:"qris,omitempty"`
+	ExpiredAt   *time.Time `json:"kedaluwarsa,omitempty"`
+}
+
+// PaymentRefund is money returned against one pembayaran row through its gateway
+type PaymentRefund struct {
+	ID            int       `json:"id_refund"`
+	PaymentID     int       `json:"id_pembayaran"`
+	TransactionID int       `json:"id_transaksi"`
+	ReferenceNo   string    `json:"nomor_referensi_partner"`
+	Amount        float64   `json:"jumlah_refund"`
+	Status        string    `json:"status_refund"`
+	Reason        string    `json:"alasan"`
+	CreatedAt     time.Time `json:"created_at"`
+	CreatedBy     string    `json:"created_by"`
+}
+
+// RevenueReport sums the transaksi entered in a period; cancelled orders are only counted, never summed
+type RevenueReport struct {
+	OutletID         int       `json:"id_outlet,omitempty"`
+	From             time.Time `json:"dari"`
+	To               time.Time `json:"sampai"`
+	TransactionCount int       `json:"jumlah_transaksi"`
+	GrossSales       float64   `json:"total_penjualan"`
+	BaseSales        float64   `json:"total_harga_dasar"`
+	SurchargeSales   float64   `json:"total_biaya_tambahan"`
+	TotalCollected   float64   `json:"total_diterima"`
+	Outstanding      float64   `json:"total_piutang"`
+	CancelledCount   int       `json:"jumlah_dibatalkan"`
+	TotalRefunded    float64   `json:"total_refund"`
+}
+
+// Outcome of a raw gateway callback recorded in callback_pembayaran
+const (
+	CallbackResultProcessed = "diproses"
+	CallbackResultDuplicate = "duplikat"
+	CallbackResultRejected  = "ditolak"
+)
+
+type PaymentCallbackLog struct {
+	ID              int       `json:"id_callback"`
+	PaymentMethodID int       `json:"id_metode_pembayaran"`
+	TransactionID   int       `json:"id_transaksi"`
+	ReferenceNo     string    `json:"nomor_referensi_pembayaran"`
+	Payload         string    `json:"payload"`
+	Signature       string    `json:"signature"`
+	SignatureValid  bool      `json:"signature_valid"`
+	Result          string    `json:"hasil"`
+	Message         string    `json:"pesan"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
 type HistoryStatusTransaction struct {
 	ID            int        `json:"id_history"`
 	TransactionID int        `json:"id_transaksi"`
@@ -150,6 +258,7 @@ type HistoryStatusTransaction struct {
 	NewStatus     string     `json:"status_baru"`
 	ChangeTime    *time.Time `json:"waktu_perubahan"`
 	Description   string     `json:"keterangan"`
+	ChangedBy     string     `json:"diubah_oleh"`
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
 }
@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// IdempotencyKey is a request sent with an Idempotency-Key header and, once CompletedAt is set, the response it got.
+// Owner scopes the key to the token that sent it, as "<aud>:<user_id>".
+type IdempotencyKey struct {
+	ID           int        `json:"id"`
+	Owner        string     `json:"owner"`
+	Key          string     `json:"idempotency_key"`
+	RequestHash  string     `json:"-"`
+	StatusCode   int        `json:"status_code"`
+	ResponseBody string     `json:"-"`
+	ExpiredAt    time.Time  `json:"expired_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
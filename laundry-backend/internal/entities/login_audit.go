@@ -0,0 +1,36 @@
+package entities
+
+import "time"
+
+// Logins recorded in login_audit
+const (
+	// LoginTypeUsers is the super admin login against the users table
+	LoginTypeUsers = "users"
+	// LoginTypeUserAccess is the employee login against the user_access table
+	LoginTypeUserAccess = "user_access"
+)
+
+// Reasons a login attempt is rejected, stored in login_audit.failure_reason
+const (
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureAccountLocked      = "account_locked"
+	LoginFailureTooManyAttempts    = "too_many_attempts"
+)
+
+// LoginAudit is one login attempt; FailureReason is empty when the attempt succeeded
+type LoginAudit struct {
+	ID            int       `json:"id"`
+	LoginType     string    `json:"login_type"`
+	Username      string    `json:"username"`
+	IPAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// LoginClient identifies where a login attempt comes from
+type LoginClient struct {
+	IPAddress string
+	UserAgent string
+}
@@ -0,0 +1,80 @@
+package entities
+
+import "time"
+
+// Modifier types of modifier_layanan.jenis
+const (
+	ModifierSurcharge = "surcharge"
+	ModifierAddon     = "addon"
+)
+
+// Value types of modifier_layanan.tipe_nilai
+const (
+	ModifierValuePercent = "persen"
+	ModifierValueFlat    = "flat"
+)
+
+// Modifier is a surcharge or an add-on of a brand that can be selected on an order line. It applies to ServiceID
+// alone, to the services of CategoryID, or to every service of the brand when neither is set.
+// A percentage surcharge adds Value percent of the line's base price; a flat surcharge adds Value once per line
+// and an add-on adds Value for every piece. Estimation and Unit, when set, replace the duration of the service.
+type Modifier struct {
+	ID         int       `json:"id"`
+	BrandID    int       `json:"id_brand"`
+	ServiceID  *int      `json:"id_layanan"`
+	CategoryID *int      `json:"id_kategori"`
+	Name       string    `json:"nama"`
+	Type       string    `json:"jenis"`
+	ValueType  string    `json:"tipe_nilai"`
+	Value      float64   `json:"nilai"`
+	Estimation int       `json:"durasi_pengerjaan,omitempty"`
+	Unit       string    `json:"satuan_durasi,omitempty"`
+	Active     bool      `json:"aktif"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ModifierFilter narrows the modifiers listed; ServiceID keeps only the active modifiers that apply to that service
+type ModifierFilter struct {
+	BrandID   int
+	ServiceID int
+}
+
+// TransactionDetailModifier is a modifier as it was applied to a detail_transaksi, kept apart from modifier_layanan
+// so receipts do not change when the modifier is edited or deleted
+type TransactionDetailModifier struct {
+	ID         int     `json:"id"`
+	DetailID   int     `json:"id_detail"`
+	ModifierID *int    `json:"id_modifier"`
+	Name       string  `json:"nama"`
+	Type       string  `json:"jenis"`
+	ValueType  string  `json:"tipe_nilai"`
+	Value      float64 `json:"nilai"`
+	Quantity   float64 `json:"kuantitas"`
+	Amount     float64 `json:"jumlah"`
+}
+
+type CreateModifierRequest struct {
+	BrandID    int     `json:"id_brand" validate:"required,gt=0"`
+	ServiceID  *int    `json:"id_layanan" validate:"omitempty,gt=0"`
+	CategoryID *int    `json:"id_kategori" validate:"omitempty,gt=0"`
+	Name       string  `json:"nama" validate:"required,max=100"`
+	Type       string  `json:"jenis" validate:"required,oneof=surcharge addon"`
+	ValueType  string  `json:"tipe_nilai" validate:"required,oneof=persen flat"`
+	Value      float64 `json:"nilai" validate:"gte=0"`
+	Estimation int     `json:"durasi_pengerjaan" validate:"gte=0"`
+	Unit       string  `json:"satuan_durasi" validate:"required_with=Estimation,omitempty,oneof=jam hari"`
+	Active     *bool   `json:"aktif"`
+}
+
+type UpdateModifierRequest struct {
+	ServiceID  *int    `json:"id_layanan" validate:"omitempty,gt=0"`
+	CategoryID *int    `json:"id_kategori" validate:"omitempty,gt=0"`
+	Name       string  `json:"nama" validate:"required,max=100"`
+	Type       string  `json:"jenis" validate:"required,oneof=surcharge addon"`
+	ValueType  string  `json:"tipe_nilai" validate:"required,oneof=persen flat"`
+	Value      float64 `json:"nilai" validate:"gte=0"`
+	Estimation int     `json:"durasi_pengerjaan" validate:"gte=0"`
+	Unit       string  `json:"satuan_durasi" validate:"required_with=Estimation,omitempty,oneof=jam hari"`
+	Active     *bool   `json:"aktif"`
+}
@@ -0,0 +1,51 @@
+package entities
+
+import "time"
+
+// PricingRule is how a service is priced, at OutletID or at every outlet without its own rule when OutletID is nil.
+// The quantity received is rounded up to a multiple of RoundingStep and charged for at least MinimumQuantity;
+// UnitPrice, when set, replaces the price of the service. With Tiers, the quantity above a tier's From
+// is charged at the tier's price.
+type PricingRule struct {
+	ID              int         `json:"id"`
+	ServiceID       int         `json:"id_layanan"`
+	OutletID        *int        `json:"id_outlet"`
+	UnitPrice       *float64    `json:"harga_satuan"`
+	MinimumQuantity float64     `json:"kuantitas_minimum"`
+	RoundingStep    float64     `json:"pembulatan"`
+	Tiers           []PriceTier `json:"tingkat_harga"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+// PriceTier is the unit price of the quantity above From
+type PriceTier struct {
+	From      float64 `json:"kuantitas_dari" validate:"gt=0"`
+	UnitPrice float64 `json:"harga_satuan" validate:"gte=0"`
+}
+
+// SavePricingRuleRequest sets the pricing rule of a service at OutletID, or at every outlet when OutletID is 0
+type SavePricingRuleRequest struct {
+	OutletID        int         `json:"id_outlet" validate:"gte=0"`
+	UnitPrice       *float64    `json:"harga_satuan" validate:"omitempty,gte=0"`
+	MinimumQuantity float64     `json:"kuantitas_minimum" validate:"gte=0"`
+	RoundingStep    float64     `json:"pembulatan" validate:"gte=0"`
+	Tiers           []PriceTier `json:"tingkat_harga" validate:"omitempty,dive"`
+}
+
+// QuoteRequest prices the items of an inquiry without saving it; without a payment method the fees are left out
+type QuoteRequest struct {
+	Items           []InquiryItemRequest `json:"items" validate:"required,min=1,dive"`
+	OutletID        int                  `json:"id_outlet" validate:"gte=0"`
+	PaymentMethodID int                  `json:"id_metode_pembayaran" validate:"gte=0"`
+	UserID          int                  `json:"id_user"`
+}
+
+// InquiryQuote is the priced breakdown of an inquiry as POST /inquiry would save it
+type InquiryQuote struct {
+	OutletID       int                 `json:"id_outlet"`
+	Items          []TransactionDetail `json:"detail_transaksi"`
+	FeeBreakdown   FeeBreakdown        `json:"rincian_biaya"`
+	TotalPrice     float64             `json:"total_harga"`
+	EstimatedReady *time.Time          `json:"estimasi_selesai"`
+}
@@ -1,8 +1,10 @@
 package entities
 
+import "time"
+
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
 }
 
 type LoginResponse struct {
@@ -11,96 +13,203 @@ type LoginResponse struct {
 }
 
 type RegisterBrandRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	PICName     string `json:"pic_name"`
-	PICEmail    string `json:"pic_email"`
-	PICTelepon  string `json:"pic_telepon"`
-	LogoURL     string `json:"logo_url"`
+	Name          string `json:"name" validate:"required,max=100"`
+	Description   string `json:"description"`
+	PICName       string `json:"pic_name"`
+	PICEmail      string `json:"pic_email" validate:"omitempty,email"`
+	PICTelepon    string `json:"pic_telepon" validate:"omitempty,phone"`
+	LogoURL       string `json:"logo_url" validate:"omitempty,url"`
+	Code          string `json:"kode_brand" validate:"omitempty,alphanum,max=10"`
+	InvoiceFormat string `json:"format_invoice" validate:"omitempty,max=100"`
 }
 
 type RegisterCabangRequest struct {
-	BrandID    int    `json:"brand_id"`
-	Name       string `json:"name"`
+	BrandID    int    `json:"brand_id" validate:"required,gt=0"`
+	Name       string `json:"name" validate:"required,max=100"`
 	Address    string `json:"address"`
 	City       string `json:"city"`
 	Province   string `json:"province"`
-	PostalCode string `json:"postal_code"`
-	Phone      string `json:"phone"`
-	Email      string `json:"email"`
+	PostalCode string `json:"postal_code" validate:"omitempty,numeric,len=5"`
+	Phone      string `json:"phone" validate:"omitempty,phone"`
+	Email      string `json:"email" validate:"omitempty,email"`
 	PICName    string `json:"pic_name"`
-	PICEmail   string `json:"pic_email"`
-	PICTelepon string `json:"pic_telepon"`
+	PICEmail   string `json:"pic_email" validate:"omitempty,email"`
+	PICTelepon string `json:"pic_telepon" validate:"omitempty,phone"`
 }
 
 type RegisterOutletRequest struct {
-	CabangID   int     `json:"cabang_id"`
-	Name       string  `json:"name"`
-	Address    string  `json:"address"`
-	City       string  `json:"city"`
-	Province   string  `json:"province"`
-	PostalCode string  `json:"postal_code"`
-	Phone      string  `json:"phone"`
-	Email      string  `json:"email"`
-	Latitude   float64 `json:"latitude"`
-	Longitude  float64 `json:"longitude"`
-	OpenTime   string  `json:"open_time"`
-	CloseTime  string  `json:"close_time"`
-	PICName    string  `json:"pic_name"`
-	PICEmail   string  `json:"pic_email"`
-	PICTelepon string  `json:"pic_telepon"`
+	CabangID            int     `json:"cabang_id" validate:"required,gt=0"`
+	Name                string  `json:"name" validate:"required,max=100"`
+	Address             string  `json:"address"`
+	City                string  `json:"city"`
+	Province            string  `json:"province"`
+	PostalCode          string  `json:"postal_code" validate:"omitempty,numeric,len=5"`
+	Phone               string  `json:"phone" validate:"omitempty,phone"`
+	Email               string  `json:"email" validate:"omitempty,email"`
+	Latitude            float64 `json:"latitude" validate:"min=-90,max=90"`
+	Longitude           float64 `json:"longitude" validate:"min=-180,max=180"`
+	OpenTime            string  `json:"open_time" validate:"required_with=CloseTime,omitempty,clock"`
+	CloseTime           string  `json:"close_time" validate:"required_with=OpenTime,omitempty,clock"`
+	PICName             string  `json:"pic_name"`
+	PICEmail            string  `json:"pic_email" validate:"omitempty,email"`
+	PICTelepon          string  `json:"pic_telepon" validate:"omitempty,phone"`
+	RequirePaidOnPickup *bool   `json:"wajib_lunas_saat_diambil"`
+	Code                string  `json:"kode_outlet" validate:"omitempty,alphanum,max=10"`
+	Timezone            string  `json:"zona_waktu" validate:"omitempty,timezone"`
+}
+
+// OpeningHoursRequest replaces the weekly opening hours of an outlet; weekdays left out use open_time/close_time
+type OpeningHoursRequest struct {
+	Days []OpeningHoursDay `json:"jam_operasional" validate:"required,max=7,unique=Weekday,dive"`
+}
+
+type OpeningHoursDay struct {
+	Weekday   int    `json:"hari" validate:"min=0,max=6"`
+	OpenTime  string `json:"jam_buka" validate:"required_unless=Closed true,omitempty,clock"`
+	CloseTime string `json:"jam_tutup" validate:"required_unless=Closed true,omitempty,clock"`
+	Closed    bool   `json:"tutup"`
+}
+
+type CreateHolidayRequest struct {
+	Type     string `json:"jenis" validate:"required,oneof=nasional brand outlet"`
+	BrandID  int    `json:"id_brand" validate:"required_if=Type brand"`
+	OutletID int    `json:"id_outlet" validate:"required_if=Type outlet"`
+	Date     string `json:"tanggal" validate:"required,datetime=2006-01-02"`
+	Name     string `json:"keterangan" validate:"max=100"`
 }
 
 type InquiryRequest struct {
-	ServicePackageID int     `json:"id_layanan" validare:"required"`
-	CustomerID       int     `json:"id_pelanggan" validare:"required"`
-	OutletID         int     `json:"id_outlet"`
-	PaymentMethodID  int     `json:"id_metode_pembayaran"`
-	UserID           int     `json:"id_user"`
-	Quantity         float64 `json:"jumlah" validare:"required"`
-	Note             string  `json:"catatan"`
+	Items           []InquiryItemRequest `json:"items" validate:"required,min=1,dive"`
+	CustomerID      int                  `json:"id_pelanggan" validate:"required,gt=0"`
+	OutletID        int                  `json:"id_outlet" validate:"gte=0"`
+	PaymentMethodID int                  `json:"id_metode_pembayaran" validate:"gte=0"`
+	UserID          int                  `json:"id_user"`
+	DownPayment     float64              `json:"uang_muka" validate:"gte=0"`
+	Note            string               `json:"catatan"`
+}
+
+// InquiryItemRequest represents a single order line (one paket layanan) in an inquiry
+type InquiryItemRequest struct {
+	ServicePackageID int                      `json:"id_layanan" validate:"required,gt=0"`
+	Quantity         float64                  `json:"jumlah" validate:"required,gt=0"`
+	Note             string                   `json:"catatan"`
+	Modifiers        []InquiryModifierRequest `json:"modifier" validate:"omitempty,dive"`
+}
+
+// InquiryModifierRequest selects a surcharge or an add-on for an order line; Quantity is the number of add-on
+// pieces and defaults to 1
+type InquiryModifierRequest struct {
+	ModifierID int     `json:"id_modifier" validate:"required,gt=0"`
+	Quantity   float64 `json:"kuantitas" validate:"gte=0"`
 }
 
 type InquiryResponse struct {
 	Transaction        Transaction              `json:"transaksi"`
 	TransactionDetails []TransactionDetail      `json:"detail_transaksi"`
-	Payment            Payment                  `json:"pembayaran"`
+	Payment            *Payment                 `json:"pembayaran,omitempty"`
+	FeeBreakdown       FeeBreakdown             `json:"rincian_biaya"`
+	Charge             *PaymentCharge           `json:"tagihan_pembayaran,omitempty"`
 	History            HistoryStatusTransaction `json:"history_status_transaksi"`
 }
 
+// FeeBreakdown is how the total_harga of a transaksi is made up
+type FeeBreakdown struct {
+	ServiceSubtotal float64 `json:"subtotal_layanan"`
+	AdminFee        float64 `json:"biaya_admin"`
+	MerchantFee     float64 `json:"biaya_merchant"`
+	FeeBearer       string  `json:"biaya_ditanggung"`
+	GrandTotal      float64 `json:"total_harga"`
+}
+
+// CancelTransactionRequest cancels a transaksi; RefundAmount defaults to everything paid so far minus the change returned
+type CancelTransactionRequest struct {
+	Reason       string   `json:"alasan" validate:"required"`
+	RefundAmount *float64 `json:"jumlah_refund" validate:"omitempty,gte=0"`
+}
+
+type TransactionCancellationResponse struct {
+	History       HistoryStatusTransaction `json:"history_status_transaksi"`
+	TotalRefunded float64                  `json:"total_refund"`
+	Refunds       []PaymentRefund          `json:"refund"`
+}
+
+// TransactionTimelineResponse is the ordered status history of a transaksi with time spent per stage
+type TransactionTimelineResponse struct {
+	TransactionID  int                        `json:"id_transaksi"`
+	InvoiceNumber  string                     `json:"nomor_invoice"`
+	CurrentStatus  string                     `json:"status_transaksi"`
+	History        []HistoryStatusTransaction `json:"history"`
+	StageDurations []StatusStageDuration      `json:"durasi_tahapan"`
+}
+
+// StatusStageDuration is how long a transaksi stayed in one status
+type StatusStageDuration struct {
+	Status          string     `json:"status"`
+	StartedAt       time.Time  `json:"mulai"`
+	EndedAt         *time.Time `json:"selesai"`
+	DurationSeconds int64      `json:"durasi_detik"`
+	Duration        string     `json:"durasi"`
+	Ongoing         bool       `json:"sedang_berjalan"`
+}
+
 type RegisterEmployeeRequest struct {
-	OutletID  int     `json:"id_outlet"`
+	OutletID  int     `json:"id_outlet" validate:"required,gt=0"`
 	NIK       string  `json:"nik"`
-	Name      string  `json:"nama_lengkap"`
-	Email     string  `json:"email"`
-	Phone     string  `json:"telepon"`
+	Name      string  `json:"nama_lengkap" validate:"required,max=100"`
+	Email     string  `json:"email" validate:"omitempty,email"`
+	Phone     string  `json:"telepon" validate:"omitempty,phone"`
 	Address   string  `json:"alamat"`
-	BirthDate string  `json:"tanggal_lahir"`
-	Gender    string  `json:"jenis_kelamin"`
+	BirthDate string  `json:"tanggal_lahir" validate:"omitempty,datetime=2006-01-02"`
+	Gender    string  `json:"jenis_kelamin" validate:"omitempty,oneof=L P"`
 	Position  string  `json:"posisi"`
-	Salary    float64 `json:"gaji"`
-	JoinDate  string  `json:"tanggal_masuk"`
-	Status    string  `json:"status"`
+	Salary    float64 `json:"gaji" validate:"gte=0"`
+	JoinDate  string  `json:"tanggal_masuk" validate:"omitempty,datetime=2006-01-02"`
+	Status    string  `json:"status" validate:"omitempty,oneof=aktif 'tidak aktif'"`
 }
 
 type RegisterCustomerRequest struct {
-	OutletID int    `json:"id_outlet"`
-	Name     string `json:"nama"`
-	Email    string `json:"email"`
-	Phone    string `json:"telepon"`
+	OutletID int    `json:"id_outlet" validate:"required,gt=0"`
+	Name     string `json:"nama" validate:"required,max=100"`
+	Email    string `json:"email" validate:"omitempty,email"`
+	Phone    string `json:"telepon" validate:"required,phone"`
 	Address  string `json:"alamat"`
 }
 
 type UpdateTransactionStatusRequest struct {
-	Status string `json:"status_transaksi" validate:"required"`
+	Status string `json:"status_transaksi" validate:"required,oneof=diterima diproses selesai diambil dibatalkan"`
+	Note   string `json:"keterangan"`
 }
 
 type UpdatePaymentStatusRequest struct {
-	Status string `json:"status_pembayaran" validate:"required"`
+	Status        string `json:"status_pembayaran" validate:"required,oneof=gagal pending sukses"`
+	StatusCode    string `json:"status_kode"`
+	StatusMessage string `json:"status_pesan"`
+}
+
+type CreatePaymentRequest struct {
+	PaymentMethodID    int     `json:"id_metode_pembayaran" validate:"required,gt=0"`
+	Amount             float64 `json:"jumlah_bayar" validate:"required,gt=0"`
+	Status             string  `json:"status_pembayaran" validate:"omitempty,oneof=gagal pending sukses"`
+	PartnerReferenceNo string  `json:"nomor_referensi_partner"`
+	Note               string  `json:"catatan"`
+}
+
+// TransactionPaymentsResponse lists the payments of a transaksi with its derived paid/unpaid state
+type TransactionPaymentsResponse struct {
+	TransactionID int             `json:"id_transaksi"`
+	TotalPrice    float64         `json:"total_harga"`
+	TotalPaid     float64         `json:"total_dibayar"`
+	ChangeAmount  float64         `json:"uang_kembalian"`
+	Outstanding   float64         `json:"sisa_tagihan"`
+	PaymentStatus string          `json:"status_pembayaran"`
+	Payments      []Payment       `json:"pembayaran"`
+	TotalRefunded float64         `json:"total_refund"`
+	Refunds       []PaymentRefund `json:"refund"`
 }
 
 type PaymentCallbackRequest struct {
 	TransactionID          int     `json:"id_transaksi" validate:"required"`
+	PaymentMethodID        int     `json:"id_metode_pembayaran"`
 	PaymentStatus          string  `json:"status_pembayaran" validate:"required"`
 	PaymentMethod          string  `json:"metode_pembayaran"`
 	PaymentReferenceNumber string  `json:"nomor_referensi_pembayaran"`
@@ -111,24 +220,38 @@ type PaymentCallbackRequest struct {
 }
 
 type CreatePaymentMethodRequest struct {
-	NamaMetode  string  `json:"nama_metode"`
-	URL         string  `json:"url"`
-	SKey        string  `json:"s_key"`
-	MKey        string  `json:"m_key"`
-	MerchantFee float64 `json:"merchant_fee"`
-	AdminFee    float64 `json:"admin_fee"`
-	Status      string  `json:"status"`
-	CreatedBy   string  `json:"created_by"`
-	UpdatedBy   string  `json:"updated_by"`
+	NamaMetode      string  `json:"nama_metode" validate:"required,max=100"`
+	URL             string  `json:"url" validate:"omitempty,url"`
+	SKey            string  `json:"s_key"`
+	MKey            string  `json:"m_key"`
+	MerchantFee     float64 `json:"merchant_fee" validate:"gte=0"`
+	AdminFee        float64 `json:"admin_fee" validate:"gte=0"`
+	MerchantFeeType string  `json:"merchant_fee_type" validate:"omitempty,oneof=flat percent"`
+	FeeBearer       string  `json:"fee_bearer" validate:"omitempty,oneof=customer merchant"`
+	Status          string  `json:"status" validate:"omitempty,oneof=active inactive"`
+	CreatedBy       string  `json:"created_by"`
+	UpdatedBy       string  `json:"updated_by"`
 }
 
 type UpdatePaymentMethodRequest struct {
-	NamaMetode  string  `json:"nama_metode"`
-	URL         string  `json:"url"`
-	SKey        string  `json:"s_key"`
-	MKey        string  `json:"m_key"`
-	MerchantFee float64 `json:"merchant_fee"`
-	AdminFee    float64 `json:"admin_fee"`
-	Status      string  `json:"status"`
-	UpdatedBy   string  `json:"updated_by"`
+	NamaMetode      string  `json:"nama_metode" validate:"required,max=100"`
+	URL             string  `json:"url" validate:"omitempty,url"`
+	MerchantFee     float64 `json:"merchant_fee" validate:"gte=0"`
+	AdminFee        float64 `json:"admin_fee" validate:"gte=0"`
+	MerchantFeeType string  `json:"merchant_fee_type" validate:"omitempty,oneof=flat percent"`
+	FeeBearer       string  `json:"fee_bearer" validate:"omitempty,oneof=customer merchant"`
+	Status          string  `json:"status" validate:"omitempty,oneof=active inactive"`
+	UpdatedBy       string  `json:"updated_by"`
+}
+
+// UpdatePaymentMethodSecretsRequest replaces the gateway keys of a payment method; a key left empty is kept
+type UpdatePaymentMethodSecretsRequest struct {
+	SKey      string `json:"s_key" validate:"required_without=MKey"`
+	MKey      string `json:"m_key" validate:"required_without=SKey"`
+	UpdatedBy string `json:"updated_by"`
+}
+
+// RotatePaymentMethodSecretsResponse reports how many payment methods were re-encrypted with the active key
+type RotatePaymentMethodSecretsResponse struct {
+	Rotated int `json:"rotated"`
 }
@@ -3,10 +3,18 @@ package entities
 type (
 	APIResponse struct {
 		Status  int         `json:"status"`
+		Code    string      `json:"code,omitempty"`
 		Message string      `json:"message"`
 		Result  interface{} `json:"result,omitempty"`
 		Error   string      `json:"error,omitempty"`
 	}
+	// FieldError explains why one request field failed validation, in Indonesian and English
+	FieldError struct {
+		Field     string `json:"field"`
+		Rule      string `json:"rule"`
+		MessageID string `json:"message_id"`
+		MessageEN string `json:"message_en"`
+	}
 	ResponseGlobal struct {
 		ResponseCode    string      `json:"responseCode"`
 		ResponseMessage string      `json:"responseMessage"`
@@ -4,6 +4,12 @@ import (
 	"time"
 )
 
+// Units of paket_layanan.durasi_pengerjaan
+const (
+	DurationUnitHour = "jam"
+	DurationUnitDay  = "hari"
+)
+
 type Service struct {
 	BrandID     int       `json:"brand_id" `
 	ID          int       `json:"id"`
@@ -28,30 +34,30 @@ type ServiceCategory struct {
 }
 
 type CreateServiceRequest struct {
-	BrandID     int     `json:"brand_id" validare:"required"`
-	CategoryID  int     `json:"kategori_id" validare:"required"`
-	Name        string  `json:"nama_layanan" validare:"required"`
+	BrandID     int     `json:"brand_id" validate:"required,gt=0"`
+	CategoryID  int     `json:"kategori_id" validate:"required,gt=0"`
+	Name        string  `json:"nama_layanan" validate:"required,max=100"`
 	Description string  `json:"deskripsi"`
-	Price       float64 `json:"harga_satuan" validare:"required"`
-	Unit        string  `json:"satuan_durasi" validare:"required"`
-	Estimation  int     `json:"durasi_pengerjaan"`
+	Price       float64 `json:"harga_satuan" validate:"required,gt=0"`
+	Unit        string  `json:"satuan_durasi" validate:"required,oneof=jam hari"`
+	Estimation  int     `json:"durasi_pengerjaan" validate:"gte=0"`
 }
 
 type UpdateServiceRequest struct {
-	CategoryID  int     `json:"kategori_id" validare:"required"`
-	Name        string  `json:"nama_layanan" validare:"required"`
+	CategoryID  int     `json:"kategori_id" validate:"required,gt=0"`
+	Name        string  `json:"nama_layanan" validate:"required,max=100"`
 	Description string  `json:"deskripsi"`
-	Price       float64 `json:"harga_satuan" validare:"required"`
-	Unit        string  `json:"satuan_durasi" validare:"required"`
-	Estimation  int     `json:"durasi_pengerjaan"`
+	Price       float64 `json:"harga_satuan" validate:"required,gt=0"`
+	Unit        string  `json:"satuan_durasi" validate:"required,oneof=jam hari"`
+	Estimation  int     `json:"durasi_pengerjaan" validate:"gte=0"`
 }
 
 type CreateServiceCategoryRequest struct {
-	Name        string `json:"nama_kategori" validare:"required"`
+	Name        string `json:"nama_kategori" validate:"required,max=100"`
 	Description string `json:"deskripsi"`
 }
 
 type UpdateServiceCategoryRequest struct {
-	Name        string `json:"nama_kategori" validare:"required"`
+	Name        string `json:"nama_kategori" validate:"required,max=100"`
 	Description string `json:"deskripsi"`
 }
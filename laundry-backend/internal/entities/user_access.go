@@ -8,35 +8,76 @@ type UserAccess struct {
 	ID             int        `json:"id"`
 	Username       string     `json:"username"`
 	Password       string     `json:"password,omitempty"` // Omit when marshaling to JSON
-	Role           string     `json:"role"`               //staft, cashier,warehouse, manager and owner
+	Role           string     `json:"role"`               //staff, cashier,warehouse, manager and owner
 	IsActive       bool       `json:"is_active"`
 	LastLogin      *time.Time `json:"last_login,omitempty"`
-	ReferenceLevel string     `json:"reference_level"` //pegawai, outlet or cabang
+	ReferenceLevel string     `json:"reference_level"` //karyawan, outlet, cabang or brand
 	ReferenceID    int        `json:"reference_id"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
+	// FailedLoginAttempts counts wrong passwords since the last successful login or lockout
+	FailedLoginAttempts int        `json:"failed_login_attempts"`
+	LockedUntil         *time.Time `json:"locked_until,omitempty"`
+	// MustChangePassword restricts the account to the change-password endpoint until the password is updated
+	MustChangePassword bool      `json:"must_change_password"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
+// Roles a user_access may hold
+const (
+	RoleStaff     = "staff"
+	RoleCashier   = "cashier"
+	RoleWarehouse = "warehouse"
+	RoleManager   = "manager"
+	RoleOwner     = "owner"
+	// RoleKaryawan is the user_access.role column default and is treated like RoleStaff
+	RoleKaryawan = "karyawan"
+	// RoleAdmin is the role of accounts in the users table
+	RoleAdmin = "admin"
+)
+
+// Hierarchy levels a user_access can be attached to through reference_level
+const (
+	ReferenceLevelBrand  = "brand"
+	ReferenceLevelCabang = "cabang"
+	ReferenceLevelOutlet = "outlet"
+	// ReferenceLevelKaryawan links the user to a pegawai; it is also assumed when reference_level is empty
+	ReferenceLevelKaryawan = "karyawan"
+)
+
+// AccessScope is the part of the brand -> cabang -> outlet hierarchy a caller may see,
+// resolved from the reference_level/reference_id claims of their JWT.
+// The zero value matches nothing.
+type AccessScope struct {
+	// Global is only set for administrator accounts, which see every brand
+	Global      bool
+	Level       string
+	ReferenceID int
+	// Role is the caller's own role; an account cannot be given a role above it
+	Role string
+}
+
+// CreateUserAccessRequest creates a user access; Password must satisfy the password policy
 type CreateUserAccessRequest struct {
-	Username       string `json:"username" validate:"required"`
-	Password       string `json:"password" validate:"required,min=6"`
-	Role           string `json:"role" validate:"required"`
-	IsActive       bool   `json:"is_active"`
-	ReferenceLevel string `json:"reference_level"`
-	ReferenceID    int    `json:"reference_id"`
+	Username           string `json:"username" validate:"required,max=50"`
+	Password           string `json:"password" validate:"required"`
+	Role               string `json:"role" validate:"required,oneof=staff cashier warehouse manager owner karyawan"`
+	IsActive           bool   `json:"is_active"`
+	ReferenceLevel     string `json:"reference_level" validate:"omitempty,oneof=brand cabang outlet karyawan"`
+	ReferenceID        int    `json:"reference_id" validate:"gte=0"`
+	MustChangePassword bool   `json:"must_change_password"`
 }
 
 type UpdateUserAccessRequest struct {
-	Username       string `json:"username" validate:"required"`
-	Role           string `json:"role" validate:"required"`
+	Username       string `json:"username" validate:"required,max=50"`
+	Role           string `json:"role" validate:"required,oneof=staff cashier warehouse manager owner karyawan"`
 	IsActive       bool   `json:"is_active"`
-	ReferenceLevel string `json:"reference_level"`
-	ReferenceID    int    `json:"reference_id"`
+	ReferenceLevel string `json:"reference_level" validate:"omitempty,oneof=brand cabang outlet karyawan"`
+	ReferenceID    int    `json:"reference_id" validate:"gte=0"`
 }
 
 type UpdateUserPasswordRequest struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
-	NewPassword     string `json:"new_password" validate:"required,min=6"`
+	NewPassword     string `json:"new_password" validate:"required"`
 }
 
 type UserLoginRequest struct {
@@ -49,3 +90,44 @@ type UserLoginResponse struct {
 	RefreshToken string     `json:"refresh_token,omitempty"`
 	User         UserAccess `json:"user"`
 }
+
+// RefreshToken is a login session of a user_access; only the SHA-256 hash of the token is stored.
+// A refresh token is single use: refreshing revokes it and points ReplacedBy at its successor.
+type RefreshToken struct {
+	ID         int        `json:"id"`
+	AccessID   int        `json:"id_access"`
+	TokenHash  string     `json:"-"`
+	ExpiredAt  time.Time  `json:"expired_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy *int       `json:"replaced_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type RevokeSessionsResponse struct {
+	RevokedSessions int `json:"revoked_sessions"`
+}
+
+// PasswordResetToken is a one-time token an admin issues so a user can set a new password; only its SHA-256 hash is stored
+type PasswordResetToken struct {
+	ID        int        `json:"id"`
+	AccessID  int        `json:"id_access"`
+	TokenHash string     `json:"-"`
+	ExpiredAt time.Time  `json:"expired_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// PasswordResetResponse carries the reset token to the admin, who hands it to the user
+type PasswordResetResponse struct {
+	ResetToken string    `json:"reset_token"`
+	ExpiredAt  time.Time `json:"expired_at"`
+}
+
+type ResetPasswordRequest struct {
+	ResetToken  string `json:"reset_token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
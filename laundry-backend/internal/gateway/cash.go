@@ -0,0 +1,31 @@
+package gateway
+
+import "laundry-backend/internal/entities"
+
+type cashGateway struct{}
+
+// NewCashGateway returns the adapter for money handled at the outlet counter; it never calls out
+func NewCashGateway() PaymentGateway {
+	return &cashGateway{}
+}
+
+// CreateCharge returns a pending charge without a reference: cash is collected in person, so there is nothing to reconcile
+func (g *cashGateway) CreateCharge(request ChargeRequest) (*entities.PaymentCharge, error) {
+	return &entities.PaymentCharge{
+		Amount: request.Amount,
+		Status: entities.PaymentStatusPending,
+	}, nil
+}
+
+// QueryStatus returns ErrStatusUnavailable: only the cashier knows whether cash was handed over
+func (g *cashGateway) QueryStatus(referenceNo string) (*entities.PaymentCharge, error) {
+	return nil, ErrStatusUnavailable
+}
+
+func (g *cashGateway) Refund(request RefundRequest) (*entities.PaymentRefund, error) {
+	return &entities.PaymentRefund{
+		ReferenceNo: request.ReferenceNo,
+		Amount:      request.Amount,
+		Status:      entities.PaymentStatusSuccess,
+	}, nil
+}
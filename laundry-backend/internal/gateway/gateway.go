@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"errors"
+	"laundry-backend/internal/entities"
+	"net/http"
+	"time"
+)
+
+// ErrStatusUnavailable is returned by QueryStatus of an adapter that has nobody to ask, such as cash
+var ErrStatusUnavailable = errors.New("payment status is not available from this gateway")
+
+// PaymentGateway is the adapter a payment method uses to collect and return money
+type PaymentGateway interface {
+	CreateCharge(request ChargeRequest) (*entities.PaymentCharge, error)
+	QueryStatus(referenceNo string) (*entities.PaymentCharge, error)
+	Refund(request RefundRequest) (*entities.PaymentRefund, error)
+}
+
+// ChargeRequest asks a gateway to collect Amount for one transaksi
+type ChargeRequest struct {
+	ReferenceNo string  `json:"reference_no"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+}
+
+// RefundRequest asks a gateway to return Amount of a charge identified by ReferenceNo
+type RefundRequest struct {
+	ReferenceNo string  `json:"reference_no"`
+	Amount      float64 `json:"amount"`
+	Reason      string  `json:"reason"`
+}
+
+// Resolver selects the gateway adapter of a payment method
+type Resolver interface {
+	Resolve(paymentMethod *entities.PaymentMethod) PaymentGateway
+}
+
+type resolver struct {
+	client *http.Client
+}
+
+// NewResolver returns a Resolver whose HTTP adapters share client; a nil client gets a 30 second timeout
+func NewResolver(client *http.Client) Resolver {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &resolver{client: client}
+}
+
+// Resolve returns the cash adapter for payment methods without a gateway URL, the HTTP adapter otherwise
+func (r *resolver) Resolve(paymentMethod *entities.PaymentMethod) PaymentGateway {
+	if paymentMethod.URL == "" {
+		return NewCashGateway()
+	}
+	return NewHTTPGateway(paymentMethod.URL, paymentMethod.MKey, paymentMethod.SKey, r.client)
+}
@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/utils"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// httpGateway talks to a QRIS/e-wallet style gateway over JSON:
+//
+//	POST {url}/charges                  create a charge
+//	GET  {url}/charges/{reference}      query its status
+//	POST {url}/charges/{reference}/refund
+//
+// Every request carries the merchant key in X-Merchant-Key and the HMAC-SHA256 of the body
+// keyed with the secret key in X-Signature, the same scheme the gateway uses for callbacks.
+type httpGateway struct {
+	baseURL     string
+	merchantKey string
+	secretKey   string
+	client      *http.Client
+}
+
+// chargeResponse is the gateway's view of a charge
+type chargeResponse struct {
+	ReferenceNo string     `json:"reference_no"`
+	Amount      float64    `json:"amount"`
+	Status      string     `json:"status"`
+	PaymentURL  string     `json:"payment_url"`
+	QRString    string     `json:"qr_string"`
+	ExpiredAt   *time.Time `json:"expired_at"`
+}
+
+// refundResponse is the gateway's view of a refund
+type refundResponse struct {
+	ReferenceNo string  `json:"reference_no"`
+	Amount      float64 `json:"amount"`
+	Status      string  `json:"status"`
+}
+
+func NewHTTPGateway(baseURL, merchantKey, secretKey string, client *http.Client) PaymentGateway {
+	return &httpGateway{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		merchantKey: merchantKey,
+		secretKey:   secretKey,
+		client:      client,
+	}
+}
+
+func (g *httpGateway) CreateCharge(request ChargeRequest) (*entities.PaymentCharge, error) {
+	var response chargeResponse
+	if err := g.do(http.MethodPost, "/charges", request, &response); err != nil {
+		return nil, err
+	}
+	return response.toCharge(), nil
+}
+
+func (g *httpGateway) QueryStatus(referenceNo string) (*entities.PaymentCharge, error) {
+	var response chargeResponse
+	if err := g.do(http.MethodGet, "/charges/"+url.PathEscape(referenceNo), nil, &response); err != nil {
+		return nil, err
+	}
+	return response.toCharge(), nil
+}
+
+func (g *httpGateway) Refund(request RefundRequest) (*entities.PaymentRefund, error) {
+	var response refundResponse
+	if err := g.do(http.MethodPost, "/charges/"+url.PathEscape(request.ReferenceNo)+"/refund", request, &response); err != nil {
+		return nil, err
+	}
+	return &entities.PaymentRefund{
+		ReferenceNo: response.ReferenceNo,
+		Amount:      response.Amount,
+		Status:      normalizeStatus(response.Status),
+	}, nil
+}
+
+// do sends a signed JSON request and decodes a 2xx JSON response into out
+func (g *httpGateway) do(method, path string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, g.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Merchant-Key", g.merchantKey)
+	req.Header.Set("X-Signature", utils.SignPayload(g.secretKey, body))
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("payment gateway request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read payment gateway response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("payment gateway responded %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("invalid payment gateway response: %w", err)
+	}
+	return nil
+}
+
+func (r chargeResponse) toCharge() *entities.PaymentCharge {
+	return &entities.PaymentCharge{
+		ReferenceNo: r.ReferenceNo,
+		Amount:      r.Amount,
+		Status:      normalizeStatus(r.Status),
+		PaymentURL:  r.PaymentURL,
+		QRString:    r.QRString,
+		ExpiredAt:   r.ExpiredAt,
+	}
+}
+
+// normalizeStatus maps the gateway's status vocabulary onto status_pembayaran
+func normalizeStatus(status string) string {
+	switch strings.ToLower(status) {
+	case "paid", "success", "settlement", "succeeded", "refunded", entities.PaymentStatusSuccess:
+		return entities.PaymentStatusSuccess
+	case "failed", "expired", "cancelled", "canceled", "deny", entities.PaymentStatusFailed:
+		return entities.PaymentStatusFailed
+	}
+	return entities.PaymentStatusPending
+}
@@ -0,0 +1,175 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	testMerchantKey = "merchant-key"
+	testSecretKey   = "secret-key"
+)
+
+// gatewayRequest is what the test server saw of one request
+type gatewayRequest struct {
+	method    string
+	path      string
+	body      []byte
+	merchant  string
+	signature string
+}
+
+// newTestGateway starts a server that records every request and answers with status and body
+func newTestGateway(t *testing.T, status int, body string) (PaymentGateway, *[]gatewayRequest) {
+	t.Helper()
+	var requests []gatewayRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read request body: %v", err)
+		}
+		requests = append(requests, gatewayRequest{
+			method:    r.Method,
+			path:      r.URL.EscapedPath(),
+			body:      payload,
+			merchant:  r.Header.Get("X-Merchant-Key"),
+			signature: r.Header.Get("X-Signature"),
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return NewHTTPGateway(server.URL+"/", testMerchantKey, testSecretKey, server.Client()), &requests
+}
+
+// checkSigned fails unless the request carries the merchant key and the HMAC of its body
+func checkSigned(t *testing.T, request gatewayRequest) {
+	t.Helper()
+	if request.merchant != testMerchantKey {
+		t.Errorf("X-Merchant-Key = %q, want %q", request.merchant, testMerchantKey)
+	}
+	if !utils.VerifyPayloadSignature(testSecretKey, request.body, request.signature) {
+		t.Errorf("X-Signature %q does not sign body %q", request.signature, request.body)
+	}
+}
+
+func TestHTTPGatewayCreateCharge(t *testing.T) {
+	gw, requests := newTestGateway(t, http.StatusCreated,
+		`{"reference_no":"GW-1","amount":45000,"status":"PENDING","payment_url":"https://pay.example/GW-1","qr_string":"000201"}`)
+
+	charge, err := gw.CreateCharge(ChargeRequest{ReferenceNo: "INV-1", Amount: 45000, Description: "Pembayaran INV-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if charge.ReferenceNo != "GW-1" || charge.Amount != 45000 || charge.Status != entities.PaymentStatusPending ||
+		charge.PaymentURL != "https://pay.example/GW-1" || charge.QRString != "000201" {
+		t.Errorf("charge = %+v", charge)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("sent %d requests, want 1", len(*requests))
+	}
+	request := (*requests)[0]
+	if request.method != http.MethodPost || request.path != "/charges" {
+		t.Errorf("request = %s %s, want POST /charges", request.method, request.path)
+	}
+	var sent ChargeRequest
+	if err := json.Unmarshal(request.body, &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent.ReferenceNo != "INV-1" || sent.Amount != 45000 {
+		t.Errorf("sent charge = %+v", sent)
+	}
+	checkSigned(t, request)
+}
+
+func TestHTTPGatewayQueryStatus(t *testing.T) {
+	gw, requests := newTestGateway(t, http.StatusOK, `{"reference_no":"GW 1/2","amount":45000,"status":"settlement"}`)
+
+	charge, err := gw.QueryStatus("GW 1/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if charge.Status != entities.PaymentStatusSuccess {
+		t.Errorf("status = %q, want %q", charge.Status, entities.PaymentStatusSuccess)
+	}
+
+	request := (*requests)[0]
+	if request.method != http.MethodGet || request.path != "/charges/GW%201%2F2" {
+		t.Errorf("request = %s %s, want GET /charges/GW%%201%%2F2", request.method, request.path)
+	}
+	checkSigned(t, request)
+}
+
+func TestHTTPGatewayRefund(t *testing.T) {
+	gw, requests := newTestGateway(t, http.StatusOK, `{"reference_no":"RF-1","amount":10000,"status":"refunded"}`)
+
+	refund, err := gw.Refund(RefundRequest{ReferenceNo: "GW-1", Amount: 10000, Reason: "Batal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.ReferenceNo != "RF-1" || refund.Amount != 10000 || refund.Status != entities.PaymentStatusSuccess {
+		t.Errorf("refund = %+v", refund)
+	}
+
+	request := (*requests)[0]
+	if request.method != http.MethodPost || request.path != "/charges/GW-1/refund" {
+		t.Errorf("request = %s %s, want POST /charges/GW-1/refund", request.method, request.path)
+	}
+	var sent RefundRequest
+	if err := json.Unmarshal(request.body, &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Amount != 10000 || sent.Reason != "Batal" {
+		t.Errorf("sent refund = %+v", sent)
+	}
+	checkSigned(t, request)
+}
+
+func TestHTTPGatewayErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "non-2xx response", status: http.StatusBadGateway, body: `{"message":"upstream down"}`, wantErr: "responded 502"},
+		{name: "client error", status: http.StatusUnauthorized, body: `invalid signature`, wantErr: "responded 401: invalid signature"},
+		{name: "malformed JSON", status: http.StatusOK, body: `{"reference_no":`, wantErr: "invalid payment gateway response"},
+	}
+
+	calls := map[string]func(PaymentGateway) error{
+		"CreateCharge": func(gw PaymentGateway) error {
+			_, err := gw.CreateCharge(ChargeRequest{ReferenceNo: "INV-1", Amount: 1000})
+			return err
+		},
+		"QueryStatus": func(gw PaymentGateway) error {
+			_, err := gw.QueryStatus("GW-1")
+			return err
+		},
+		"Refund": func(gw PaymentGateway) error {
+			_, err := gw.Refund(RefundRequest{ReferenceNo: "GW-1", Amount: 1000})
+			return err
+		},
+	}
+
+	for _, tt := range tests {
+		for name, call := range calls {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				gw, _ := newTestGateway(t, tt.status, tt.body)
+				err := call(gw)
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+			})
+		}
+	}
+}
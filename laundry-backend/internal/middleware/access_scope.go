@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"laundry-backend/internal/entities"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AccessScope returns the hierarchy scope of the caller from the reference_level/reference_id claims of their JWT.
+// Administrator accounts of the users table are global; any other level is treated as karyawan, as it is at login.
+func AccessScope(c echo.Context) entities.AccessScope {
+	claims := tokenClaims(c)
+	role, _ := claims["role"].(string)
+	level, _ := claims["reference_level"].(string)
+	referenceID, _ := claims["reference_id"].(float64)
+
+	if role == entities.RoleAdmin && level == "" {
+		return entities.AccessScope{Global: true}
+	}
+
+	switch level {
+	case entities.ReferenceLevelBrand, entities.ReferenceLevelCabang, entities.ReferenceLevelOutlet:
+	default:
+		level = entities.ReferenceLevelKaryawan
+	}
+
+	return entities.AccessScope{
+		Level:       level,
+		ReferenceID: int(referenceID),
+		Role:        role,
+	}
+}
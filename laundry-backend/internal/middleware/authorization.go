@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"fmt"
+	"laundry-backend/constant"
+	"laundry-backend/internal/entities"
+	"net/http"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+// Resources protected by Authorize
+const (
+	ResourceBrand           = "brand"
+	ResourceCabang          = "cabang"
+	ResourceOutlet          = "outlet"
+	ResourceInquiry         = "inquiry"
+	ResourceEmployee        = "pegawai"
+	ResourceCustomer        = "pelanggan"
+	ResourceService         = "service"
+	ResourceServiceCategory = "service_category"
+	ResourceUserAccess      = "user_access"
+	ResourceTransaction     = "transaction"
+	ResourcePayment         = "payment"
+	ResourcePaymentMethod   = "payment_method"
+	ResourceReport          = "report"
+	ResourceHoliday         = "hari_libur"
+	ResourceModifier        = "modifier"
+)
+
+// Actions a role may be granted on a resource
+const (
+	ActionRead   = "read"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	// ActionCancel cancels a transaksi and refunds its payments
+	ActionCancel = "cancel"
+	// ActionManageSecrets replaces or rotates payment method keys
+	ActionManageSecrets = "manage_secrets"
+	// ActionRevokeSessions signs a user_access out of every device
+	ActionRevokeSessions = "revoke_sessions"
+	// ActionUnlock lifts the lockout of a user_access after too many wrong passwords
+	ActionUnlock = "unlock"
+	// ActionResetPassword issues a one-time password reset token for a user_access
+	ActionResetPassword = "reset_password"
+)
+
+// allActions grants every action on a resource
+const allActions = "*"
+
+var (
+	readOnly  = []string{ActionRead}
+	readWrite = []string{ActionRead, ActionCreate, ActionUpdate}
+	fullCRUD  = []string{ActionRead, ActionCreate, ActionUpdate, ActionDelete}
+
+	staffPermissions = map[string][]string{
+		ResourceOutlet:          readOnly,
+		ResourceInquiry:         {ActionCreate},
+		ResourceCustomer:        {ActionRead, ActionCreate},
+		ResourceService:         readOnly,
+		ResourceServiceCategory: readOnly,
+		ResourceModifier:        readOnly,
+		ResourceTransaction:     {ActionRead, ActionUpdate},
+		ResourcePayment:         readOnly,
+		ResourcePaymentMethod:   readOnly,
+	}
+)
+
+// rolePermissions maps role -> resource -> allowed actions; roles not listed are denied everything
+var rolePermissions = map[string]map[string][]string{
+	entities.RoleOwner: {
+		allActions: {allActions},
+	},
+	entities.RoleAdmin: {
+		allActions: {allActions},
+	},
+	entities.RoleManager: {
+		ResourceOutlet:          {ActionRead, ActionUpdate},
+		ResourceInquiry:         {ActionCreate},
+		ResourceEmployee:        fullCRUD,
+		ResourceCustomer:        fullCRUD,
+		ResourceService:         fullCRUD,
+		ResourceServiceCategory: fullCRUD,
+		ResourceModifier:        fullCRUD,
+		ResourceUserAccess:      readWrite,
+		ResourceTransaction:     {ActionRead, ActionUpdate, ActionCancel},
+		ResourcePayment:         readWrite,
+		ResourcePaymentMethod:   readOnly,
+		ResourceReport:          readOnly,
+		ResourceHoliday:         {ActionCreate, ActionDelete},
+	},
+	entities.RoleCashier: {
+		ResourceOutlet:          readOnly,
+		ResourceInquiry:         {ActionCreate},
+		ResourceCustomer:        readWrite,
+		ResourceService:         readOnly,
+		ResourceServiceCategory: readOnly,
+		ResourceModifier:        readOnly,
+		ResourceTransaction:     {ActionRead, ActionUpdate},
+		ResourcePayment:         readWrite,
+		ResourcePaymentMethod:   readOnly,
+	},
+	entities.RoleWarehouse: {
+		ResourceOutlet:      readOnly,
+		ResourceCustomer:    readOnly,
+		ResourceService:     readOnly,
+		ResourceTransaction: {ActionRead, ActionUpdate},
+	},
+	entities.RoleStaff:    staffPermissions,
+	entities.RoleKaryawan: staffPermissions,
+}
+
+// IsAllowed reports whether role may perform action on resource
+func IsAllowed(role, resource, action string) bool {
+	permissions, ok := rolePermissions[role]
+	if !ok {
+		return false
+	}
+	for _, key := range []string{resource, allActions} {
+		for _, allowed := range permissions[key] {
+			if allowed == action || allowed == allActions {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authorize only lets the request through when the role claim of the JWT may perform action on resource.
+// It must run after echo's JWT middleware, which stores the token under "user".
+func Authorize(resource, action string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims := tokenClaims(c)
+			role, _ := claims["role"].(string)
+
+			if !IsAllowed(role, resource, action) {
+				return forbidden(c, role, resource, action)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// AuthorizeSelf is Authorize, but also lets a staff user act on their own user_access row identified by the route param idParam
+func AuthorizeSelf(idParam, resource, action string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims := tokenClaims(c)
+			role, _ := claims["role"].(string)
+			userID, isStaff := StaffUserID(c)
+
+			if (!isStaff || fmt.Sprint(userID) != c.Param(idParam)) && !IsAllowed(role, resource, action) {
+				return forbidden(c, role, resource, action)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// tokenClaims returns the claims of the token stored by echo's JWT middleware, or empty claims
+func tokenClaims(c echo.Context) jwt.MapClaims {
+	if user, ok := c.Get("user").(*jwt.Token); ok {
+		if claims, ok := user.Claims.(jwt.MapClaims); ok {
+			return claims
+		}
+	}
+	return jwt.MapClaims{}
+}
+
+func forbidden(c echo.Context, role, resource, action string) error {
+	return c.JSON(http.StatusForbidden, entities.APIResponse{
+		Status:  http.StatusForbidden,
+		Code:    constant.ForbiddenCode,
+		Message: "Forbidden",
+		Error:   fmt.Sprintf("role %q is not allowed to %s %s", role, action, resource),
+	})
+}
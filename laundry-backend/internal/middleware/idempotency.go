@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"laundry-backend/internal/apperror"
+	"laundry-backend/internal/usecases"
+	"laundry-backend/internal/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// IdempotencyKeyHeader lets a client retry a request without running it twice
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from an earlier request with the same key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency honours the Idempotency-Key header: the first response for a key is stored with a hash of the request,
+// a retry with the same key and body gets that response again, and the same key with another body is refused with 422.
+// Requests without the header are passed through. Keys are scoped to the token owner,
+// so it must run after echo's JWT middleware, which stores the token under "user".
+func Idempotency(idempotency usecases.IdempotencyUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return apperror.Validation(fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return apperror.Validation("failed to read request body")
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			claims := tokenClaims(c)
+			owner := fmt.Sprintf("%v:%v", claims["aud"], claims["user_id"])
+			requestHash := utils.HashToken(c.Request().Method + " " + c.Request().URL.Path + "\n" + string(body))
+
+			record, err := idempotency.Begin(owner, key, requestHash)
+			if err != nil {
+				return err
+			}
+			if record.CompletedAt != nil {
+				c.Response().Header().Set(IdempotentReplayedHeader, "true")
+				return c.JSONBlob(record.StatusCode, []byte(record.ResponseBody))
+			}
+
+			// a panicking handler is answered with 500 by echo's Recover middleware, so its key is released as well
+			defer func() {
+				if r := recover(); r != nil {
+					if err := idempotency.Release(record); err != nil {
+						utils.LoggMsg("Idempotency", "Failed to release idempotency key", err)
+					}
+					panic(r)
+				}
+			}()
+
+			recorder := &bodyRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			// write errors here rather than in echo's error handler so the error response is recorded too
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				if err := idempotency.Release(record); err != nil {
+					utils.LoggMsg("Idempotency", "Failed to release idempotency key", err)
+				}
+				return nil
+			}
+			if err := idempotency.Complete(record, status, recorder.body.String()); err != nil {
+				utils.LoggMsg("Idempotency", "Failed to store idempotent response", err)
+				// without a stored response the key would stay in flight and every retry would get 409 until it expires;
+				// releasing it lets a retry run again
+				if err := idempotency.Release(record); err != nil {
+					utils.LoggMsg("Idempotency", "Failed to release idempotency key", err)
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// bodyRecorder copies the response body while it is written to the client
+type bodyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
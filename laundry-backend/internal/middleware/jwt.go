@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"laundry-backend/internal/utils"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// JWT validates the bearer token with the token service and stores it under "user",
+// in the same shape echo's JWT middleware does, for Authorize, AccessScope and the handlers.
+func JWT(tokens *utils.TokenService) echo.MiddlewareFunc {
+	return echoMiddleware.JWTWithConfig(echoMiddleware.JWTConfig{
+		ParseTokenFunc: func(auth string, c echo.Context) (interface{}, error) {
+			claims, err := tokens.Parse(auth)
+			if err != nil {
+				return nil, err
+			}
+
+			return &jwt.Token{
+				Raw:    auth,
+				Method: jwt.SigningMethodHS256,
+				Claims: jwt.MapClaims(claims),
+				Valid:  true,
+			}, nil
+		},
+	})
+}
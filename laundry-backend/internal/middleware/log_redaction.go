@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// redactedValue replaces a secret in the request and response bodies written to the log
+const redactedValue = "[REDACTED]"
+
+// sensitiveLogFields are the body fields never written to the log: passwords, payment gateway keys
+// and the access, refresh and password reset tokens
+var sensitiveLogFields = map[string]bool{
+	"password":         true,
+	"current_password": true,
+	"new_password":     true,
+	"s_key":            true,
+	"m_key":            true,
+	"skey":             true,
+	"mkey":             true,
+	"access_token":     true,
+	"refresh_token":    true,
+	"reset_token":      true,
+}
+
+// redactBody returns body with the value of every sensitive field replaced, at any depth of a JSON body
+// or in a form body. A body that is neither is returned unchanged.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err == nil {
+		redacted, err := json.Marshal(redactJSON(data))
+		if err != nil {
+			return redactedValue
+		}
+		return string(redacted)
+	}
+
+	if form, err := url.ParseQuery(string(body)); err == nil && strings.Contains(string(body), "=") {
+		for key := range form {
+			if sensitiveLogFields[strings.ToLower(key)] {
+				form.Set(key, redactedValue)
+			}
+		}
+		return form.Encode()
+	}
+
+	return string(body)
+}
+
+// redactJSON replaces the sensitive fields of a decoded JSON value
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveLogFields[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import "testing"
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "payment method secrets",
+			body: `{"s_key":"secret","m_key":"merchant"}`,
+			want: `{"m_key":"[REDACTED]","s_key":"[REDACTED]"}`,
+		},
+		{
+			name: "nested tokens in a response",
+			body: `{"data":{"access_token":"a","refresh_token":"r","user":{"username":"kasir"}},"status":200}`,
+			want: `{"data":{"access_token":"[REDACTED]","refresh_token":"[REDACTED]","user":{"username":"kasir"}},"status":200}`,
+		},
+		{
+			name: "password change",
+			body: `{"current_password":"old","new_password":"new"}`,
+			want: `{"current_password":"[REDACTED]","new_password":"[REDACTED]"}`,
+		},
+		{
+			name: "form body",
+			body: `username=kasir&password=rahasia`,
+			want: `password=%5BREDACTED%5D&username=kasir`,
+		},
+		{name: "no secrets", body: `{"id_outlet":2}`, want: `{"id_outlet":2}`},
+		{name: "empty", body: ``, want: ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactBody([]byte(tt.body)); got != tt.want {
+				t.Errorf("redactBody(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
@@ -56,8 +56,8 @@ func (m *LoggingMiddleware) LogRequestResponse(next echo.HandlerFunc) echo.Handl
 			"url":           c.Request().URL.String(),
 			"status":        c.Response().Status,
 			"duration_ms":   duration.Milliseconds(),
-			"request_body":  string(reqBody),
-			"response_body": resBody.String(),
+			"request_body":  redactBody(reqBody),
+			"response_body": redactBody(resBody.Bytes()),
 			"user_agent":    c.Request().UserAgent(),
 			"client_ip":     c.RealIP(),
 		}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"laundry-backend/constant"
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/usecases"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequirePasswordChanged refuses callers that must change their password on every route except allowedPaths,
+// the echo route paths a user may call to change their password. A staff token is checked against the stored
+// must_change_password flag, so access tokens issued before a password reset are restricted as well;
+// other tokens only carry the claim.
+// It must run after echo's JWT middleware, which stores the token under "user".
+func RequirePasswordChanged(userAccess usecases.UserAccessUsecase, allowedPaths ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			for _, path := range allowedPaths {
+				if c.Path() == path {
+					return next(c)
+				}
+			}
+
+			claims := tokenClaims(c)
+			mustChange, _ := claims["must_change_password"].(bool)
+			if userID, isStaff := StaffUserID(c); isStaff {
+				var err error
+				if mustChange, err = userAccess.MustChangePassword(userID); err != nil {
+					return err
+				}
+			}
+			if !mustChange {
+				return next(c)
+			}
+
+			return c.JSON(http.StatusForbidden, entities.APIResponse{
+				Status:  http.StatusForbidden,
+				Code:    constant.ForbiddenCode,
+				Message: "Forbidden",
+				Error:   "password must be changed before using this endpoint",
+			})
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"fmt"
+	"laundry-backend/internal/entities"
+)
+
+// outletScopeCondition returns a condition limiting outletColumn to the outlets inside scope.
+// The reference id is bound to $argIndex and returned as the condition's args; a global scope needs none.
+func outletScopeCondition(scope entities.AccessScope, outletColumn string, argIndex int) (string, []interface{}) {
+	if scope.Global {
+		return "TRUE", nil
+	}
+
+	placeholder := fmt.Sprintf("$%d", argIndex)
+	switch scope.Level {
+	case entities.ReferenceLevelBrand:
+		return outletColumn + ` IN (SELECT o.id_outlet FROM outlet o
+			JOIN cabang c ON c.id_cabang = o.id_cabang WHERE c.id_brand = ` + placeholder + `)`, []interface{}{scope.ReferenceID}
+	case entities.ReferenceLevelCabang:
+		return outletColumn + ` IN (SELECT id_outlet FROM outlet WHERE id_cabang = ` + placeholder + `)`, []interface{}{scope.ReferenceID}
+	case entities.ReferenceLevelOutlet:
+		return outletColumn + ` = ` + placeholder, []interface{}{scope.ReferenceID}
+	case entities.ReferenceLevelKaryawan:
+		return outletColumn + ` IN (SELECT id_outlet FROM pegawai WHERE id_pegawai = ` + placeholder + `)`, []interface{}{scope.ReferenceID}
+	}
+
+	return "FALSE", nil
+}
+
+// userAccessScopeCondition is outletScopeCondition for user_access rows, by the brand, cabang, outlet or pegawai
+// they reference. Only a brand scope reaches the accounts of its own brand level.
+func userAccessScopeCondition(scope entities.AccessScope, argIndex int) (string, []interface{}) {
+	if scope.Global {
+		return "TRUE", nil
+	}
+
+	brandCond := "FALSE"
+	if scope.Level == entities.ReferenceLevelBrand {
+		brandCond = fmt.Sprintf("ua.reference_id = $%d", argIndex)
+	}
+	cabangCond, args := cabangScopeCondition(scope, "ua.reference_id", argIndex)
+	outletCond, _ := outletScopeCondition(scope, "ua.reference_id", argIndex)
+	employeeCond, _ := outletScopeCondition(scope, "pg.id_outlet", argIndex)
+	if args == nil {
+		// an unknown level matches nothing and binds no argument
+		return "FALSE", nil
+	}
+
+	return `(CASE ua.reference_level
+			WHEN 'brand' THEN ` + brandCond + `
+			WHEN 'cabang' THEN ` + cabangCond + `
+			WHEN 'outlet' THEN ` + outletCond + `
+			ELSE ua.reference_id IN (SELECT pg.id_pegawai FROM pegawai pg WHERE ` + employeeCond + `)
+		END)`, args
+}
+
+// cabangScopeCondition is outletScopeCondition for rows that belong to a cabang.
+// Outlet and karyawan scopes only reach the cabang of their own outlet.
+func cabangScopeCondition(scope entities.AccessScope, cabangColumn string, argIndex int) (string, []interface{}) {
+	if scope.Global {
+		return "TRUE", nil
+	}
+
+	placeholder := fmt.Sprintf("$%d", argIndex)
+	switch scope.Level {
+	case entities.ReferenceLevelBrand:
+		return cabangColumn + ` IN (SELECT id_cabang FROM cabang WHERE id_brand = ` + placeholder + `)`, []interface{}{scope.ReferenceID}
+	case entities.ReferenceLevelCabang:
+		return cabangColumn + ` = ` + placeholder, []interface{}{scope.ReferenceID}
+	case entities.ReferenceLevelOutlet:
+		return cabangColumn + ` IN (SELECT id_cabang FROM outlet WHERE id_outlet = ` + placeholder + `)`, []interface{}{scope.ReferenceID}
+	case entities.ReferenceLevelKaryawan:
+		return cabangColumn + ` IN (SELECT o.id_cabang FROM outlet o
+			JOIN pegawai pg ON pg.id_outlet = o.id_outlet WHERE pg.id_pegawai = ` + placeholder + `)`, []interface{}{scope.ReferenceID}
+	}
+
+	return "FALSE", nil
+}
+
+// brandScopeCondition is outletScopeCondition for rows that belong to a brand.
+// Cabang, outlet and karyawan scopes reach the brand their own row belongs to.
+func brandScopeCondition(scope entities.AccessScope, brandColumn string, argIndex int) (string, []interface{}) {
+	if scope.Global {
+		return "TRUE", nil
+	}
+
+	placeholder := fmt.Sprintf("$%d", argIndex)
+	switch scope.Level {
+	case entities.ReferenceLevelBrand:
+		return brandColumn + ` = ` + placeholder, []interface{}{scope.ReferenceID}
+	case entities.ReferenceLevelCabang:
+		return brandColumn + ` IN (SELECT id_brand FROM cabang WHERE id_cabang = ` + placeholder + `)`, []interface{}{scope.ReferenceID}
+	case entities.ReferenceLevelOutlet:
+		return brandColumn + ` IN (SELECT c.id_brand FROM cabang c
+			JOIN outlet o ON o.id_cabang = c.id_cabang WHERE o.id_outlet = ` + placeholder + `)`, []interface{}{scope.ReferenceID}
+	case entities.ReferenceLevelKaryawan:
+		return brandColumn + ` IN (SELECT c.id_brand FROM cabang c
+			JOIN outlet o ON o.id_cabang = c.id_cabang
+			JOIN pegawai pg ON pg.id_outlet = o.id_outlet WHERE pg.id_pegawai = ` + placeholder + `)`, []interface{}{scope.ReferenceID}
+	}
+
+	return "FALSE", nil
+}
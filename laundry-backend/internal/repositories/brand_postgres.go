@@ -16,13 +16,14 @@ func NewBrandRepository(db *sql.DB) BrandRepository {
 }
 
 func (r *brandPostgresRepository) Create(brand *entities.Brand) error {
-	query := `INSERT INTO brand (nama_brand, deskripsi, pic_nama, pic_email, pic_telepon, logo_url, created_at, updated_at) 
-	VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW()) RETURNING id_brand`
-	return r.db.QueryRow(query, brand.Name, brand.Description, brand.PICName, brand.PICEmail, brand.PICTelepon, brand.LogoURL).Scan(&brand.ID)
+	query := `INSERT INTO brand (nama_brand, deskripsi, pic_nama, pic_email, pic_telepon, logo_url, kode_brand, format_invoice, created_at, updated_at) 
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW()) RETURNING id_brand`
+	return r.db.QueryRow(query, brand.Name, brand.Description, brand.PICName, brand.PICEmail, brand.PICTelepon, brand.LogoURL,
+		brand.Code, brand.InvoiceFormat).Scan(&brand.ID)
 }
 
 func (r *brandPostgresRepository) FindByID(id int) (*entities.Brand, error) {
-	query := `SELECT id_brand, nama_brand, deskripsi, pic_nama, pic_email, pic_telepon, logo_url, created_at, updated_at 
+	query := `SELECT id_brand, nama_brand, deskripsi, pic_nama, pic_email, pic_telepon, logo_url, kode_brand, format_invoice, created_at, updated_at 
 	FROM brand WHERE id_brand = $1`
 	row := r.db.QueryRow(query, id)
 
@@ -35,6 +36,8 @@ func (r *brandPostgresRepository) FindByID(id int) (*entities.Brand, error) {
 		&brand.PICEmail,
 		&brand.PICTelepon,
 		&brand.LogoURL,
+		&brand.Code,
+		&brand.InvoiceFormat,
 		&brand.CreatedAt,
 		&brand.UpdatedAt,
 	)
@@ -49,7 +52,7 @@ func (r *brandPostgresRepository) FindByID(id int) (*entities.Brand, error) {
 }
 
 func (r *brandPostgresRepository) FindAll() ([]entities.Brand, error) {
-	query := `SELECT id_brand, nama_brand, deskripsi, pic_nama, pic_email, pic_telepon, logo_url, created_at, updated_at FROM brand`
+	query := `SELECT id_brand, nama_brand, deskripsi, pic_nama, pic_email, pic_telepon, logo_url, kode_brand, format_invoice, created_at, updated_at FROM brand`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -67,6 +70,8 @@ func (r *brandPostgresRepository) FindAll() ([]entities.Brand, error) {
 			&brand.PICEmail,
 			&brand.PICTelepon,
 			&brand.LogoURL,
+			&brand.Code,
+			&brand.InvoiceFormat,
 			&brand.CreatedAt,
 			&brand.UpdatedAt,
 		)
@@ -113,7 +118,7 @@ func (r *brandPostgresRepository) FindAllWithPagination(limit, offset int, searc
 	}
 	
 	// Build the query
-	baseQuery := `SELECT id_brand, nama_brand, deskripsi, pic_nama, pic_email, pic_telepon, logo_url, created_at, updated_at FROM brand`
+	baseQuery := `SELECT id_brand, nama_brand, deskripsi, pic_nama, pic_email, pic_telepon, logo_url, kode_brand, format_invoice, created_at, updated_at FROM brand`
 	countQuery := `SELECT COUNT(*) FROM brand`
 	
 	var args []interface{}
@@ -157,6 +162,8 @@ func (r *brandPostgresRepository) FindAllWithPagination(limit, offset int, searc
 			&brand.PICEmail,
 			&brand.PICTelepon,
 			&brand.LogoURL,
+			&brand.Code,
+			&brand.InvoiceFormat,
 			&brand.CreatedAt,
 			&brand.UpdatedAt,
 		)
@@ -187,10 +194,20 @@ func (r *brandPostgresRepository) FindAllWithPagination(limit, offset int, searc
 	return brands, recordsTotal, recordsFiltered, nil
 }
 
+func (r *brandPostgresRepository) ExistsInScope(id int, scope entities.AccessScope) (bool, error) {
+	scopeCond, scopeArgs := brandScopeCondition(scope, "id_brand", 2)
+	query := `SELECT EXISTS (SELECT 1 FROM brand WHERE id_brand = $1 AND ` + scopeCond + `)`
+
+	var exists bool
+	err := r.db.QueryRow(query, append([]interface{}{id}, scopeArgs...)...).Scan(&exists)
+	return exists, err
+}
+
 func (r *brandPostgresRepository) Update(brand *entities.Brand) error {
 	query := `UPDATE brand SET nama_brand = $1, deskripsi = $2, pic_nama = $3, pic_email = $4, pic_telepon = $5, 
-	logo_url = $6, updated_at = NOW() WHERE id_brand = $7`
-	_, err := r.db.Exec(query, brand.Name, brand.Description, brand.PICName, brand.PICEmail, brand.PICTelepon, brand.LogoURL, brand.ID)
+	logo_url = $6, kode_brand = $7, format_invoice = $8, updated_at = NOW() WHERE id_brand = $9`
+	_, err := r.db.Exec(query, brand.Name, brand.Description, brand.PICName, brand.PICEmail, brand.PICTelepon, brand.LogoURL,
+		brand.Code, brand.InvoiceFormat, brand.ID)
 	return err
 }
 
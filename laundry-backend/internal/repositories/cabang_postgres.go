@@ -277,3 +277,13 @@ func (r *cabangPostgresRepository) Delete(id int) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+// ExistsInScope reports whether the cabang exists inside the caller's part of the hierarchy
+func (r *cabangPostgresRepository) ExistsInScope(id int, scope entities.AccessScope) (bool, error) {
+	scopeCond, scopeArgs := cabangScopeCondition(scope, "id_cabang", 2)
+	query := `SELECT EXISTS (SELECT 1 FROM cabang WHERE id_cabang = $1 AND ` + scopeCond + `)`
+
+	var exists bool
+	err := r.db.QueryRow(query, append([]interface{}{id}, scopeArgs...)...).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"database/sql"
+	"laundry-backend/internal/entities"
+	"time"
+)
+
+type calendarPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarRepository(db *sql.DB) CalendarRepository {
+	return &calendarPostgresRepository{db: db}
+}
+
+// FindOpeningHours returns the weekdays of the outlet that have their own jam_operasional_outlet row
+func (r *calendarPostgresRepository) FindOpeningHours(outletID int) ([]entities.OpeningHours, error) {
+	query := `SELECT id_outlet, hari, jam_buka, jam_tutup, tutup
+		FROM jam_operasional_outlet WHERE id_outlet = $1 ORDER BY hari`
+	rows, err := r.db.Query(query, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hours []entities.OpeningHours
+	for rows.Next() {
+		var day entities.OpeningHours
+		var openTime, closeTime sql.NullString
+		if err := rows.Scan(&day.OutletID, &day.Weekday, &openTime, &closeTime, &day.Closed); err != nil {
+			return nil, err
+		}
+		day.OpenTime = openTime.String
+		day.CloseTime = closeTime.String
+		hours = append(hours, day)
+	}
+
+	return hours, rows.Err()
+}
+
+// ReplaceOpeningHours replaces every jam_operasional_outlet row of the outlet with hours
+func (r *calendarPostgresRepository) ReplaceOpeningHours(outletID int, hours []entities.OpeningHours) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM jam_operasional_outlet WHERE id_outlet = $1`, outletID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO jam_operasional_outlet (id_outlet, hari, jam_buka, jam_tutup, tutup, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())`
+	for _, day := range hours {
+		var openTime, closeTime interface{}
+		if !day.Closed {
+			openTime, closeTime = day.OpenTime, day.CloseTime
+		}
+		if _, err := tx.Exec(query, outletID, day.Weekday, openTime, closeTime, day.Closed); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// holidayColumns is the column list shared by every hari_libur SELECT, in scanHoliday order
+const holidayColumns = `h.id_libur, h.jenis, h.id_brand, h.id_outlet, h.tanggal, COALESCE(h.keterangan, ''), h.created_at, h.updated_at`
+
+// scanHoliday scans a row selected with holidayColumns
+func scanHoliday(row rowScanner) (*entities.Holiday, error) {
+	var holiday entities.Holiday
+	var brandID, outletID sql.NullInt64
+	var date time.Time
+
+	err := row.Scan(
+		&holiday.ID,
+		&holiday.Type,
+		&brandID,
+		&outletID,
+		&date,
+		&holiday.Name,
+		&holiday.CreatedAt,
+		&holiday.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if brandID.Valid {
+		id := int(brandID.Int64)
+		holiday.BrandID = &id
+	}
+	if outletID.Valid {
+		id := int(outletID.Int64)
+		holiday.OutletID = &id
+	}
+	holiday.Date = date.Format("2006-01-02")
+
+	return &holiday, nil
+}
+
+// FindHolidaysByOutlet returns the national holidays, the holidays of the outlet's brand and
+// the outlet's own holidays between from and to, inclusive
+func (r *calendarPostgresRepository) FindHolidaysByOutlet(outletID int, from, to time.Time) ([]entities.Holiday, error) {
+	query := `SELECT ` + holidayColumns + `
+		FROM hari_libur h
+		WHERE h.tanggal BETWEEN $2::date AND $3::date
+			AND (h.jenis = 'nasional'
+				OR h.id_outlet = $1
+				OR h.id_brand = (SELECT c.id_brand FROM outlet o
+					JOIN cabang c ON c.id_cabang = o.id_cabang WHERE o.id_outlet = $1))
+		ORDER BY h.tanggal, h.id_libur`
+	rows, err := r.db.Query(query, outletID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holidays []entities.Holiday
+	for rows.Next() {
+		holiday, err := scanHoliday(rows)
+		if err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, *holiday)
+	}
+
+	return holidays, rows.Err()
+}
+
+func (r *calendarPostgresRepository) FindHolidayByID(id int) (*entities.Holiday, error) {
+	query := `SELECT ` + holidayColumns + ` FROM hari_libur h WHERE h.id_libur = $1`
+	holiday, err := scanHoliday(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return holiday, nil
+}
+
+func (r *calendarPostgresRepository) CreateHoliday(holiday *entities.Holiday) error {
+	query := `INSERT INTO hari_libur (jenis, id_brand, id_outlet, tanggal, keterangan, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id_libur, created_at, updated_at`
+	return r.db.QueryRow(query, holiday.Type, holiday.BrandID, holiday.OutletID, holiday.Date, holiday.Name).
+		Scan(&holiday.ID, &holiday.CreatedAt, &holiday.UpdatedAt)
+}
+
+func (r *calendarPostgresRepository) DeleteHoliday(id int) error {
+	_, err := r.db.Exec(`DELETE FROM hari_libur WHERE id_libur = $1`, id)
+	return err
+}
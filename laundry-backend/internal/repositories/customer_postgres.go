@@ -75,13 +75,14 @@ func (r *customerPostgresRepository) FindByID(id int) (*entities.Customer, error
 	return customer, nil
 }
 
-func (r *customerPostgresRepository) FindByOutletID(outletID int) ([]entities.Customer, error) {
+func (r *customerPostgresRepository) FindByOutletID(outletID int, scope entities.AccessScope) ([]entities.Customer, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "po.id_outlet", 2)
 	query := `SELECT p.id_pelanggan, po.id_outlet, p.nama_lengkap, p.email, p.nomor_hp, p.alamat, p.created_at, p.updated_at 
 	          FROM pelanggan p
 			  JOIN pelanggan_outlet po ON p.id_pelanggan = po.id_pelanggan
-			  WHERE po.id_outlet = $1`
+			  WHERE po.id_outlet = $1 AND ` + scopeCond
 
-	rows, err := r.db.Query(query, outletID)
+	rows, err := r.db.Query(query, append([]interface{}{outletID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -101,12 +102,14 @@ func (r *customerPostgresRepository) FindByOutletID(outletID int) ([]entities.Cu
 	return customers, nil
 }
 
-func (r *customerPostgresRepository) FindAll() ([]entities.Customer, error) {
+func (r *customerPostgresRepository) FindAll(scope entities.AccessScope) ([]entities.Customer, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "po.id_outlet", 1)
 	query := `SELECT p.id_pelanggan, po.id_outlet, p.nama_lengkap, p.email, p.nomor_hp, p.alamat, p.created_at, p.updated_at 
 	          FROM pelanggan p
-			  JOIN pelanggan_outlet po ON p.id_pelanggan = po.id_pelanggan`
+			  JOIN pelanggan_outlet po ON p.id_pelanggan = po.id_pelanggan
+			  WHERE ` + scopeCond
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, scopeArgs...)
 	if err != nil {
 		return nil, err
 	}
@@ -126,19 +129,23 @@ func (r *customerPostgresRepository) FindAll() ([]entities.Customer, error) {
 	return customers, nil
 }
 
-func (r *customerPostgresRepository) FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Customer, int, int, error) {
+func (r *customerPostgresRepository) FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string, scope entities.AccessScope) ([]entities.Customer, int, int, error) {
 	baseQuery := `SELECT p.id_pelanggan, po.id_outlet, p.nama_lengkap, p.email, p.nomor_hp, p.alamat, p.created_at, p.updated_at 
 	              FROM pelanggan p
 				  JOIN pelanggan_outlet po ON p.id_pelanggan = po.id_pelanggan`
 	countQuery := "SELECT COUNT(*) FROM pelanggan p JOIN pelanggan_outlet po ON p.id_pelanggan = po.id_pelanggan"
 
-	var args []interface{}
-	argIndex := 1
+	// Limit the rows to the caller's part of the hierarchy
+	scopeCond, args := outletScopeCondition(scope, "po.id_outlet", 1)
+	argIndex := len(args) + 1
+	baseQuery += " WHERE " + scopeCond
+	countQuery += " WHERE " + scopeCond
 
 	// Add search condition if provided
 	if search != "" {
-		baseQuery += " WHERE LOWER(p.nama_lengkap) LIKE $1 OR LOWER(p.email) LIKE $1 OR p.nomor_hp LIKE $1"
-		countQuery += " WHERE LOWER(p.nama_lengkap) LIKE $1 OR LOWER(p.email) LIKE $1 OR p.nomor_hp LIKE $1"
+		searchCond := fmt.Sprintf(" AND (LOWER(p.nama_lengkap) LIKE $%d OR LOWER(p.email) LIKE $%d OR p.nomor_hp LIKE $%d)", argIndex, argIndex, argIndex)
+		baseQuery += searchCond
+		countQuery += searchCond
 		args = append(args, "%"+search+"%")
 		argIndex++
 	}
@@ -279,3 +286,13 @@ func (r *customerPostgresRepository) Delete(id int) error {
 
 	return nil
 }
+
+// ExistsInScope reports whether the pelanggan is registered at any outlet inside the caller's part of the hierarchy
+func (r *customerPostgresRepository) ExistsInScope(id int, scope entities.AccessScope) (bool, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "po.id_outlet", 2)
+	query := `SELECT EXISTS (SELECT 1 FROM pelanggan_outlet po WHERE po.id_pelanggan = $1 AND ` + scopeCond + `)`
+
+	var exists bool
+	err := r.db.QueryRow(query, append([]interface{}{id}, scopeArgs...)...).Scan(&exists)
+	return exists, err
+}
@@ -54,9 +54,10 @@ func (r *employeePostgresRepository) FindByID(id int) (*entities.Employee, error
 	return &employee, nil
 }
 
-func (r *employeePostgresRepository) FindAll() ([]entities.Employee, error) {
-	query := `SELECT id_pegawai, id_outlet, nik, nama_lengkap, email, telepon, alamat, tanggal_lahir, jenis_kelamin, posisi, gaji, tanggal_masuk, status,  created_at, updated_at FROM pegawai`
-	rows, err := r.db.Query(query)
+func (r *employeePostgresRepository) FindAll(scope entities.AccessScope) ([]entities.Employee, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "id_outlet", 1)
+	query := `SELECT id_pegawai, id_outlet, nik, nama_lengkap, email, telepon, alamat, tanggal_lahir, jenis_kelamin, posisi, gaji, tanggal_masuk, status,  created_at, updated_at FROM pegawai WHERE ` + scopeCond
+	rows, err := r.db.Query(query, scopeArgs...)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +92,7 @@ func (r *employeePostgresRepository) FindAll() ([]entities.Employee, error) {
 	return employees, nil
 }
 
-func (r *employeePostgresRepository) FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Employee, int, int, error) {
+func (r *employeePostgresRepository) FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string, scope entities.AccessScope) ([]entities.Employee, int, int, error) {
 	// Validate orderBy field to prevent SQL injection
 	validFields := map[string]bool{
 		"id_pegawai":    true,
@@ -144,14 +145,17 @@ func (r *employeePostgresRepository) FindAllWithPagination(limit, offset int, se
 	baseQuery := `SELECT id_pegawai, id_outlet, nik, nama_lengkap, email, telepon, alamat, tanggal_lahir, jenis_kelamin, posisi, gaji, tanggal_masuk, status, created_at, updated_at FROM pegawai`
 	countQuery := `SELECT COUNT(*) FROM pegawai`
 
-	var args []interface{}
-	argIndex := 1
+	// Limit the rows to the caller's part of the hierarchy
+	scopeCond, args := outletScopeCondition(scope, "id_outlet", 1)
+	argIndex := len(args) + 1
+	baseQuery += ` WHERE ` + scopeCond
+	countQuery += ` WHERE ` + scopeCond
 
 	// Add search condition if provided
 	if search != "" {
 		search = strings.ToLower(search)
-		baseQuery += fmt.Sprintf(` WHERE (LOWER(nik) LIKE $%d OR LOWER(nama_lengkap) LIKE $%d OR LOWER(email) LIKE $%d)`, argIndex, argIndex+1, argIndex+2)
-		countQuery += fmt.Sprintf(` WHERE (LOWER(nik) LIKE $%d OR LOWER(nama_lengkap) LIKE $%d OR LOWER(email) LIKE $%d)`, argIndex, argIndex+1, argIndex+2)
+		baseQuery += fmt.Sprintf(` AND (LOWER(nik) LIKE $%d OR LOWER(nama_lengkap) LIKE $%d OR LOWER(email) LIKE $%d)`, argIndex, argIndex+1, argIndex+2)
+		countQuery += fmt.Sprintf(` AND (LOWER(nik) LIKE $%d OR LOWER(nama_lengkap) LIKE $%d OR LOWER(email) LIKE $%d)`, argIndex, argIndex+1, argIndex+2)
 		args = append(args, "%"+search+"%", "%"+search+"%", "%"+search+"%")
 		argIndex += 3
 	}
@@ -233,3 +237,13 @@ func (r *employeePostgresRepository) Delete(id int) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+// ExistsInScope reports whether the pegawai works at an outlet inside the caller's part of the hierarchy
+func (r *employeePostgresRepository) ExistsInScope(id int, scope entities.AccessScope) (bool, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "id_outlet", 2)
+	query := `SELECT EXISTS (SELECT 1 FROM pegawai WHERE id_pegawai = $1 AND ` + scopeCond + `)`
+
+	var exists bool
+	err := r.db.QueryRow(query, append([]interface{}{id}, scopeArgs...)...).Scan(&exists)
+	return exists, err
+}
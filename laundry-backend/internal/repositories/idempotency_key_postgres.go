@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"database/sql"
+	"laundry-backend/internal/entities"
+)
+
+type idempotencyKeyPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyKeyRepository(db *sql.DB) IdempotencyKeyRepository {
+	return &idempotencyKeyPostgresRepository{db: db}
+}
+
+// Reserve claims owner/key for a new request and reports whether it did; an expired key is claimed again,
+// a live one is left untouched so the caller can replay or reject it
+func (r *idempotencyKeyPostgresRepository) Reserve(key *entities.IdempotencyKey) (bool, error) {
+	query := `INSERT INTO idempotency_key (owner, idempotency_key, request_hash, expired_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (owner, idempotency_key) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_body = NULL,
+				expired_at = EXCLUDED.expired_at, completed_at = NULL, created_at = NOW()
+			WHERE idempotency_key.expired_at < NOW()
+		RETURNING id_idempotency_key, created_at`
+
+	err := r.db.QueryRow(query, key.Owner, key.Key, key.RequestHash, key.ExpiredAt).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *idempotencyKeyPostgresRepository) FindByKey(owner, key string) (*entities.IdempotencyKey, error) {
+	query := `SELECT id_idempotency_key, owner, idempotency_key, request_hash, status_code, response_body,
+			expired_at, completed_at, created_at
+		FROM idempotency_key
+		WHERE owner = $1 AND idempotency_key = $2`
+
+	var (
+		record       entities.IdempotencyKey
+		statusCode   sql.NullInt64
+		responseBody sql.NullString
+		completedAt  sql.NullTime
+	)
+	err := r.db.QueryRow(query, owner, key).Scan(
+		&record.ID,
+		&record.Owner,
+		&record.Key,
+		&record.RequestHash,
+		&statusCode,
+		&responseBody,
+		&record.ExpiredAt,
+		&completedAt,
+		&record.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	record.StatusCode = int(statusCode.Int64)
+	record.ResponseBody = responseBody.String
+	if completedAt.Valid {
+		record.CompletedAt = &completedAt.Time
+	}
+
+	return &record, nil
+}
+
+// Complete stores the response of the request that reserved the key
+func (r *idempotencyKeyPostgresRepository) Complete(id, statusCode int, responseBody string) error {
+	query := `UPDATE idempotency_key SET status_code = $1, response_body = $2, completed_at = NOW()
+		WHERE id_idempotency_key = $3`
+
+	_, err := r.db.Exec(query, statusCode, responseBody, id)
+	return err
+}
+
+// Delete frees a key whose request failed, so a retry is processed again
+func (r *idempotencyKeyPostgresRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_key WHERE id_idempotency_key = $1`, id)
+	return err
+}
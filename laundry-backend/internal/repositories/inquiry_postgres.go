@@ -35,19 +35,6 @@ func (r *inquiryPostgresRepository) ValidateEmployee(id int) (*entities.Employee
 	return employee, nil
 }
 
-func (r *inquiryPostgresRepository) ValidateCustomer(id int) (bool, error) {
-	query := `SELECT COUNT(*) FROM pelanggan WHERE id_pelanggan = $1`
-	row := r.db.QueryRow(query, id)
-
-	var count int
-	err := row.Scan(&count)
-	if err != nil {
-		return false, err
-	}
-
-	return count > 0, nil
-}
-
 // Transaction methods
 func (r *inquiryPostgresRepository) BeginTransaction() (*sql.Tx, error) {
 	return r.db.Begin()
@@ -65,14 +52,19 @@ func (r *inquiryPostgresRepository) InsertTransactionWithTx(tx *sql.Tx, transact
 		id_pegawai,
 		tanggal_selesai,
 		tanggal_diambil,
+		estimasi_selesai,
 		total_harga,
+		subtotal_layanan,
+		biaya_admin,
+		biaya_merchant,
+		biaya_ditanggung,
 		uang_bayar,
 		uang_kembalian,
 		created_at,
 		updated_at,
 		created_by,
 		updated_by
-	) VALUES (?, ?, ?, ?, ?, ?,?,?,?,?,?,?,?,?,?,?
+	) VALUES (?, ?, ?, ?, ?, ?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?
 	) RETURNING id_transaksi`
 
 	var id int
@@ -88,7 +80,12 @@ func (r *inquiryPostgresRepository) InsertTransactionWithTx(tx *sql.Tx, transact
 		transaction.UserID,
 		transaction.CompletionDate,
 		transaction.PickupDate,
+		transaction.EstimatedReady,
 		transaction.TotalPrice,
+		transaction.ServiceSubtotal,
+		transaction.AdminFee,
+		transaction.MerchantFee,
+		transaction.FeeBearer,
 		transaction.PaidAmount,
 		transaction.ChangeAmount,
 		transaction.CreatedAt,
@@ -115,14 +112,19 @@ func (r *inquiryPostgresRepository) InsertTransactionDetailWithTx(tx *sql.Tx, de
 			id_transaksi,
 			id_layanan,
 			kuantitas,
+			kuantitas_ditagih,
 			harga_satuan,
+			harga_dasar,
+			total_biaya_tambahan,
 			subtotal,
+			estimasi_selesai,
 			status_pengerjaan,
+			catatan,
 			created_at,
 			updated_at,
 			created_by,
 			updated_by
-	) VALUES (?,?,?,?,?,?,?,?,?,?
+	) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?
 	) RETURNING id_detail`
 
 	var id int
@@ -132,9 +134,14 @@ func (r *inquiryPostgresRepository) InsertTransactionDetailWithTx(tx *sql.Tx, de
 		detail.TransactionID,
 		detail.ServiceID,
 		detail.Quantity,
+		detail.ChargedQuantity,
 		detail.Price,
+		detail.BasePrice,
+		detail.SurchargeTotal,
 		detail.Subtotal,
+		detail.EstimatedReady,
 		detail.Status,
+		detail.Note,
 		detail.CreatedAt,
 		detail.UpdatedAt,
 		detail.CreatedBy,
@@ -151,47 +158,28 @@ func (r *inquiryPostgresRepository) InsertTransactionDetailWithTx(tx *sql.Tx, de
 	detail.CreatedAt = now
 	detail.UpdatedAt = now
 
+	// Keep a copy of every modifier applied to the line
+	modifierQuery := `INSERT INTO detail_transaksi_modifier (id_detail, id_modifier, nama, jenis, tipe_nilai, nilai, kuantitas, jumlah, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+	for i := range detail.Modifiers {
+		modifier := &detail.Modifiers[i]
+		modifier.DetailID = id
+		err := tx.QueryRow(modifierQuery, id, modifier.ModifierID, modifier.Name, modifier.Type, modifier.ValueType,
+			modifier.Value, modifier.Quantity, modifier.Amount, detail.CreatedAt).Scan(&modifier.ID)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (r *inquiryPostgresRepository) InsertPaymentWithTx(tx *sql.Tx, payment *entities.Payment) error {
-	query := `INSERT INTO pembayaran (
-			id_transaksi,
-			tanggal_bayar,
-			jumlah_bayar,
-			metode_bayar,
-			id_metode_pembayaran,
-			nomor_referensi_partner,
-			status_code_partner,
-			status_message_partner,
-			catatan,
-			created_at,
-			updated_at
-	) VALUES (?,?,?,?,?,?,?,?,?,?,?
-	) RETURNING id_pembayaran`
-
-	var id int
-	query = utils.QuerySupport(query)
-	err := tx.QueryRow(
-		query,
-		payment.TransactionID,
-		payment.PaymentDate,
-		payment.Amount,
-		payment.Method,
-		payment.PaymentMethodID,
-		payment.PartnerReferenceNo,
-		payment.PartnerStatusCode,
-		payment.PartnerStatusMessage,
-		payment.Note,
-		payment.CreatedAt,
-		payment.UpdatedAt,
-	).Scan(&id)
-
-	if err != nil {
+	if err := insertPayment(tx, payment); err != nil {
 		return err
 	}
 
-	payment.ID = id
 	// Set default values for timestamps
 	now := time.Now()
 	payment.CreatedAt = now
@@ -200,36 +188,16 @@ func (r *inquiryPostgresRepository) InsertPaymentWithTx(tx *sql.Tx, payment *ent
 	return nil
 }
 
-func (r *inquiryPostgresRepository) InsertHistoryStatusTransactionWithTx(tx *sql.Tx, history *entities.HistoryStatusTransaction) error {
-	query := `INSERT INTO history_status_transaksi (
-			id_transaksi,
-			status_lama,
-			status_baru,
-			waktu_perubahan,
-			keterangan,
-			created_at,
-			updated_at
-	) VALUES (?,?,?,?,?,?,?
-	) RETURNING id_history`
-
-	var id int
-	query = utils.QuerySupport(query)
-	err := tx.QueryRow(
-		query,
-		history.TransactionID,
-		history.OldStatus,
-		history.NewStatus,
-		history.ChangeTime,
-		history.Description,
-		history.CreatedAt,
-		history.UpdatedAt,
-	).Scan(&id)
+// InsertPayment records a pembayaran outside the order's database transaction, such as a charge opened after it was committed
+func (r *inquiryPostgresRepository) InsertPayment(payment *entities.Payment) error {
+	return insertPayment(r.db, payment)
+}
 
-	if err != nil {
+func (r *inquiryPostgresRepository) InsertHistoryStatusTransactionWithTx(tx *sql.Tx, history *entities.HistoryStatusTransaction) error {
+	if err := insertHistoryStatusTransaction(tx, history); err != nil {
 		return err
 	}
 
-	history.ID = id
 	// Set default values for timestamps
 	now := time.Now()
 	history.CreatedAt = now
@@ -237,3 +205,46 @@ func (r *inquiryPostgresRepository) InsertHistoryStatusTransactionWithTx(tx *sql
 
 	return nil
 }
+
+// FindInvoiceSettingsWithTx returns the outlet and brand codes and the brand's invoice format, nil when the outlet does not exist
+func (r *inquiryPostgresRepository) FindInvoiceSettingsWithTx(tx *sql.Tx, outletID int) (*entities.InvoiceSettings, error) {
+	query := `SELECT o.id_outlet, o.kode_outlet, b.kode_brand, b.format_invoice
+		FROM outlet o
+		JOIN cabang c ON c.id_cabang = o.id_cabang
+		JOIN brand b ON b.id_brand = c.id_brand
+		WHERE o.id_outlet = $1`
+
+	var settings entities.InvoiceSettings
+	err := tx.QueryRow(query, outletID).Scan(
+		&settings.OutletID,
+		&settings.OutletCode,
+		&settings.BrandCode,
+		&settings.Format,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// NextInvoiceSequenceWithTx increments and returns the outlet's invoice sequence for period.
+// The upsert locks the sequence row until tx commits or rolls back, so a concurrent inquiry of the
+// same outlet waits for it and a rolled back number is handed out again.
+func (r *inquiryPostgresRepository) NextInvoiceSequenceWithTx(tx *sql.Tx, outletID int, period string) (int, error) {
+	query := `INSERT INTO urutan_invoice (id_outlet, periode, nomor_terakhir)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (id_outlet, periode)
+		DO UPDATE SET nomor_terakhir = urutan_invoice.nomor_terakhir + 1, updated_at = NOW()
+		RETURNING nomor_terakhir`
+
+	var seq int
+	if err := tx.QueryRow(query, outletID, period).Scan(&seq); err != nil {
+		return 0, err
+	}
+
+	return seq, nil
+}
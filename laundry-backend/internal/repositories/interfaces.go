@@ -3,11 +3,14 @@ package repositories
 import (
 	"database/sql"
 	"laundry-backend/internal/entities"
+	"time"
 )
 
 type UserRepository interface {
 	FindByEmail(email string) (*entities.User, error)
 	Create(user *entities.User) error
+	RegisterFailedLogin(id, maxAttempts int, lockout time.Duration) (*time.Time, error)
+	ResetFailedLogins(id int) error
 }
 
 type BrandRepository interface {
@@ -15,6 +18,7 @@ type BrandRepository interface {
 	FindByID(id int) (*entities.Brand, error)
 	FindAll() ([]entities.Brand, error)
 	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Brand, int, int, error)
+	ExistsInScope(id int, scope entities.AccessScope) (bool, error)
 	Update(brand *entities.Brand) error
 	Delete(id int) error
 }
@@ -25,6 +29,7 @@ type CabangRepository interface {
 	FindByBrandID(brandID int) ([]entities.Cabang, error)
 	FindAll() ([]entities.Cabang, error)
 	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Cabang, int, int, error)
+	ExistsInScope(id int, scope entities.AccessScope) (bool, error)
 	Update(cabang *entities.Cabang) error
 	Delete(id int) error
 }
@@ -32,17 +37,43 @@ type CabangRepository interface {
 type OutletRepository interface {
 	Create(outlet *entities.Outlet) error
 	FindByID(id int) (*entities.Outlet, error)
-	FindByCabangID(cabangID int) ([]entities.Outlet, error)
-	FindAll(request entities.Outlet) ([]entities.Outlet, error)
-	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Outlet, int, int, error)
+	FindByCabangID(cabangID int, scope entities.AccessScope) ([]entities.Outlet, error)
+	FindAll(request entities.Outlet, scope entities.AccessScope) ([]entities.Outlet, error)
+	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string, scope entities.AccessScope) ([]entities.Outlet, int, int, error)
+	ExistsInScope(id int, scope entities.AccessScope) (bool, error)
 	Update(outlet *entities.Outlet) error
 	Delete(id int) error
 }
 
+type CalendarRepository interface {
+	FindOpeningHours(outletID int) ([]entities.OpeningHours, error)
+	ReplaceOpeningHours(outletID int, hours []entities.OpeningHours) error
+	FindHolidaysByOutlet(outletID int, from, to time.Time) ([]entities.Holiday, error)
+	FindHolidayByID(id int) (*entities.Holiday, error)
+	CreateHoliday(holiday *entities.Holiday) error
+	DeleteHoliday(id int) error
+}
+
+type ModifierRepository interface {
+	Create(modifier *entities.Modifier) error
+	FindByID(id int) (*entities.Modifier, error)
+	FindAll(filter entities.ModifierFilter, scope entities.AccessScope) ([]entities.Modifier, error)
+	Update(modifier *entities.Modifier) error
+	Delete(id int) error
+}
+
+type PricingRuleRepository interface {
+	FindByService(serviceID int, scope entities.AccessScope) ([]entities.PricingRule, error)
+	FindEffective(serviceID, outletID int) (*entities.PricingRule, error)
+	FindByID(id int) (*entities.PricingRule, error)
+	Save(rule *entities.PricingRule) error
+	Delete(id int) error
+}
+
 type InquiryRepository interface {
 	// ValidateServicePackage(id int) (bool, error)
 	ValidateEmployee(id int) (*entities.Employee, error)
-	ValidateCustomer(id int) (bool, error)
+	InsertPayment(payment *entities.Payment) error
 	// GetServicePackagePrice(id int) (float64, error)
 	// Transaction methods
 	BeginTransaction() (*sql.Tx, error)
@@ -50,13 +81,16 @@ type InquiryRepository interface {
 	InsertTransactionDetailWithTx(tx *sql.Tx, detail *entities.TransactionDetail) error
 	InsertPaymentWithTx(tx *sql.Tx, payment *entities.Payment) error
 	InsertHistoryStatusTransactionWithTx(tx *sql.Tx, history *entities.HistoryStatusTransaction) error
+	FindInvoiceSettingsWithTx(tx *sql.Tx, outletID int) (*entities.InvoiceSettings, error)
+	NextInvoiceSequenceWithTx(tx *sql.Tx, outletID int, period string) (int, error)
 }
 
 type EmployeeRepository interface {
 	Create(employee *entities.Employee) error
 	FindByID(id int) (*entities.Employee, error)
-	FindAll() ([]entities.Employee, error)
-	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Employee, int, int, error)
+	FindAll(scope entities.AccessScope) ([]entities.Employee, error)
+	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string, scope entities.AccessScope) ([]entities.Employee, int, int, error)
+	ExistsInScope(id int, scope entities.AccessScope) (bool, error)
 	Update(employee *entities.Employee) error
 	Delete(id int) error
 }
@@ -64,9 +98,10 @@ type EmployeeRepository interface {
 type CustomerRepository interface {
 	Create(customer *entities.Customer) error
 	FindByID(id int) (*entities.Customer, error)
-	FindByOutletID(outletID int) ([]entities.Customer, error)
-	FindAll() ([]entities.Customer, error)
-	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Customer, int, int, error)
+	FindByOutletID(outletID int, scope entities.AccessScope) ([]entities.Customer, error)
+	FindAll(scope entities.AccessScope) ([]entities.Customer, error)
+	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string, scope entities.AccessScope) ([]entities.Customer, int, int, error)
+	ExistsInScope(id int, scope entities.AccessScope) (bool, error)
 	Update(customer *entities.Customer) error
 	Delete(id int) error
 }
@@ -74,11 +109,11 @@ type CustomerRepository interface {
 type ServiceRepository interface {
 	Create(service *entities.Service) error
 	FindByID(id int) (*entities.Service, error)
-	FindAll() ([]entities.Service, error)
-	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Service, int, error)
+	FindAll(scope entities.AccessScope) ([]entities.Service, error)
+	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string, scope entities.AccessScope) ([]entities.Service, int, error)
 	Update(service *entities.Service) error
 	Delete(id int) error
-	FindByCategoryID(categoryID int) ([]entities.Service, error)
+	FindByCategoryID(categoryID int, scope entities.AccessScope) ([]entities.Service, error)
 }
 
 type ServiceCategoryRepository interface {
@@ -94,33 +129,82 @@ type UserAccessRepository interface {
 	Create(access *entities.UserAccess) error
 	FindByID(id int) (*entities.UserAccess, error)
 	FindByUsername(username string) (*entities.UserAccess, error)
-	FindAll() ([]entities.UserAccess, error)
-	FindAllWithPagination(limit, offset int) ([]entities.UserAccess, int, error)
+	FindAll(scope entities.AccessScope) ([]entities.UserAccess, error)
+	FindAllWithPagination(limit, offset int, scope entities.AccessScope) ([]entities.UserAccess, int, error)
+	ExistsInScope(id int, scope entities.AccessScope) (bool, error)
 	Update(access *entities.UserAccess) error
-	UpdatePassword(id int, password string) error
+	UpdatePassword(id int, password string, mustChangePassword bool) error
+	FindPasswordHashes(id, limit int) ([]string, error)
 	UpdateLastLogin(id int) error
+	RegisterFailedLogin(id, maxAttempts int, lockout time.Duration) (*time.Time, error)
+	ResetFailedLogins(id int) error
 	Delete(id int) error
 	AuthenticateUser(username, password string) (*entities.UserAccess, error)
+	// Transaction methods
+	BeginTransaction() (*sql.Tx, error)
+	UpdatePasswordWithTx(tx *sql.Tx, id int, password string, mustChangePassword bool) error
+	SetMustChangePasswordWithTx(tx *sql.Tx, id int, mustChangePassword bool) error
+}
+
+type PasswordResetRepository interface {
+	CreateWithTx(tx *sql.Tx, token *entities.PasswordResetToken) error
+	FindByHashForUpdateWithTx(tx *sql.Tx, tokenHash string) (*entities.PasswordResetToken, error)
+	MarkUsedWithTx(tx *sql.Tx, id int) error
+	InvalidateByAccessIDWithTx(tx *sql.Tx, accessID int) error
+}
+
+type LoginAuditRepository interface {
+	Create(audit *entities.LoginAudit) error
+	CountFailedByIP(ipAddress string, since time.Time) (int, error)
+}
+
+type IdempotencyKeyRepository interface {
+	Reserve(key *entities.IdempotencyKey) (bool, error)
+	FindByKey(owner, key string) (*entities.IdempotencyKey, error)
+	Complete(id, statusCode int, responseBody string) error
+	Delete(id int) error
+}
+
+type RefreshTokenRepository interface {
+	Create(token *entities.RefreshToken) error
+	RevokeByHash(tokenHash string) (bool, error)
+	RevokeAllByAccessID(accessID int) (int, error)
+	// Transaction methods
+	BeginTransaction() (*sql.Tx, error)
+	FindByHashForUpdateWithTx(tx *sql.Tx, tokenHash string) (*entities.RefreshToken, error)
+	CreateWithTx(tx *sql.Tx, token *entities.RefreshToken) error
+	RevokeWithTx(tx *sql.Tx, id int, replacedBy *int) error
+	RevokeAllByAccessIDWithTx(tx *sql.Tx, accessID int) (int, error)
 }
 
 type TransactionRepository interface {
-	FindAll() ([]entities.Transaction, error)
-	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Transaction, int, error)
+	FindAll(scope entities.AccessScope) ([]entities.Transaction, error)
+	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string, scope entities.AccessScope) ([]entities.Transaction, int, error)
 	FindByID(id int) (*entities.Transaction, error)
-	FindByOutletID(outletID int) ([]entities.Transaction, error)
+	ExistsInScope(id int, scope entities.AccessScope) (bool, error)
+	FindByOutletID(outletID int, scope entities.AccessScope) ([]entities.Transaction, error)
 	FindDetailsByTransactionID(transactionID int) ([]entities.TransactionDetail, error)
-	UpdateTransactionStatus(id int, status string) error
-	UpdatePaymentStatus(id int, status string) error
-	UpdatePaymentCallback(transactionID int, request entities.PaymentCallbackRequest) error
-}
-
-type TransactionUsecase interface {
-	GetAllTransactions() ([]entities.Transaction, error)
-	GetAllTransactionsDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error)
-	GetTransactionByID(id int) (*entities.Transaction, error)
-	GetTransactionsByOutletID(outletID int) ([]entities.Transaction, error)
-	GetTransactionDetails(transactionID int) ([]entities.TransactionDetail, error)
-	UpdateTransactionStatus(id int, request entities.UpdateTransactionStatusRequest) error
-	UpdatePaymentStatus(id int, request entities.UpdatePaymentStatusRequest) error
-	ProcessPaymentCallback(request entities.PaymentCallbackRequest) error
+	FindHistoryByTransactionID(transactionID int) ([]entities.HistoryStatusTransaction, error)
+	GetRevenueSummary(outletID int, from, to time.Time, scope entities.AccessScope) (*entities.RevenueReport, error)
+	// Transaction methods
+	BeginTransaction() (*sql.Tx, error)
+	FindByIDForUpdateWithTx(tx *sql.Tx, id int) (*entities.Transaction, error)
+	UpdateTransactionStatusWithTx(tx *sql.Tx, transaction *entities.Transaction) error
+	InsertHistoryStatusTransactionWithTx(tx *sql.Tx, history *entities.HistoryStatusTransaction) error
+	UpdatePaidAmountWithTx(tx *sql.Tx, transactionID int) error
+}
+
+type PaymentRepository interface {
+	FindByID(id int) (*entities.Payment, error)
+	FindByTransactionID(transactionID int) ([]entities.Payment, error)
+	FindByReferenceNoWithTx(tx *sql.Tx, paymentMethodID int, referenceNo string) (*entities.Payment, error)
+	CreateCallbackLog(log *entities.PaymentCallbackLog) error
+	FindRefundsByTransactionID(transactionID int) ([]entities.PaymentRefund, error)
+	UpdateRefundStatus(refund *entities.PaymentRefund) error
+	// Transaction methods
+	BeginTransaction() (*sql.Tx, error)
+	FindByTransactionIDForUpdateWithTx(tx *sql.Tx, transactionID int) ([]entities.Payment, error)
+	CreateWithTx(tx *sql.Tx, payment *entities.Payment) error
+	UpdateStatusWithTx(tx *sql.Tx, payment *entities.Payment) error
+	CreateRefundWithTx(tx *sql.Tx, refund *entities.PaymentRefund) error
 }
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"laundry-backend/internal/entities"
+	"time"
+)
+
+type loginAuditPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAuditRepository(db *sql.DB) LoginAuditRepository {
+	return &loginAuditPostgresRepository{db: db}
+}
+
+func (r *loginAuditPostgresRepository) Create(audit *entities.LoginAudit) error {
+	query := `INSERT INTO login_audit (login_type, username, ip_address, user_agent, success, failure_reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NOW())
+		RETURNING id_login_audit, created_at`
+
+	return r.db.QueryRow(query,
+		audit.LoginType,
+		audit.Username,
+		audit.IPAddress,
+		audit.UserAgent,
+		audit.Success,
+		audit.FailureReason,
+	).Scan(&audit.ID, &audit.CreatedAt)
+}
+
+// CountFailedByIP counts the failed login attempts of both logins from ipAddress since the given time
+func (r *loginAuditPostgresRepository) CountFailedByIP(ipAddress string, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM login_audit
+		WHERE ip_address = $1 AND success = false AND created_at >= $2`
+
+	var count int
+	err := r.db.QueryRow(query, ipAddress, since).Scan(&count)
+	return count, err
+}
+
+// registerFailedLogin counts a wrong password against a users/user_access row. Reaching maxAttempts
+// locks the row for lockout and starts the count again; it returns locked_until, nil when never locked.
+func registerFailedLogin(db *sql.DB, table, idColumn string, id, maxAttempts int, lockout time.Duration) (*time.Time, error) {
+	query := fmt.Sprintf(`UPDATE %[1]s
+		SET failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= $2 THEN 0 ELSE failed_login_attempts + 1 END,
+			locked_until = CASE WHEN failed_login_attempts + 1 >= $2 THEN NOW() + make_interval(secs => $3) ELSE locked_until END
+		WHERE %[2]s = $1
+		RETURNING locked_until`, table, idColumn)
+
+	var lockedUntil sql.NullTime
+	if err := db.QueryRow(query, id, maxAttempts, lockout.Seconds()).Scan(&lockedUntil); err != nil {
+		return nil, err
+	}
+	if !lockedUntil.Valid {
+		return nil, nil
+	}
+	return &lockedUntil.Time, nil
+}
+
+// resetFailedLogins clears the failed attempt count and any lockout of a users/user_access row
+func resetFailedLogins(db *sql.DB, table, idColumn string, id int) error {
+	query := fmt.Sprintf(`UPDATE %s SET failed_login_attempts = 0, locked_until = NULL WHERE %s = $1`, table, idColumn)
+	_, err := db.Exec(query, id)
+	return err
+}
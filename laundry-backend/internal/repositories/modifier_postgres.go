@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"database/sql"
+	"laundry-backend/internal/entities"
+)
+
+type modifierPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewModifierRepository(db *sql.DB) ModifierRepository {
+	return &modifierPostgresRepository{db: db}
+}
+
+// modifierColumns is the column list shared by every modifier_layanan SELECT, in scanModifier order
+const modifierColumns = `m.id_modifier, m.id_brand, m.id_layanan, m.id_kategori, m.nama, m.jenis, m.tipe_nilai, m.nilai,
+	COALESCE(m.durasi_pengerjaan, 0), COALESCE(m.satuan_durasi, ''), m.aktif, m.created_at, m.updated_at`
+
+// scanModifier scans a row selected with modifierColumns
+func scanModifier(row rowScanner) (*entities.Modifier, error) {
+	var modifier entities.Modifier
+	var serviceID, categoryID sql.NullInt64
+
+	err := row.Scan(
+		&modifier.ID,
+		&modifier.BrandID,
+		&serviceID,
+		&categoryID,
+		&modifier.Name,
+		&modifier.Type,
+		&modifier.ValueType,
+		&modifier.Value,
+		&modifier.Estimation,
+		&modifier.Unit,
+		&modifier.Active,
+		&modifier.CreatedAt,
+		&modifier.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if serviceID.Valid {
+		id := int(serviceID.Int64)
+		modifier.ServiceID = &id
+	}
+	if categoryID.Valid {
+		id := int(categoryID.Int64)
+		modifier.CategoryID = &id
+	}
+
+	return &modifier, nil
+}
+
+// nullableDuration stores a modifier without its own durasi_pengerjaan as NULL
+func nullableDuration(modifier *entities.Modifier) (interface{}, interface{}) {
+	if modifier.Estimation <= 0 {
+		return nil, nil
+	}
+	return modifier.Estimation, modifier.Unit
+}
+
+func (r *modifierPostgresRepository) Create(modifier *entities.Modifier) error {
+	estimation, unit := nullableDuration(modifier)
+	query := `INSERT INTO modifier_layanan (id_brand, id_layanan, id_kategori, nama, jenis, tipe_nilai, nilai,
+			durasi_pengerjaan, satuan_durasi, aktif, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		RETURNING id_modifier, created_at, updated_at`
+	return r.db.QueryRow(query, modifier.BrandID, modifier.ServiceID, modifier.CategoryID, modifier.Name, modifier.Type,
+		modifier.ValueType, modifier.Value, estimation, unit, modifier.Active).
+		Scan(&modifier.ID, &modifier.CreatedAt, &modifier.UpdatedAt)
+}
+
+func (r *modifierPostgresRepository) FindByID(id int) (*entities.Modifier, error) {
+	query := `SELECT ` + modifierColumns + ` FROM modifier_layanan m WHERE m.id_modifier = $1`
+	modifier, err := scanModifier(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return modifier, nil
+}
+
+// FindAll returns the modifiers of filter.BrandID, or of every brand inside scope when it is 0. With filter.ServiceID
+// only the active modifiers of the service's brand that apply to the service or its category are returned.
+func (r *modifierPostgresRepository) FindAll(filter entities.ModifierFilter, scope entities.AccessScope) ([]entities.Modifier, error) {
+	scopeCond, scopeArgs := brandScopeCondition(scope, "m.id_brand", 3)
+	query := `SELECT ` + modifierColumns + `
+		FROM modifier_layanan m
+		LEFT JOIN paket_layanan l ON l.id_layanan = $2
+		WHERE ($1 = 0 OR m.id_brand = $1)
+			AND ($2 = 0 OR (m.aktif AND m.id_brand = l.id_brand
+				AND (m.id_layanan IS NULL OR m.id_layanan = l.id_layanan)
+				AND (m.id_kategori IS NULL OR m.id_kategori = l.id_kategori)))
+			AND ` + scopeCond + `
+		ORDER BY m.jenis DESC, m.nama, m.id_modifier`
+	rows, err := r.db.Query(query, append([]interface{}{filter.BrandID, filter.ServiceID}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var modifiers []entities.Modifier
+	for rows.Next() {
+		modifier, err := scanModifier(rows)
+		if err != nil {
+			return nil, err
+		}
+		modifiers = append(modifiers, *modifier)
+	}
+
+	return modifiers, rows.Err()
+}
+
+func (r *modifierPostgresRepository) Update(modifier *entities.Modifier) error {
+	estimation, unit := nullableDuration(modifier)
+	query := `UPDATE modifier_layanan
+		SET id_layanan = $1, id_kategori = $2, nama = $3, jenis = $4, tipe_nilai = $5, nilai = $6,
+			durasi_pengerjaan = $7, satuan_durasi = $8, aktif = $9, updated_at = NOW()
+		WHERE id_modifier = $10
+		RETURNING updated_at`
+	return r.db.QueryRow(query, modifier.ServiceID, modifier.CategoryID, modifier.Name, modifier.Type, modifier.ValueType,
+		modifier.Value, estimation, unit, modifier.Active, modifier.ID).Scan(&modifier.UpdatedAt)
+}
+
+func (r *modifierPostgresRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM modifier_layanan WHERE id_modifier = $1`, id)
+	return err
+}
@@ -27,16 +27,16 @@ func (r *outletPostgresRepository) Create(outlet *entities.Outlet) error {
 	}
 
 	query := `INSERT INTO outlet (id_cabang, nama_outlet, alamat, kota, provinsi, kode_pos, telepon, email, 
-		latitude, longitude, jam_buka, jam_tutup, pic_nama, pic_email, pic_telepon, created_at, updated_at) 
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW(), NOW()) RETURNING id_outlet`
+		latitude, longitude, jam_buka, jam_tutup, pic_nama, pic_email, pic_telepon, wajib_lunas_saat_diambil, kode_outlet, zona_waktu, created_at, updated_at) 
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, NOW(), NOW()) RETURNING id_outlet`
 	return r.db.QueryRow(query, outlet.CabangID, outlet.Name, outlet.Address, outlet.City, outlet.Province,
 		outlet.PostalCode, outlet.Phone, outlet.Email, lat, lon, outlet.OpenTime,
-		outlet.CloseTime, outlet.PICName, outlet.PICEmail, outlet.PICTelepon).Scan(&outlet.ID)
+		outlet.CloseTime, outlet.PICName, outlet.PICEmail, outlet.PICTelepon, outlet.RequirePaidOnPickup, outlet.Code, outlet.Timezone).Scan(&outlet.ID)
 }
 
 func (r *outletPostgresRepository) FindByID(id int) (*entities.Outlet, error) {
 	query := `SELECT id_outlet, id_cabang, nama_outlet, alamat, kota, provinsi, kode_pos, telepon, email, 
-		latitude, longitude, jam_buka, jam_tutup, pic_nama, pic_email, pic_telepon, created_at, updated_at 
+		latitude, longitude, jam_buka, jam_tutup, pic_nama, pic_email, pic_telepon, wajib_lunas_saat_diambil, kode_outlet, zona_waktu, created_at, updated_at 
 	FROM outlet WHERE id_outlet = $1`
 	row := r.db.QueryRow(query, id)
 
@@ -59,6 +59,9 @@ func (r *outletPostgresRepository) FindByID(id int) (*entities.Outlet, error) {
 		&outlet.PICName,
 		&outlet.PICEmail,
 		&outlet.PICTelepon,
+		&outlet.RequirePaidOnPickup,
+		&outlet.Code,
+		&outlet.Timezone,
 		&outlet.CreatedAt,
 		&outlet.UpdatedAt,
 	)
@@ -80,11 +83,12 @@ func (r *outletPostgresRepository) FindByID(id int) (*entities.Outlet, error) {
 	return &outlet, nil
 }
 
-func (r *outletPostgresRepository) FindByCabangID(cabangID int) ([]entities.Outlet, error) {
+func (r *outletPostgresRepository) FindByCabangID(cabangID int, scope entities.AccessScope) ([]entities.Outlet, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "id_outlet", 2)
 	query := `SELECT id_outlet, id_cabang, nama_outlet, alamat, kota, provinsi, kode_pos, telepon, email, 
-		latitude, longitude, jam_buka, jam_tutup, pic_nama, pic_email, pic_telepon, created_at, updated_at 
-	FROM outlet WHERE id_cabang = $1`
-	rows, err := r.db.Query(query, cabangID)
+		latitude, longitude, jam_buka, jam_tutup, pic_nama, pic_email, pic_telepon, wajib_lunas_saat_diambil, kode_outlet, zona_waktu, created_at, updated_at 
+	FROM outlet WHERE id_cabang = $1 AND ` + scopeCond
+	rows, err := r.db.Query(query, append([]interface{}{cabangID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +115,9 @@ func (r *outletPostgresRepository) FindByCabangID(cabangID int) ([]entities.Outl
 			&outlet.PICName,
 			&outlet.PICEmail,
 			&outlet.PICTelepon,
+			&outlet.RequirePaidOnPickup,
+			&outlet.Code,
+			&outlet.Timezone,
 			&outlet.CreatedAt,
 			&outlet.UpdatedAt,
 		)
@@ -132,10 +139,11 @@ func (r *outletPostgresRepository) FindByCabangID(cabangID int) ([]entities.Outl
 	return outlets, nil
 }
 
-func (r *outletPostgresRepository) FindAll(request entities.Outlet) ([]entities.Outlet, error) {
+func (r *outletPostgresRepository) FindAll(request entities.Outlet, scope entities.AccessScope) ([]entities.Outlet, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "id_outlet", 1)
 	query := `SELECT id_outlet, id_cabang, nama_outlet, alamat, kota, provinsi, kode_pos, telepon, email, 
-		latitude, longitude, jam_buka, jam_tutup, pic_nama, pic_email, pic_telepon, created_at, updated_at 
-	FROM outlet where true `
+		latitude, longitude, jam_buka, jam_tutup, pic_nama, pic_email, pic_telepon, wajib_lunas_saat_diambil, kode_outlet, zona_waktu, created_at, updated_at 
+	FROM outlet where ` + scopeCond
 	if request.CabangID != 0 {
 		query += ` and id_cabang = ` + strconv.Itoa(request.CabangID)
 	}
@@ -143,7 +151,7 @@ func (r *outletPostgresRepository) FindAll(request entities.Outlet) ([]entities.
 		query += ` and id_outlet = ` + strconv.Itoa(request.ID)
 	}
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, scopeArgs...)
 	if err != nil {
 		return nil, err
 	}
@@ -170,6 +178,9 @@ func (r *outletPostgresRepository) FindAll(request entities.Outlet) ([]entities.
 			&outlet.PICName,
 			&outlet.PICEmail,
 			&outlet.PICTelepon,
+			&outlet.RequirePaidOnPickup,
+			&outlet.Code,
+			&outlet.Timezone,
 			&outlet.CreatedAt,
 			&outlet.UpdatedAt,
 		)
@@ -191,7 +202,7 @@ func (r *outletPostgresRepository) FindAll(request entities.Outlet) ([]entities.
 	return outlets, nil
 }
 
-func (r *outletPostgresRepository) FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Outlet, int, int, error) {
+func (r *outletPostgresRepository) FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string, scope entities.AccessScope) ([]entities.Outlet, int, int, error) {
 	// Validate orderBy field to prevent SQL injection
 	validFields := map[string]bool{
 		"id":         true,
@@ -230,17 +241,21 @@ func (r *outletPostgresRepository) FindAllWithPagination(limit, offset int, sear
 
 	// Build the query
 	baseQuery := `SELECT id_outlet, id_cabang, nama_outlet, alamat, kota, provinsi, kode_pos, telepon, email, 
-		latitude, longitude, jam_buka, jam_tutup, pic_nama, pic_email, pic_telepon, created_at, updated_at FROM outlet`
+		latitude, longitude, jam_buka, jam_tutup, pic_nama, pic_email, pic_telepon, wajib_lunas_saat_diambil, kode_outlet, zona_waktu, created_at, updated_at FROM outlet`
 	countQuery := `SELECT COUNT(*) FROM outlet`
 
-	var args []interface{}
-	argIndex := 1
+	// Limit the rows to the caller's part of the hierarchy
+	scopeCond, args := outletScopeCondition(scope, "id_outlet", 1)
+	argIndex := len(args) + 1
+	baseQuery += ` WHERE ` + scopeCond
+	countQuery += ` WHERE ` + scopeCond
+	totalQuery, scopeArgs := countQuery, args
 
 	// Add search condition if provided
 	if search != "" {
 		search = strings.ToLower(search)
-		baseQuery += fmt.Sprintf(` WHERE LOWER(nama_outlet) LIKE $%d OR LOWER(kota) LIKE $%d OR LOWER(provinsi) LIKE $%d OR LOWER(pic_nama) LIKE $%d`, argIndex, argIndex+1, argIndex+2, argIndex+3)
-		countQuery += fmt.Sprintf(` WHERE LOWER(nama_outlet) LIKE $%d OR LOWER(kota) LIKE $%d OR LOWER(provinsi) LIKE $%d OR LOWER(pic_nama) LIKE $%d`, argIndex, argIndex+1, argIndex+2, argIndex+3)
+		baseQuery += fmt.Sprintf(` AND (LOWER(nama_outlet) LIKE $%d OR LOWER(kota) LIKE $%d OR LOWER(provinsi) LIKE $%d OR LOWER(pic_nama) LIKE $%d)`, argIndex, argIndex+1, argIndex+2, argIndex+3)
+		countQuery += fmt.Sprintf(` AND (LOWER(nama_outlet) LIKE $%d OR LOWER(kota) LIKE $%d OR LOWER(provinsi) LIKE $%d OR LOWER(pic_nama) LIKE $%d)`, argIndex, argIndex+1, argIndex+2, argIndex+3)
 		args = append(args, "%"+search+"%", "%"+search+"%", "%"+search+"%", "%"+search+"%")
 		argIndex += 4
 	}
@@ -284,6 +299,9 @@ func (r *outletPostgresRepository) FindAllWithPagination(limit, offset int, sear
 			&outlet.PICName,
 			&outlet.PICEmail,
 			&outlet.PICTelepon,
+			&outlet.RequirePaidOnPickup,
+			&outlet.Code,
+			&outlet.Timezone,
 			&outlet.CreatedAt,
 			&outlet.UpdatedAt,
 		)
@@ -304,7 +322,7 @@ func (r *outletPostgresRepository) FindAllWithPagination(limit, offset int, sear
 
 	// Execute the count query
 	var recordsTotal, recordsFiltered int
-	err = r.db.QueryRow(countQuery).Scan(&recordsTotal)
+	err = r.db.QueryRow(totalQuery, scopeArgs...).Scan(&recordsTotal)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -335,10 +353,10 @@ func (r *outletPostgresRepository) Update(outlet *entities.Outlet) error {
 
 	query := `UPDATE outlet SET id_cabang = $1, nama_outlet = $2, alamat = $3, kota = $4, provinsi = $5, 
 		kode_pos = $6, telepon = $7, email = $8, latitude = $9, longitude = $10, jam_buka = $11, jam_tutup = $12, 
-		pic_nama = $13, pic_email = $14, pic_telepon = $15, updated_at = NOW() WHERE id_outlet = $16`
+		pic_nama = $13, pic_email = $14, pic_telepon = $15, wajib_lunas_saat_diambil = $16, kode_outlet = $17, zona_waktu = $18, updated_at = NOW() WHERE id_outlet = $19`
 	_, err := r.db.Exec(query, outlet.CabangID, outlet.Name, outlet.Address, outlet.City, outlet.Province,
 		outlet.PostalCode, outlet.Phone, outlet.Email, lat, lon, outlet.OpenTime,
-		outlet.CloseTime, outlet.PICName, outlet.PICEmail, outlet.PICTelepon, outlet.ID)
+		outlet.CloseTime, outlet.PICName, outlet.PICEmail, outlet.PICTelepon, outlet.RequirePaidOnPickup, outlet.Code, outlet.Timezone, outlet.ID)
 	return err
 }
 
@@ -347,3 +365,13 @@ func (r *outletPostgresRepository) Delete(id int) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+// ExistsInScope reports whether the outlet exists inside the caller's part of the hierarchy
+func (r *outletPostgresRepository) ExistsInScope(id int, scope entities.AccessScope) (bool, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "id_outlet", 2)
+	query := `SELECT EXISTS (SELECT 1 FROM outlet WHERE id_outlet = $1 AND ` + scopeCond + `)`
+
+	var exists bool
+	err := r.db.QueryRow(query, append([]interface{}{id}, scopeArgs...)...).Scan(&exists)
+	return exists, err
+}
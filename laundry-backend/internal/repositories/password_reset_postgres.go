@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"database/sql"
+	"laundry-backend/internal/entities"
+)
+
+type passwordResetPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) PasswordResetRepository {
+	return &passwordResetPostgresRepository{db: db}
+}
+
+func (r *passwordResetPostgresRepository) CreateWithTx(tx *sql.Tx, token *entities.PasswordResetToken) error {
+	query := `INSERT INTO password_reset_token (id_access, token_hash, expired_at, created_at)
+		VALUES ($1, $2, $3, NOW())
+		RETURNING id_password_reset, created_at`
+
+	return tx.QueryRow(query, token.AccessID, token.TokenHash, token.ExpiredAt).Scan(&token.ID, &token.CreatedAt)
+}
+
+// FindByHashForUpdateWithTx reads a reset token and locks its row until tx ends, so it can only be used once
+func (r *passwordResetPostgresRepository) FindByHashForUpdateWithTx(tx *sql.Tx, tokenHash string) (*entities.PasswordResetToken, error) {
+	query := `SELECT id_password_reset, id_access, token_hash, expired_at, used_at, created_at
+		FROM password_reset_token
+		WHERE token_hash = $1
+		FOR UPDATE`
+
+	var (
+		token  entities.PasswordResetToken
+		usedAt sql.NullTime
+	)
+	err := tx.QueryRow(query, tokenHash).Scan(
+		&token.ID,
+		&token.AccessID,
+		&token.TokenHash,
+		&token.ExpiredAt,
+		&usedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return &token, nil
+}
+
+func (r *passwordResetPostgresRepository) MarkUsedWithTx(tx *sql.Tx, id int) error {
+	query := `UPDATE password_reset_token SET used_at = NOW() WHERE id_password_reset = $1`
+	_, err := tx.Exec(query, id)
+	return err
+}
+
+// InvalidateByAccessIDWithTx spends every unused reset token of a user_access, so only the newest one works
+func (r *passwordResetPostgresRepository) InvalidateByAccessIDWithTx(tx *sql.Tx, accessID int) error {
+	query := `UPDATE password_reset_token SET used_at = NOW() WHERE id_access = $1 AND used_at IS NULL`
+	_, err := tx.Exec(query, accessID)
+	return err
+}
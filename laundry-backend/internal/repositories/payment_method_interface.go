@@ -10,5 +10,7 @@ type PaymentMethodRepository interface {
 	FindAll() ([]entities.PaymentMethod, error)
 	FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.PaymentMethod, int, int, error)
 	Update(paymentMethod *entities.PaymentMethod) error
+	UpdateSecrets(paymentMethod *entities.PaymentMethod) error
+	RotateSecrets(updatedBy string) (int, error)
 	Delete(id int) error
 }
\ No newline at end of file
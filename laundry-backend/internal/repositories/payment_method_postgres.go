@@ -4,25 +4,55 @@ import (
 	"database/sql"
 	"fmt"
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/utils"
 	"strings"
 )
 
 type paymentMethodPostgresRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *utils.SecretCipher
 }
 
-func NewPaymentMethodRepository(db *sql.DB) PaymentMethodRepository {
-	return &paymentMethodPostgresRepository{db: db}
+// NewPaymentMethodRepository stores s_key and m_key encrypted with cipher and returns them decrypted
+func NewPaymentMethodRepository(db *sql.DB, cipher *utils.SecretCipher) PaymentMethodRepository {
+	return &paymentMethodPostgresRepository{db: db, cipher: cipher}
+}
+
+// encryptSecrets returns the stored form of the payment method keys
+func (r *paymentMethodPostgresRepository) encryptSecrets(paymentMethod *entities.PaymentMethod) (sKey, mKey string, err error) {
+	if sKey, err = r.cipher.Encrypt(paymentMethod.SKey); err != nil {
+		return "", "", err
+	}
+	if mKey, err = r.cipher.Encrypt(paymentMethod.MKey); err != nil {
+		return "", "", err
+	}
+	return sKey, mKey, nil
+}
+
+// decryptSecrets replaces the stored keys of a scanned payment method with their plain values
+func (r *paymentMethodPostgresRepository) decryptSecrets(paymentMethod *entities.PaymentMethod) error {
+	var err error
+	if paymentMethod.SKey, err = r.cipher.Decrypt(paymentMethod.SKey); err != nil {
+		return err
+	}
+	if paymentMethod.MKey, err = r.cipher.Decrypt(paymentMethod.MKey); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (r *paymentMethodPostgresRepository) Create(paymentMethod *entities.PaymentMethod) error {
-	query := `INSERT INTO metode_pembayaran (nama_metode, url, s_key, m_key, merchant_fee, admin_fee, status, created_at, updated_at,  created_by, updated_by) 
-	VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW(),  $8, $9) RETURNING id`
-	return r.db.QueryRow(query, paymentMethod.NamaMetode, paymentMethod.URL, paymentMethod.SKey, paymentMethod.MKey, paymentMethod.MerchantFee, paymentMethod.AdminFee, paymentMethod.Status, paymentMethod.CreatedBy, paymentMethod.UpdatedBy).Scan(&paymentMethod.ID)
+	query := `INSERT INTO metode_pembayaran (nama_metode, url, s_key, m_key, merchant_fee, admin_fee, merchant_fee_type, fee_bearer, status, created_at, updated_at,  created_by, updated_by) 
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW(),  $10, $11) RETURNING id`
+	sKey, mKey, err := r.encryptSecrets(paymentMethod)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRow(query, paymentMethod.NamaMetode, paymentMethod.URL, sKey, mKey, paymentMethod.MerchantFee, paymentMethod.AdminFee, paymentMethod.MerchantFeeType, paymentMethod.FeeBearer, paymentMethod.Status, paymentMethod.CreatedBy, paymentMethod.UpdatedBy).Scan(&paymentMethod.ID)
 }
 
 func (r *paymentMethodPostgresRepository) FindByID(id int) (*entities.PaymentMethod, error) {
-	query := `SELECT id, nama_metode, url, s_key, m_key, merchant_fee, admin_fee, status, created_at, updated_at,  created_by, updated_by 
+	query := `SELECT id, nama_metode, url, s_key, m_key, merchant_fee, admin_fee, merchant_fee_type, fee_bearer, status, created_at, updated_at,  created_by, updated_by 
 	FROM metode_pembayaran WHERE id = $1`
 	row := r.db.QueryRow(query, id)
 
@@ -35,6 +65,8 @@ func (r *paymentMethodPostgresRepository) FindByID(id int) (*entities.PaymentMet
 		&paymentMethod.MKey,
 		&paymentMethod.MerchantFee,
 		&paymentMethod.AdminFee,
+		&paymentMethod.MerchantFeeType,
+		&paymentMethod.FeeBearer,
 		&paymentMethod.Status,
 		&paymentMethod.CreatedAt,
 		&paymentMethod.UpdatedAt,
@@ -47,12 +79,15 @@ func (r *paymentMethodPostgresRepository) FindByID(id int) (*entities.PaymentMet
 		}
 		return nil, err
 	}
+	if err := r.decryptSecrets(&paymentMethod); err != nil {
+		return nil, err
+	}
 
 	return &paymentMethod, nil
 }
 
 func (r *paymentMethodPostgresRepository) FindAll() ([]entities.PaymentMethod, error) {
-	query := `SELECT id, nama_metode, url, s_key, m_key, merchant_fee, admin_fee, status, created_at, updated_at,  created_by, updated_by FROM metode_pembayaran`
+	query := `SELECT id, nama_metode, url, s_key, m_key, merchant_fee, admin_fee, merchant_fee_type, fee_bearer, status, created_at, updated_at,  created_by, updated_by FROM metode_pembayaran`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -70,6 +105,8 @@ func (r *paymentMethodPostgresRepository) FindAll() ([]entities.PaymentMethod, e
 			&paymentMethod.MKey,
 			&paymentMethod.MerchantFee,
 			&paymentMethod.AdminFee,
+			&paymentMethod.MerchantFeeType,
+			&paymentMethod.FeeBearer,
 			&paymentMethod.Status,
 			&paymentMethod.CreatedAt,
 			&paymentMethod.UpdatedAt,
@@ -79,6 +116,9 @@ func (r *paymentMethodPostgresRepository) FindAll() ([]entities.PaymentMethod, e
 		if err != nil {
 			return nil, err
 		}
+		if err := r.decryptSecrets(&paymentMethod); err != nil {
+			return nil, err
+		}
 		paymentMethods = append(paymentMethods, paymentMethod)
 	}
 
@@ -93,6 +133,7 @@ func (r *paymentMethodPostgresRepository) FindAllWithPagination(limit, offset in
 		"url":          true,
 		"merchant_fee": true,
 		"admin_fee":    true,
+		"fee_bearer":   true,
 		"status":       true,
 		"created_at":   true,
 		"updated_at":   true,
@@ -109,7 +150,7 @@ func (r *paymentMethodPostgresRepository) FindAllWithPagination(limit, offset in
 	}
 
 	// Build the query
-	baseQuery := `SELECT id, nama_metode, url, s_key, m_key, merchant_fee, admin_fee, status, created_at, updated_at,  created_by, updated_by FROM metode_pembayaran`
+	baseQuery := `SELECT id, nama_metode, url, s_key, m_key, merchant_fee, admin_fee, merchant_fee_type, fee_bearer, status, created_at, updated_at,  created_by, updated_by FROM metode_pembayaran`
 	countQuery := `SELECT COUNT(*) FROM metode_pembayaran`
 
 	var args []interface{}
@@ -149,6 +190,8 @@ func (r *paymentMethodPostgresRepository) FindAllWithPagination(limit, offset in
 			&paymentMethod.MKey,
 			&paymentMethod.MerchantFee,
 			&paymentMethod.AdminFee,
+			&paymentMethod.MerchantFeeType,
+			&paymentMethod.FeeBearer,
 			&paymentMethod.Status,
 			&paymentMethod.CreatedAt,
 			&paymentMethod.UpdatedAt,
@@ -158,6 +201,9 @@ func (r *paymentMethodPostgresRepository) FindAllWithPagination(limit, offset in
 		if err != nil {
 			return nil, 0, 0, err
 		}
+		if err := r.decryptSecrets(&paymentMethod); err != nil {
+			return nil, 0, 0, err
+		}
 		paymentMethods = append(paymentMethods, paymentMethod)
 	}
 
@@ -183,12 +229,83 @@ func (r *paymentMethodPostgresRepository) FindAllWithPagination(limit, offset in
 }
 
 func (r *paymentMethodPostgresRepository) Update(paymentMethod *entities.PaymentMethod) error {
-	query := `UPDATE metode_pembayaran SET nama_metode = $1, url = $2, s_key = $3, m_key = $4, merchant_fee = $5, admin_fee = $6, 
-	status = $7, updated_at = NOW(), created_by = $8, updated_by = $9 WHERE id = $10`
-	_, err := r.db.Exec(query, paymentMethod.NamaMetode, paymentMethod.URL, paymentMethod.SKey, paymentMethod.MKey, paymentMethod.MerchantFee, paymentMethod.AdminFee, paymentMethod.Status, paymentMethod.CreatedBy, paymentMethod.UpdatedBy, paymentMethod.ID)
+	// s_key and m_key are only written by UpdateSecrets
+	query := `UPDATE metode_pembayaran SET nama_metode = $1, url = $2, merchant_fee = $3, admin_fee = $4, 
+	merchant_fee_type = $5, fee_bearer = $6, status = $7, updated_at = NOW(), created_by = $8, updated_by = $9 WHERE id = $10`
+	_, err := r.db.Exec(query, paymentMethod.NamaMetode, paymentMethod.URL, paymentMethod.MerchantFee, paymentMethod.AdminFee, paymentMethod.MerchantFeeType, paymentMethod.FeeBearer, paymentMethod.Status, paymentMethod.CreatedBy, paymentMethod.UpdatedBy, paymentMethod.ID)
 	return err
 }
 
+func (r *paymentMethodPostgresRepository) UpdateSecrets(paymentMethod *entities.PaymentMethod) error {
+	sKey, mKey, err := r.encryptSecrets(paymentMethod)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE metode_pembayaran SET s_key = $1, m_key = $2, updated_at = NOW(), updated_by = $3 WHERE id = $4`
+	_, err = r.db.Exec(query, sKey, mKey, paymentMethod.UpdatedBy, paymentMethod.ID)
+	return err
+}
+
+// RotateSecrets re-wraps every stored key that is plain text or encrypted with a retired master key
+func (r *paymentMethodPostgresRepository) RotateSecrets(updatedBy string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(`SELECT id, COALESCE(s_key,''), COALESCE(m_key,'') FROM metode_pembayaran FOR UPDATE`)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	type storedSecrets struct {
+		id         int
+		sKey, mKey string
+	}
+	var pending []storedSecrets
+	for rows.Next() {
+		var stored storedSecrets
+		if err := rows.Scan(&stored.id, &stored.sKey, &stored.mKey); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return 0, err
+		}
+		if r.cipher.NeedsRotation(stored.sKey) || r.cipher.NeedsRotation(stored.mKey) {
+			pending = append(pending, stored)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for _, stored := range pending {
+		sKey, err := r.cipher.Rotate(stored.sKey)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("failed to rotate secrets of payment method %d: %w", stored.id, err)
+		}
+		mKey, err := r.cipher.Rotate(stored.mKey)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("failed to rotate secrets of payment method %d: %w", stored.id, err)
+		}
+		_, err = tx.Exec(`UPDATE metode_pembayaran SET s_key = $1, m_key = $2, updated_at = NOW(), updated_by = $3 WHERE id = $4`,
+			sKey, mKey, updatedBy, stored.id)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(pending), nil
+}
+
 func (r *paymentMethodPostgresRepository) Delete(id int) error {
 	// Instead of deleting, we'll set the deleted_at timestamp
 	query := `UPDATE metode_pembayaran SET deleted_at = NOW(), status = 'inactive' WHERE id = $1`
@@ -0,0 +1,324 @@
+package repositories
+
+import (
+	"database/sql"
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/utils"
+)
+
+type paymentPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) PaymentRepository {
+	return &paymentPostgresRepository{db: db}
+}
+
+// paymentColumns is the column list shared by every pembayaran SELECT, in scanPayment order
+const paymentColumns = `
+		p.id_pembayaran,
+		p.id_transaksi,
+		p.tanggal_bayar,
+		p.jumlah_bayar,
+		COALESCE(p.metode_bayar,''),
+		p.id_metode_pembayaran,
+		COALESCE(p.nomor_referensi_partner,''),
+		COALESCE(p.status_code_partner,''),
+		COALESCE(p.status_message_partner,''),
+		COALESCE(p.status_pembayaran,''),
+		COALESCE(p.catatan,''),
+		p.created_at,
+		p.updated_at`
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// scanPayment scans a row selected with paymentColumns
+func scanPayment(row rowScanner) (*entities.Payment, error) {
+	var payment entities.Payment
+	var paymentDate sql.NullTime
+
+	err := row.Scan(
+		&payment.ID,
+		&payment.TransactionID,
+		&paymentDate,
+		&payment.Amount,
+		&payment.Method,
+		&payment.PaymentMethodID,
+		&payment.PartnerReferenceNo,
+		&payment.PartnerStatusCode,
+		&payment.PartnerStatusMessage,
+		&payment.Status,
+		&payment.Note,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle nullable fields
+	if paymentDate.Valid {
+		payment.PaymentDate = &paymentDate.Time
+	}
+
+	return &payment, nil
+}
+
+// insertPayment writes one pembayaran row; shared by the inquiry and payment repositories
+func insertPayment(q queryRower, payment *entities.Payment) error {
+	query := `INSERT INTO pembayaran (
+			id_transaksi,
+			tanggal_bayar,
+			jumlah_bayar,
+			metode_bayar,
+			id_metode_pembayaran,
+			nomor_referensi_partner,
+			status_code_partner,
+			status_message_partner,
+			status_pembayaran,
+			catatan,
+			created_at,
+			updated_at
+	) VALUES (?,?,?,?,?,?,?,?,?,?,?,?
+	) RETURNING id_pembayaran`
+
+	query = utils.QuerySupport(query)
+	return q.QueryRow(
+		query,
+		payment.TransactionID,
+		payment.PaymentDate,
+		payment.Amount,
+		payment.Method,
+		payment.PaymentMethodID,
+		payment.PartnerReferenceNo,
+		payment.PartnerStatusCode,
+		payment.PartnerStatusMessage,
+		payment.Status,
+		payment.Note,
+		payment.CreatedAt,
+		payment.UpdatedAt,
+	).Scan(&payment.ID)
+}
+
+func (r *paymentPostgresRepository) BeginTransaction() (*sql.Tx, error) {
+	return r.db.Begin()
+}
+
+func (r *paymentPostgresRepository) CreateWithTx(tx *sql.Tx, payment *entities.Payment) error {
+	return insertPayment(tx, payment)
+}
+
+func (r *paymentPostgresRepository) FindByID(id int) (*entities.Payment, error) {
+	query := `SELECT ` + paymentColumns + `
+		FROM pembayaran p
+		WHERE p.id_pembayaran = $1`
+
+	payment, err := scanPayment(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (r *paymentPostgresRepository) FindByTransactionID(transactionID int) ([]entities.Payment, error) {
+	query := `SELECT ` + paymentColumns + `
+		FROM pembayaran p
+		WHERE p.id_transaksi = $1
+		ORDER BY p.tanggal_bayar, p.id_pembayaran`
+
+	rows, err := r.db.Query(query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return scanPayments(rows)
+}
+
+// FindByTransactionIDForUpdateWithTx reads the pembayaran of a transaksi and locks their rows until tx ends
+func (r *paymentPostgresRepository) FindByTransactionIDForUpdateWithTx(tx *sql.Tx, transactionID int) ([]entities.Payment, error) {
+	query := `SELECT ` + paymentColumns + `
+		FROM pembayaran p
+		WHERE p.id_transaksi = $1
+		ORDER BY p.tanggal_bayar, p.id_pembayaran
+		FOR UPDATE`
+
+	rows, err := tx.Query(query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return scanPayments(rows)
+}
+
+// scanPayments scans and closes rows selected with paymentColumns
+func scanPayments(rows *sql.Rows) ([]entities.Payment, error) {
+	defer rows.Close()
+
+	var payments []entities.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, *payment)
+	}
+
+	return payments, rows.Err()
+}
+
+// FindByReferenceNoWithTx reads the pembayaran a gateway already reported and locks its row until tx ends
+func (r *paymentPostgresRepository) FindByReferenceNoWithTx(tx *sql.Tx, paymentMethodID int, referenceNo string) (*entities.Payment, error) {
+	query := `SELECT ` + paymentColumns + `
+		FROM pembayaran p
+		WHERE p.id_metode_pembayaran = $1 AND p.nomor_referensi_partner = $2
+		ORDER BY p.id_pembayaran DESC
+		LIMIT 1
+		FOR UPDATE`
+
+	payment, err := scanPayment(tx.QueryRow(query, paymentMethodID, referenceNo))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (r *paymentPostgresRepository) UpdateStatusWithTx(tx *sql.Tx, payment *entities.Payment) error {
+	query := `
+		UPDATE pembayaran
+		SET status_pembayaran = $1,
+			jumlah_bayar = $2,
+			status_code_partner = $3,
+			status_message_partner = $4,
+			updated_at = NOW()
+		WHERE id_pembayaran = $5`
+
+	_, err := tx.Exec(query,
+		payment.Status,
+		payment.Amount,
+		payment.PartnerStatusCode,
+		payment.PartnerStatusMessage,
+		payment.ID,
+	)
+	return err
+}
+
+func (r *paymentPostgresRepository) CreateCallbackLog(log *entities.PaymentCallbackLog) error {
+	query := `INSERT INTO callback_pembayaran (
+			id_metode_pembayaran,
+			id_transaksi,
+			nomor_referensi_pembayaran,
+			payload,
+			signature,
+			signature_valid,
+			hasil,
+			pesan,
+			created_at
+	) VALUES (NULLIF(?, 0),NULLIF(?, 0),?,?,?,?,?,?,?
+	) RETURNING id_callback`
+
+	query = utils.QuerySupport(query)
+	return r.db.QueryRow(
+		query,
+		log.PaymentMethodID,
+		log.TransactionID,
+		log.ReferenceNo,
+		log.Payload,
+		log.Signature,
+		log.SignatureValid,
+		log.Result,
+		log.Message,
+		log.CreatedAt,
+	).Scan(&log.ID)
+}
+
+func (r *paymentPostgresRepository) CreateRefundWithTx(tx *sql.Tx, refund *entities.PaymentRefund) error {
+	query := `INSERT INTO refund_pembayaran (
+			id_pembayaran,
+			id_transaksi,
+			nomor_referensi_partner,
+			jumlah_refund,
+			status_refund,
+			alasan,
+			created_at,
+			created_by
+	) VALUES (?,?,?,?,?,?,?,?
+	) RETURNING id_refund`
+
+	query = utils.QuerySupport(query)
+	return tx.QueryRow(
+		query,
+		refund.PaymentID,
+		refund.TransactionID,
+		refund.ReferenceNo,
+		refund.Amount,
+		refund.Status,
+		refund.Reason,
+		refund.CreatedAt,
+		refund.CreatedBy,
+	).Scan(&refund.ID)
+}
+
+// UpdateRefundStatus records the outcome the gateway gave for a pending refund
+func (r *paymentPostgresRepository) UpdateRefundStatus(refund *entities.PaymentRefund) error {
+	query := `
+		UPDATE refund_pembayaran
+		SET status_refund = $1,
+			nomor_referensi_partner = $2
+		WHERE id_refund = $3`
+
+	_, err := r.db.Exec(query, refund.Status, refund.ReferenceNo, refund.ID)
+	return err
+}
+
+func (r *paymentPostgresRepository) FindRefundsByTransactionID(transactionID int) ([]entities.PaymentRefund, error) {
+	query := `SELECT
+			id_refund,
+			id_pembayaran,
+			id_transaksi,
+			COALESCE(nomor_referensi_partner,''),
+			jumlah_refund,
+			status_refund,
+			COALESCE(alasan,''),
+			created_at,
+			COALESCE(created_by,'')
+		FROM refund_pembayaran
+		WHERE id_transaksi = $1
+		ORDER BY id_refund`
+
+	rows, err := r.db.Query(query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []entities.PaymentRefund
+	for rows.Next() {
+		var refund entities.PaymentRefund
+		err := rows.Scan(
+			&refund.ID,
+			&refund.PaymentID,
+			&refund.TransactionID,
+			&refund.ReferenceNo,
+			&refund.Amount,
+			&refund.Status,
+			&refund.Reason,
+			&refund.CreatedAt,
+			&refund.CreatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, rows.Err()
+}
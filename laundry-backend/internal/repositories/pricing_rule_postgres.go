@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"database/sql"
+	"laundry-backend/internal/entities"
+)
+
+type pricingRulePostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPricingRuleRepository(db *sql.DB) PricingRuleRepository {
+	return &pricingRulePostgresRepository{db: db}
+}
+
+// pricingRuleColumns is the column list shared by every aturan_harga SELECT, in scanPricingRule order
+const pricingRuleColumns = `a.id_aturan, a.id_layanan, a.id_outlet, a.harga_satuan, a.kuantitas_minimum, a.pembulatan, a.created_at, a.updated_at`
+
+// scanPricingRule scans a row selected with pricingRuleColumns
+func scanPricingRule(row rowScanner) (*entities.PricingRule, error) {
+	var rule entities.PricingRule
+	var outletID sql.NullInt64
+	var unitPrice sql.NullFloat64
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.ServiceID,
+		&outletID,
+		&unitPrice,
+		&rule.MinimumQuantity,
+		&rule.RoundingStep,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if outletID.Valid {
+		id := int(outletID.Int64)
+		rule.OutletID = &id
+	}
+	if unitPrice.Valid {
+		rule.UnitPrice = &unitPrice.Float64
+	}
+	rule.Tiers = []entities.PriceTier{}
+
+	return &rule, nil
+}
+
+// findTiers fills the tingkat_harga of rules, all rules of serviceID, ordered by kuantitas_dari
+func (r *pricingRulePostgresRepository) findTiers(serviceID int, rules []*entities.PricingRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	index := make(map[int]*entities.PricingRule, len(rules))
+	for _, rule := range rules {
+		index[rule.ID] = rule
+	}
+
+	query := `SELECT t.id_aturan, t.kuantitas_dari, t.harga_satuan
+		FROM tingkat_harga t
+		JOIN aturan_harga a ON a.id_aturan = t.id_aturan
+		WHERE a.id_layanan = $1
+		ORDER BY t.id_aturan, t.kuantitas_dari`
+	rows, err := r.db.Query(query, serviceID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ruleID int
+		var tier entities.PriceTier
+		if err := rows.Scan(&ruleID, &tier.From, &tier.UnitPrice); err != nil {
+			return err
+		}
+		if rule, ok := index[ruleID]; ok {
+			rule.Tiers = append(rule.Tiers, tier)
+		}
+	}
+
+	return rows.Err()
+}
+
+// FindByService returns the rule of the service for every outlet first, then the rules of the single outlets inside scope
+func (r *pricingRulePostgresRepository) FindByService(serviceID int, scope entities.AccessScope) ([]entities.PricingRule, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "a.id_outlet", 2)
+	query := `SELECT ` + pricingRuleColumns + `
+		FROM aturan_harga a
+		WHERE a.id_layanan = $1 AND (a.id_outlet IS NULL OR ` + scopeCond + `)
+		ORDER BY a.id_outlet NULLS FIRST`
+	rows, err := r.db.Query(query, append([]interface{}{serviceID}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []*entities.PricingRule
+	for rows.Next() {
+		rule, err := scanPricingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := r.findTiers(serviceID, found); err != nil {
+		return nil, err
+	}
+	rules := make([]entities.PricingRule, 0, len(found))
+	for _, rule := range found {
+		rules = append(rules, *rule)
+	}
+	return rules, nil
+}
+
+// FindEffective returns the rule of the service at the outlet, falling back to the rule for every outlet;
+// nil when the service has neither
+func (r *pricingRulePostgresRepository) FindEffective(serviceID, outletID int) (*entities.PricingRule, error) {
+	query := `SELECT ` + pricingRuleColumns + `
+		FROM aturan_harga a
+		WHERE a.id_layanan = $1 AND (a.id_outlet = $2 OR a.id_outlet IS NULL)
+		ORDER BY a.id_outlet NULLS LAST
+		LIMIT 1`
+	rule, err := scanPricingRule(r.db.QueryRow(query, serviceID, outletID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := r.findTiers(rule.ServiceID, []*entities.PricingRule{rule}); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *pricingRulePostgresRepository) FindByID(id int) (*entities.PricingRule, error) {
+	query := `SELECT ` + pricingRuleColumns + ` FROM aturan_harga a WHERE a.id_aturan = $1`
+	rule, err := scanPricingRule(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := r.findTiers(rule.ServiceID, []*entities.PricingRule{rule}); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Save creates or replaces the rule of the service at rule.OutletID together with its tiers
+func (r *pricingRulePostgresRepository) Save(rule *entities.PricingRule) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO aturan_harga (id_layanan, id_outlet, harga_satuan, kuantitas_minimum, pembulatan, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		ON CONFLICT (id_layanan, COALESCE(id_outlet, 0)) DO UPDATE
+		SET harga_satuan = EXCLUDED.harga_satuan, kuantitas_minimum = EXCLUDED.kuantitas_minimum,
+			pembulatan = EXCLUDED.pembulatan, updated_at = NOW()
+		RETURNING id_aturan, created_at, updated_at`
+	err = tx.QueryRow(query, rule.ServiceID, rule.OutletID, rule.UnitPrice, rule.MinimumQuantity, rule.RoundingStep).
+		Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM tingkat_harga WHERE id_aturan = $1`, rule.ID); err != nil {
+		return err
+	}
+	for _, tier := range rule.Tiers {
+		_, err := tx.Exec(`INSERT INTO tingkat_harga (id_aturan, kuantitas_dari, harga_satuan) VALUES ($1, $2, $3)`,
+			rule.ID, tier.From, tier.UnitPrice)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *pricingRulePostgresRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM aturan_harga WHERE id_aturan = $1`, id)
+	return err
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"database/sql"
+	"laundry-backend/internal/entities"
+)
+
+type refreshTokenPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenPostgresRepository{db: db}
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func (r *refreshTokenPostgresRepository) BeginTransaction() (*sql.Tx, error) {
+	return r.db.Begin()
+}
+
+func (r *refreshTokenPostgresRepository) Create(token *entities.RefreshToken) error {
+	return insertRefreshToken(r.db, token)
+}
+
+func (r *refreshTokenPostgresRepository) CreateWithTx(tx *sql.Tx, token *entities.RefreshToken) error {
+	return insertRefreshToken(tx, token)
+}
+
+func insertRefreshToken(q queryRower, token *entities.RefreshToken) error {
+	query := `INSERT INTO refresh_token (id_access, token_hash, expired_at, created_at)
+		VALUES ($1, $2, $3, NOW())
+		RETURNING id_refresh_token, created_at`
+
+	return q.QueryRow(query, token.AccessID, token.TokenHash, token.ExpiredAt).Scan(&token.ID, &token.CreatedAt)
+}
+
+// FindByHashForUpdateWithTx reads a refresh token and locks its row until tx ends, so it can only be rotated once
+func (r *refreshTokenPostgresRepository) FindByHashForUpdateWithTx(tx *sql.Tx, tokenHash string) (*entities.RefreshToken, error) {
+	query := `SELECT id_refresh_token, id_access, token_hash, expired_at, revoked_at, replaced_by, created_at
+		FROM refresh_token
+		WHERE token_hash = $1
+		FOR UPDATE`
+
+	var (
+		token      entities.RefreshToken
+		revokedAt  sql.NullTime
+		replacedBy sql.NullInt64
+	)
+	err := tx.QueryRow(query, tokenHash).Scan(
+		&token.ID,
+		&token.AccessID,
+		&token.TokenHash,
+		&token.ExpiredAt,
+		&revokedAt,
+		&replacedBy,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	if replacedBy.Valid {
+		id := int(replacedBy.Int64)
+		token.ReplacedBy = &id
+	}
+
+	return &token, nil
+}
+
+// RevokeWithTx revokes one refresh token; replacedBy is the token issued in its place, nil on logout
+func (r *refreshTokenPostgresRepository) RevokeWithTx(tx *sql.Tx, id int, replacedBy *int) error {
+	query := `UPDATE refresh_token SET revoked_at = NOW(), replaced_by = $1
+		WHERE id_refresh_token = $2 AND revoked_at IS NULL`
+
+	_, err := tx.Exec(query, replacedBy, id)
+	return err
+}
+
+// RevokeByHash revokes the refresh token with the given hash and reports whether it was still active
+func (r *refreshTokenPostgresRepository) RevokeByHash(tokenHash string) (bool, error) {
+	query := `UPDATE refresh_token SET revoked_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, tokenHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *refreshTokenPostgresRepository) RevokeAllByAccessID(accessID int) (int, error) {
+	return revokeAllRefreshTokens(r.db, accessID)
+}
+
+func (r *refreshTokenPostgresRepository) RevokeAllByAccessIDWithTx(tx *sql.Tx, accessID int) (int, error) {
+	return revokeAllRefreshTokens(tx, accessID)
+}
+
+// revokeAllRefreshTokens ends every active session of a user_access and returns how many there were
+func revokeAllRefreshTokens(e execer, accessID int) (int, error) {
+	query := `UPDATE refresh_token SET revoked_at = NOW()
+		WHERE id_access = $1 AND revoked_at IS NULL`
+
+	result, err := e.Exec(query, accessID)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
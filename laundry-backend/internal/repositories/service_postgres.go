@@ -60,13 +60,15 @@ func (r *servicePostgresRepository) FindByID(id int) (*entities.Service, error)
 	return &service, nil
 }
 
-func (r *servicePostgresRepository) FindAll() ([]entities.Service, error) {
+func (r *servicePostgresRepository) FindAll(scope entities.AccessScope) ([]entities.Service, error) {
+	scopeCond, scopeArgs := brandScopeCondition(scope, "l.id_brand", 1)
 	query := `
 		SELECT l.id_layanan, l.id_brand, l.id_kategori, l.nama_layanan, l.deskripsi, l.harga_satuan, l.satuan_durasi, l.durasi_pengerjaan, l.created_at, l.updated_at
 		FROM paket_layanan l
+		WHERE ` + scopeCond + `
 		ORDER BY l.id_layanan`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, scopeArgs...)
 	if err != nil {
 		return nil, err
 	}
@@ -96,10 +98,12 @@ func (r *servicePostgresRepository) FindAll() ([]entities.Service, error) {
 	return services, nil
 }
 
-func (r *servicePostgresRepository) FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Service, int, error) {
-	// Base query
+func (r *servicePostgresRepository) FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string, scope entities.AccessScope) ([]entities.Service, int, error) {
+	// Limit the rows to the brand of the caller
+	scopeCond, args := brandScopeCondition(scope, "l.id_brand", 1)
 	baseQuery := `
-		FROM paket_layanan l`
+		FROM paket_layanan l
+		WHERE ` + scopeCond
 
 	// Count query
 	countQuery := "SELECT COUNT(*) " + baseQuery
@@ -110,10 +114,10 @@ func (r *servicePostgresRepository) FindAllWithPagination(limit, offset int, sea
 		` + baseQuery
 
 	// Search condition
-	var args []interface{}
 	if search != "" {
-		countQuery += " WHERE l.nama_layanan ILIKE $1"
-		dataQuery += " WHERE l.nama_layanan ILIKE $1"
+		searchCond := fmt.Sprintf(" AND l.nama_layanan ILIKE $%d", len(args)+1)
+		countQuery += searchCond
+		dataQuery += searchCond
 		args = append(args, "%"+search+"%")
 	}
 
@@ -185,14 +189,15 @@ func (r *servicePostgresRepository) Delete(id int) error {
 	return err
 }
 
-func (r *servicePostgresRepository) FindByCategoryID(categoryID int) ([]entities.Service, error) {
+func (r *servicePostgresRepository) FindByCategoryID(categoryID int, scope entities.AccessScope) ([]entities.Service, error) {
+	scopeCond, scopeArgs := brandScopeCondition(scope, "l.id_brand", 2)
 	query := `
 		SELECT l.id_layanan, l.id_brand, l.id_kategori, l.nama_layanan, l.deskripsi, l.harga_satuan, l.satuan_durasi, l.durasi_pengerjaan, l.created_at, l.updated_at
 		FROM paket_layanan l
-		WHERE l.id_kategori = $1
+		WHERE l.id_kategori = $1 AND ` + scopeCond + `
 		ORDER BY l.id_layanan`
 
-	rows, err := r.db.Query(query, categoryID)
+	rows, err := r.db.Query(query, append([]interface{}{categoryID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
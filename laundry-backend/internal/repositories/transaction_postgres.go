@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/utils"
+	"time"
 )
 
 type transactionPostgresRepository struct {
@@ -16,128 +18,161 @@ func NewTransactionRepository(db *sql.DB) TransactionRepository {
 	}
 }
 
-func (r *transactionPostgresRepository) FindAll() ([]entities.Transaction, error) {
-	query := `
-		SELECT 
+// transactionColumns is the column list shared by every transaksi SELECT, in scanTransaction order
+const transactionColumns = `
 		t.id_transaksi,
 		t.id_pelanggan,
 		t.id_outlet,
 		t.id_access,
-		t.nomor_invoice,			 
+		t.nomor_invoice,
 		t.tanggal_masuk,
 		t.tanggal_selesai,
-		t.tanggal_diambil,			 
+		t.tanggal_diambil,
+		t.estimasi_selesai,
 		t.total_harga,
+		COALESCE(t.subtotal_layanan, t.total_harga, 0),
+		COALESCE(t.biaya_admin, 0),
+		COALESCE(t.biaya_merchant, 0),
+		COALESCE(t.biaya_ditanggung, 'customer'),
 		t.uang_bayar,
 		t.uang_kembalian,
-		t.status_transaksi,			
+		t.status_transaksi,
+		COALESCE((SELECT SUM(p.jumlah_bayar) FROM pembayaran p
+			WHERE p.id_transaksi = t.id_transaksi AND p.status_pembayaran = 'sukses'), 0),
 		COALESCE(t.catatan,''),
 		t.created_at,
-		t.updated_at,			
+		t.updated_at,
 		t.created_by,
-		t.updated_by
-		FROM transaksi t
-		ORDER BY t.id_transaksi`
+		t.updated_by`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTransaction scans a row selected with transactionColumns
+func scanTransaction(row rowScanner) (*entities.Transaction, error) {
+	var transaction entities.Transaction
+	var userID sql.NullInt64
+	var entryDate, completionDate, pickupDate, estimatedReady sql.NullTime
+	var totalPrice, paidAmount, changeAmount sql.NullFloat64
+	var createdBy, updatedBy sql.NullString
 
-	rows, err := r.db.Query(query)
+	err := row.Scan(
+		&transaction.ID,
+		&transaction.CustomerID,
+		&transaction.OutletID,
+		&userID,
+		&transaction.InvoiceNumber,
+		&entryDate,
+		&completionDate,
+		&pickupDate,
+		&estimatedReady,
+		&totalPrice,
+		&transaction.ServiceSubtotal,
+		&transaction.AdminFee,
+		&transaction.MerchantFee,
+		&transaction.FeeBearer,
+		&paidAmount,
+		&changeAmount,
+		&transaction.Status,
+		&transaction.TotalPaid,
+		&transaction.Note,
+		&transaction.CreatedAt,
+		&transaction.UpdatedAt,
+		&createdBy,
+		&updatedBy,
+	)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
+	// Handle nullable fields
+	if userID.Valid {
+		val := int(userID.Int64)
+		transaction.UserID = &val
+	}
+	if entryDate.Valid {
+		transaction.EntryDate = &entryDate.Time
+	}
+	if completionDate.Valid {
+		transaction.CompletionDate = &completionDate.Time
+	}
+	if pickupDate.Valid {
+		transaction.PickupDate = &pickupDate.Time
+	}
+	if estimatedReady.Valid {
+		transaction.EstimatedReady = &estimatedReady.Time
+	}
+	transaction.TotalPrice = totalPrice.Float64
+	transaction.PaidAmount = paidAmount.Float64
+	transaction.ChangeAmount = changeAmount.Float64
+	// Paid/unpaid is derived from the sum of successful pembayaran rows
+	if transaction.TotalPaid < transaction.TotalPrice {
+		transaction.Outstanding = transaction.TotalPrice - transaction.TotalPaid
+	}
+	if transaction.Outstanding == 0 {
+		transaction.PaymentStatus = entities.PaymentStatusPaid
+	} else {
+		transaction.PaymentStatus = entities.PaymentStatusUnpaid
+	}
+	if createdBy.Valid {
+		transaction.CreatedBy = &createdBy.String
+	}
+	if updatedBy.Valid {
+		transaction.UpdatedBy = &updatedBy.String
+	}
+
+	return &transaction, nil
+}
+
+// scanTransactions scans every row selected with transactionColumns
+func scanTransactions(rows *sql.Rows) ([]entities.Transaction, error) {
 	var transactions []entities.Transaction
 	for rows.Next() {
-		var transaction entities.Transaction
-		var userID sql.NullInt64
-		var entryDate, completionDate, pickupDate sql.NullTime
-		var createdBy, updatedBy sql.NullString
-
-		err := rows.Scan(
-			&transaction.ID,
-			&transaction.CustomerID,
-			&transaction.OutletID,
-			&userID,
-			&transaction.InvoiceNumber,
-			&entryDate,
-			&completionDate,
-			&pickupDate,
-			&transaction.TotalPrice,
-			&transaction.PaidAmount,
-			&transaction.ChangeAmount,
-			&transaction.Status,
-			&transaction.Note,
-			&transaction.CreatedAt,
-			&transaction.UpdatedAt,
-			&createdBy,
-			&updatedBy,
-		)
+		transaction, err := scanTransaction(rows)
 		if err != nil {
 			return nil, err
 		}
+		transactions = append(transactions, *transaction)
+	}
 
-		// Handle nullable fields
-		if userID.Valid {
-			val := int(userID.Int64)
-			transaction.UserID = &val
-		}
-		if entryDate.Valid {
-			transaction.EntryDate = &entryDate.Time
-		}
-		if completionDate.Valid {
-			transaction.CompletionDate = &completionDate.Time
-		}
-		if pickupDate.Valid {
-			transaction.PickupDate = &pickupDate.Time
-		}
+	return transactions, rows.Err()
+}
 
-		if createdBy.Valid {
-			transaction.CreatedBy = &createdBy.String
-		}
-		if updatedBy.Valid {
-			transaction.UpdatedBy = &updatedBy.String
-		}
+func (r *transactionPostgresRepository) FindAll(scope entities.AccessScope) ([]entities.Transaction, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "t.id_outlet", 1)
+	query := `SELECT ` + transactionColumns + `
+		FROM transaksi t
+		WHERE ` + scopeCond + `
+		ORDER BY t.id_transaksi`
 
-		transactions = append(transactions, transaction)
+	rows, err := r.db.Query(query, scopeArgs...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return transactions, nil
+	return scanTransactions(rows)
 }
 
-func (r *transactionPostgresRepository) FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string) ([]entities.Transaction, int, error) {
-	// Base query
+func (r *transactionPostgresRepository) FindAllWithPagination(limit, offset int, search string, orderBy string, orderDir string, scope entities.AccessScope) ([]entities.Transaction, int, error) {
+	// Base query, limited to the caller's part of the hierarchy
+	scopeCond, args := outletScopeCondition(scope, "t.id_outlet", 1)
 	baseQuery := `
-		FROM transaksi t`
+		FROM transaksi t
+		WHERE ` + scopeCond
 
 	// Count query
 	countQuery := "SELECT COUNT(*) " + baseQuery
 
 	// Data query
-	dataQuery := `
-		SELECT 
-		t.id_transaksi,
-		t.id_pelanggan,
-		t.id_outlet,
-		t.id_access,
-		t.nomor_invoice,			 
-		t.tanggal_masuk,
-		t.tanggal_selesai,
-		t.tanggal_diambil,			 
-		t.total_harga,
-		t.uang_bayar,
-		t.uang_kembalian,
-		t.status_transaksi,			
-		COALESCE(t.catatan,''),
-		t.created_at,
-		t.updated_at,			
-		t.created_by,
-		t.updated_by
-		` + baseQuery
+	dataQuery := `SELECT ` + transactionColumns + baseQuery
 
 	// Search condition
-	var args []interface{}
 	if search != "" {
-		countQuery += " WHERE t.nomor_invoice ILIKE $1"
-		dataQuery += " WHERE t.nomor_invoice ILIKE $1"
+		countQuery += fmt.Sprintf(" AND t.nomor_invoice ILIKE $%d", len(args)+1)
+		dataQuery += fmt.Sprintf(" AND t.nomor_invoice ILIKE $%d", len(args)+1)
 		args = append(args, "%"+search+"%")
 	}
 
@@ -169,110 +204,20 @@ func (r *transactionPostgresRepository) FindAllWithPagination(limit, offset int,
 	}
 	defer rows.Close()
 
-	var transactions []entities.Transaction
-	for rows.Next() {
-		var transaction entities.Transaction
-		var userID sql.NullInt64
-		var entryDate, completionDate, pickupDate sql.NullTime
-		var createdBy, updatedBy sql.NullString
-
-		err := rows.Scan(
-			&transaction.ID,
-			&transaction.CustomerID,
-			&transaction.OutletID,
-			&userID,
-			&transaction.InvoiceNumber,
-			&entryDate,
-			&completionDate,
-			&pickupDate,
-			&transaction.TotalPrice,
-			&transaction.PaidAmount,
-			&transaction.ChangeAmount,
-			&transaction.Status,
-			&transaction.Note,
-			&transaction.CreatedAt,
-			&transaction.UpdatedAt,
-			&createdBy,
-			&updatedBy,
-		)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		// Handle nullable fields
-		if userID.Valid {
-			val := int(userID.Int64)
-			transaction.UserID = &val
-		}
-		if entryDate.Valid {
-			transaction.EntryDate = &entryDate.Time
-		}
-		if completionDate.Valid {
-			transaction.CompletionDate = &completionDate.Time
-		}
-		if pickupDate.Valid {
-			transaction.PickupDate = &pickupDate.Time
-		}
-		if createdBy.Valid {
-			transaction.CreatedBy = &createdBy.String
-		}
-		if updatedBy.Valid {
-			transaction.UpdatedBy = &updatedBy.String
-		}
-
-		transactions = append(transactions, transaction)
+	transactions, err := scanTransactions(rows)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	return transactions, totalCount, nil
 }
 
 func (r *transactionPostgresRepository) FindByID(id int) (*entities.Transaction, error) {
-	query := `
-		SELECT 
-			t.id_transaksi,
-		t.id_pelanggan,
-		t.id_outlet,
-		t.id_access,
-		t.nomor_invoice,			 
-		t.tanggal_masuk,
-		t.tanggal_selesai,
-		t.tanggal_diambil,			 
-		t.total_harga,
-		t.uang_bayar,
-		t.uang_kembalian,
-		t.status_transaksi,			
-		COALESCE(t.catatan,''),
-		t.created_at,
-		t.updated_at,			
-		t.created_by,
-		t.updated_by
+	query := `SELECT ` + transactionColumns + `
 		FROM transaksi t
 		WHERE t.id_transaksi = $1`
 
-	var transaction entities.Transaction
-	var userID sql.NullInt64
-	var entryDate, completionDate, pickupDate sql.NullTime
-	var createdBy, updatedBy sql.NullString
-
-	err := r.db.QueryRow(query, id).Scan(
-		&transaction.ID,
-		&transaction.CustomerID,
-		&transaction.OutletID,
-		&userID,
-		&transaction.InvoiceNumber,
-		&entryDate,
-		&completionDate,
-		&pickupDate,
-		&transaction.TotalPrice,
-		&transaction.PaidAmount,
-		&transaction.ChangeAmount,
-		&transaction.Status,
-		&transaction.Note,
-		&transaction.CreatedAt,
-		&transaction.UpdatedAt,
-		&createdBy,
-		&updatedBy,
-	)
+	transaction, err := scanTransaction(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -280,116 +225,51 @@ func (r *transactionPostgresRepository) FindByID(id int) (*entities.Transaction,
 		return nil, err
 	}
 
-	// Handle nullable fields
-	if userID.Valid {
-		val := int(userID.Int64)
-		transaction.UserID = &val
-	}
-	if entryDate.Valid {
-		transaction.EntryDate = &entryDate.Time
-	}
-	if completionDate.Valid {
-		transaction.CompletionDate = &completionDate.Time
-	}
-	if pickupDate.Valid {
-		transaction.PickupDate = &pickupDate.Time
-	}
+	return transaction, nil
+}
 
-	if createdBy.Valid {
-		transaction.CreatedBy = &createdBy.String
-	}
-	if updatedBy.Valid {
-		transaction.UpdatedBy = &updatedBy.String
-	}
+// ExistsInScope reports whether the transaksi belongs to an outlet inside the caller's part of the hierarchy
+func (r *transactionPostgresRepository) ExistsInScope(id int, scope entities.AccessScope) (bool, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "t.id_outlet", 2)
+	query := `SELECT EXISTS (SELECT 1 FROM transaksi t WHERE t.id_transaksi = $1 AND ` + scopeCond + `)`
 
-	return &transaction, nil
+	var exists bool
+	err := r.db.QueryRow(query, append([]interface{}{id}, scopeArgs...)...).Scan(&exists)
+	return exists, err
 }
 
-func (r *transactionPostgresRepository) FindByOutletID(outletID int) ([]entities.Transaction, error) {
-	query := `
-		SELECT 
-			t.id_transaksi,
-		t.id_pelanggan,
-		t.id_outlet,
-		t.id_access,
-		t.nomor_invoice,			 
-		t.tanggal_masuk,
-		t.tanggal_selesai,
-		t.tanggal_diambil,			 
-		t.total_harga,
-		t.uang_bayar,
-		t.uang_kembalian,
-		t.status_transaksi,			
-		COALESCE(t.catatan,''),
-		t.created_at,
-		t.updated_at,			
-		t.created_by,
-		t.updated_by
+// FindByIDForUpdateWithTx reads a transaction and locks its row until tx ends
+func (r *transactionPostgresRepository) FindByIDForUpdateWithTx(tx *sql.Tx, id int) (*entities.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
 		FROM transaksi t
-		WHERE t.id_outlet = $1
-		ORDER BY t.id_transaksi`
+		WHERE t.id_transaksi = $1
+		FOR UPDATE`
 
-	rows, err := r.db.Query(query, outletID)
+	transaction, err := scanTransaction(tx.QueryRow(query, id))
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
-	defer rows.Close()
-
-	var transactions []entities.Transaction
-	for rows.Next() {
-		var transaction entities.Transaction
-		var userID sql.NullInt64
-		var entryDate, completionDate, pickupDate sql.NullTime
-		var createdBy, updatedBy sql.NullString
 
-		err := rows.Scan(
-			&transaction.ID,
-			&transaction.CustomerID,
-			&transaction.OutletID,
-			&userID,
-			&transaction.InvoiceNumber,
-			&entryDate,
-			&completionDate,
-			&pickupDate,
-			&transaction.TotalPrice,
-			&transaction.PaidAmount,
-			&transaction.ChangeAmount,
-			&transaction.Status,
-			&transaction.Note,
-			&transaction.CreatedAt,
-			&transaction.UpdatedAt,
-			&createdBy,
-			&updatedBy,
-		)
-		if err != nil {
-			return nil, err
-		}
+	return transaction, nil
+}
 
-		// Handle nullable fields
-		if userID.Valid {
-			val := int(userID.Int64)
-			transaction.UserID = &val
-		}
-		if entryDate.Valid {
-			transaction.EntryDate = &entryDate.Time
-		}
-		if completionDate.Valid {
-			transaction.CompletionDate = &completionDate.Time
-		}
-		if pickupDate.Valid {
-			transaction.PickupDate = &pickupDate.Time
-		}
-		if createdBy.Valid {
-			transaction.CreatedBy = &createdBy.String
-		}
-		if updatedBy.Valid {
-			transaction.UpdatedBy = &updatedBy.String
-		}
+func (r *transactionPostgresRepository) FindByOutletID(outletID int, scope entities.AccessScope) ([]entities.Transaction, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "t.id_outlet", 2)
+	query := `SELECT ` + transactionColumns + `
+		FROM transaksi t
+		WHERE t.id_outlet = $1 AND ` + scopeCond + `
+		ORDER BY t.id_transaksi`
 
-		transactions = append(transactions, transaction)
+	rows, err := r.db.Query(query, append([]interface{}{outletID}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return transactions, nil
+	return scanTransactions(rows)
 }
 
 func (r *transactionPostgresRepository) FindDetailsByTransactionID(transactionID int) ([]entities.TransactionDetail, error) {
@@ -399,9 +279,14 @@ func (r *transactionPostgresRepository) FindDetailsByTransactionID(transactionID
 			td.id_transaksi,
 			td.id_layanan,
 			td.kuantitas,
+			COALESCE(td.kuantitas_ditagih, td.kuantitas),
 			td.harga_satuan,
+			COALESCE(td.harga_dasar, td.subtotal),
+			td.total_biaya_tambahan,
 			td.subtotal,
+			td.estimasi_selesai,
 			td.status_pengerjaan,
+			COALESCE(td.catatan,''),
 			td.created_at,
 			td.updated_at,
 			td.created_by,
@@ -419,7 +304,8 @@ func (r *transactionPostgresRepository) FindDetailsByTransactionID(transactionID
 	var details []entities.TransactionDetail
 	for rows.Next() {
 		var detail entities.TransactionDetail
-		var quantity, price, subtotal sql.NullFloat64
+		var quantity, chargedQuantity, price, basePrice, subtotal sql.NullFloat64
+		var estimatedReady sql.NullTime
 		var createdBy, updatedBy sql.NullString
 
 		err := rows.Scan(
@@ -427,9 +313,14 @@ func (r *transactionPostgresRepository) FindDetailsByTransactionID(transactionID
 			&detail.TransactionID,
 			&detail.ServiceID,
 			&quantity,
+			&chargedQuantity,
 			&price,
+			&basePrice,
+			&detail.SurchargeTotal,
 			&subtotal,
+			&estimatedReady,
 			&detail.Status,
+			&detail.Note,
 			&detail.CreatedAt,
 			&detail.UpdatedAt,
 			&createdBy,
@@ -443,12 +334,21 @@ func (r *transactionPostgresRepository) FindDetailsByTransactionID(transactionID
 		if quantity.Valid {
 			detail.Quantity = &quantity.Float64
 		}
+		if chargedQuantity.Valid {
+			detail.ChargedQuantity = &chargedQuantity.Float64
+		}
 		if price.Valid {
 			detail.Price = &price.Float64
 		}
+		if basePrice.Valid {
+			detail.BasePrice = &basePrice.Float64
+		}
 		if subtotal.Valid {
 			detail.Subtotal = &subtotal.Float64
 		}
+		if estimatedReady.Valid {
+			detail.EstimatedReady = &estimatedReady.Time
+		}
 		if createdBy.Valid {
 			detail.CreatedBy = &createdBy.String
 		}
@@ -458,54 +358,255 @@ func (r *transactionPostgresRepository) FindDetailsByTransactionID(transactionID
 
 		details = append(details, detail)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
+	if err := r.attachDetailModifiers(transactionID, details); err != nil {
+		return nil, err
+	}
 	return details, nil
 }
 
-func (r *transactionPostgresRepository) UpdateTransactionStatus(id int, status string) error {
+// attachDetailModifiers fills the Modifiers of details, the lines of one transaksi
+func (r *transactionPostgresRepository) attachDetailModifiers(transactionID int, details []entities.TransactionDetail) error {
 	query := `
-		UPDATE transaksi
-		SET status_transaksi = $1, updated_at = NOW()
-		WHERE id_transaksi = $2`
+		SELECT m.id, m.id_detail, m.id_modifier, m.nama, m.jenis, m.tipe_nilai, m.nilai, m.kuantitas, m.jumlah
+		FROM detail_transaksi_modifier m
+		JOIN detail_transaksi td ON td.id_detail = m.id_detail
+		WHERE td.id_transaksi = $1
+		ORDER BY m.id`
 
-	_, err := r.db.Exec(query, status, id)
-	return err
+	rows, err := r.db.Query(query, transactionID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := make(map[int]int, len(details))
+	for i := range details {
+		details[i].Modifiers = []entities.TransactionDetailModifier{}
+		index[details[i].ID] = i
+	}
+	for rows.Next() {
+		var modifier entities.TransactionDetailModifier
+		var modifierID sql.NullInt64
+		err := rows.Scan(
+			&modifier.ID,
+			&modifier.DetailID,
+			&modifierID,
+			&modifier.Name,
+			&modifier.Type,
+			&modifier.ValueType,
+			&modifier.Value,
+			&modifier.Quantity,
+			&modifier.Amount,
+		)
+		if err != nil {
+			return err
+		}
+		if modifierID.Valid {
+			id := int(modifierID.Int64)
+			modifier.ModifierID = &id
+		}
+		if i, ok := index[modifier.DetailID]; ok {
+			details[i].Modifiers = append(details[i].Modifiers, modifier)
+		}
+	}
+
+	return rows.Err()
 }
 
-// ////perlu update
-func (r *transactionPostgresRepository) UpdatePaymentStatus(id int, status string) error {
+func (r *transactionPostgresRepository) FindHistoryByTransactionID(transactionID int) ([]entities.HistoryStatusTransaction, error) {
 	query := `
-		UPDATE transaksi
-		SET status_pembayaran = $1, updated_at = NOW()
-		WHERE id_transaksi = $2`
+		SELECT
+			h.id_history,
+			h.id_transaksi,
+			COALESCE(h.status_lama,''),
+			h.status_baru,
+			h.waktu_perubahan,
+			COALESCE(h.keterangan,''),
+			COALESCE(h.diubah_oleh,''),
+			h.created_at,
+			h.updated_at
+		FROM history_status_transaksi h
+		WHERE h.id_transaksi = $1
+		ORDER BY h.waktu_perubahan, h.id_history`
 
-	_, err := r.db.Exec(query, status, id)
-	return err
+	rows, err := r.db.Query(query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var histories []entities.HistoryStatusTransaction
+	for rows.Next() {
+		var history entities.HistoryStatusTransaction
+		var changeTime sql.NullTime
+
+		err := rows.Scan(
+			&history.ID,
+			&history.TransactionID,
+			&history.OldStatus,
+			&history.NewStatus,
+			&changeTime,
+			&history.Description,
+			&history.ChangedBy,
+			&history.CreatedAt,
+			&history.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Handle nullable fields
+		if changeTime.Valid {
+			history.ChangeTime = &changeTime.Time
+		} else {
+			history.ChangeTime = &history.CreatedAt
+		}
+
+		histories = append(histories, history)
+	}
+
+	return histories, rows.Err()
+}
+
+func (r *transactionPostgresRepository) BeginTransaction() (*sql.Tx, error) {
+	return r.db.Begin()
 }
 
-func (r *transactionPostgresRepository) UpdatePaymentCallback(transactionID int, request entities.PaymentCallbackRequest) error {
+func (r *transactionPostgresRepository) UpdateTransactionStatusWithTx(tx *sql.Tx, transaction *entities.Transaction) error {
 	query := `
 		UPDATE transaksi
-		SET 
-			status_pembayaran = $1,
-			metode_pembayaran = $2,
-			nomor_referensi_pembayaran = $3,
-			uang_bayar = $4,
-			uang_kembalian = $5,
-			status_kode = $6,
-			status_pesan = $7,
-			updated_at = NOW()
-		WHERE id_transaksi = $8`
-
-	_, err := r.db.Exec(query,
-		request.PaymentStatus,
-		request.PaymentMethod,
-		request.PaymentReferenceNumber,
-		request.PaidAmount,
-		request.ChangeAmount,
-		request.StatusCode,
-		request.StatusMessage,
-		transactionID,
+		SET status_transaksi = $1,
+			tanggal_selesai = $2,
+			tanggal_diambil = $3,
+			updated_at = $4,
+			updated_by = $5
+		WHERE id_transaksi = $6`
+
+	_, err := tx.Exec(query,
+		transaction.Status,
+		transaction.CompletionDate,
+		transaction.PickupDate,
+		transaction.UpdatedAt,
+		transaction.UpdatedBy,
+		transaction.ID,
 	)
 	return err
 }
+
+// GetRevenueSummary sums the transaksi entered in [from, to); outletID 0 means every outlet inside scope.
+// Cancelled orders only add to the cancelled count and refund total, never to sales or collections.
+// An order collects at most its total_harga; change handed back to the customer was never kept.
+func (r *transactionPostgresRepository) GetRevenueSummary(outletID int, from, to time.Time, scope entities.AccessScope) (*entities.RevenueReport, error) {
+	scopeCond, scopeArgs := outletScopeCondition(scope, "t.id_outlet", 4)
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE t.status_transaksi <> 'dibatalkan'),
+			COALESCE(SUM(t.total_harga) FILTER (WHERE t.status_transaksi <> 'dibatalkan'), 0),
+			COALESCE(SUM(lines.base) FILTER (WHERE t.status_transaksi <> 'dibatalkan'), 0),
+			COALESCE(SUM(lines.surcharge) FILTER (WHERE t.status_transaksi <> 'dibatalkan'), 0),
+			COALESCE(SUM(LEAST(COALESCE(paid.amount, 0), COALESCE(t.total_harga, 0))) FILTER (WHERE t.status_transaksi <> 'dibatalkan'), 0),
+			COALESCE(SUM(GREATEST(t.total_harga - COALESCE(paid.amount, 0), 0)) FILTER (WHERE t.status_transaksi <> 'dibatalkan'), 0),
+			COUNT(*) FILTER (WHERE t.status_transaksi = 'dibatalkan'),
+			COALESCE(SUM(refunded.amount), 0)
+		FROM transaksi t
+		LEFT JOIN LATERAL (
+			SELECT SUM(COALESCE(td.harga_dasar, td.subtotal)) AS base, SUM(td.total_biaya_tambahan) AS surcharge
+			FROM detail_transaksi td
+			WHERE td.id_transaksi = t.id_transaksi
+		) lines ON true
+		LEFT JOIN LATERAL (
+			SELECT SUM(p.jumlah_bayar) AS amount FROM pembayaran p
+			WHERE p.id_transaksi = t.id_transaksi AND p.status_pembayaran = 'sukses'
+		) paid ON true
+		LEFT JOIN LATERAL (
+			SELECT SUM(rp.jumlah_refund) AS amount FROM refund_pembayaran rp
+			WHERE rp.id_transaksi = t.id_transaksi AND rp.status_refund = 'sukses'
+		) refunded ON true
+		WHERE t.tanggal_masuk >= $1 AND t.tanggal_masuk < $2
+			AND ($3 = 0 OR t.id_outlet = $3)
+			AND ` + scopeCond
+
+	report := entities.RevenueReport{
+		OutletID: outletID,
+		From:     from,
+		To:       to,
+	}
+	err := r.db.QueryRow(query, append([]interface{}{from, to, outletID}, scopeArgs...)...).Scan(
+		&report.TransactionCount,
+		&report.GrossSales,
+		&report.BaseSales,
+		&report.SurchargeSales,
+		&report.TotalCollected,
+		&report.Outstanding,
+		&report.CancelledCount,
+		&report.TotalRefunded,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// UpdatePaidAmountWithTx recalculates uang_bayar and uang_kembalian from the successful pembayaran rows
+func (r *transactionPostgresRepository) UpdatePaidAmountWithTx(tx *sql.Tx, transactionID int) error {
+	query := `
+		UPDATE transaksi t
+		SET uang_bayar = s.paid,
+			uang_kembalian = GREATEST(s.paid - COALESCE(t.total_harga, 0), 0),
+			updated_at = NOW()
+		FROM (
+			SELECT COALESCE(SUM(p.jumlah_bayar), 0) AS paid
+			FROM pembayaran p
+			WHERE p.id_transaksi = $1 AND p.status_pembayaran = 'sukses'
+		) s
+		WHERE t.id_transaksi = $1`
+
+	_, err := tx.Exec(query, transactionID)
+	return err
+}
+
+func (r *transactionPostgresRepository) InsertHistoryStatusTransactionWithTx(tx *sql.Tx, history *entities.HistoryStatusTransaction) error {
+	return insertHistoryStatusTransaction(tx, history)
+}
+
+// insertHistoryStatusTransaction writes one history_status_transaksi row; shared by the inquiry and transaction repositories
+func insertHistoryStatusTransaction(tx *sql.Tx, history *entities.HistoryStatusTransaction) error {
+	query := `INSERT INTO history_status_transaksi (
+			id_transaksi,
+			status_lama,
+			status_baru,
+			waktu_perubahan,
+			keterangan,
+			diubah_oleh,
+			created_at,
+			updated_at
+	) VALUES (?,?,?,?,?,?,?,?
+	) RETURNING id_history`
+
+	var id int
+	query = utils.QuerySupport(query)
+	err := tx.QueryRow(
+		query,
+		history.TransactionID,
+		history.OldStatus,
+		history.NewStatus,
+		history.ChangeTime,
+		history.Description,
+		history.ChangedBy,
+		history.CreatedAt,
+		history.UpdatedAt,
+	).Scan(&id)
+
+	if err != nil {
+		return err
+	}
+
+	history.ID = id
+
+	return nil
+}
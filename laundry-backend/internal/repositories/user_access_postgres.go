@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"laundry-backend/internal/entities"
 	"log"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -26,12 +27,18 @@ func (r *userAccessPostgresRepository) Create(access *entities.UserAccess) error
 		return err
 	}
 
+	// The first password is recorded in password_history by the same statement
 	query := `
-		INSERT INTO user_access (username, password, role, is_active, reference_level, reference_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		WITH created AS (
+			INSERT INTO user_access (username, password, role, is_active, reference_level, reference_id, must_change_password, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+			RETURNING id_access, password
+		)
+		INSERT INTO password_history (id_access, password_hash, created_at)
+		SELECT id_access, password, NOW() FROM created
 		RETURNING id_access`
 
-	err = r.db.QueryRow(query, access.Username, hashedPassword, access.Role, access.IsActive, access.ReferenceLevel, access.ReferenceID).
+	err = r.db.QueryRow(query, access.Username, hashedPassword, access.Role, access.IsActive, access.ReferenceLevel, access.ReferenceID, access.MustChangePassword).
 		Scan(&access.ID)
 	if err != nil {
 		return err
@@ -44,12 +51,12 @@ func (r *userAccessPostgresRepository) Create(access *entities.UserAccess) error
 func (r *userAccessPostgresRepository) FindByID(id int) (*entities.UserAccess, error) {
 	query := `
 		SELECT id_access, username, role, is_active, last_login, 
-		       COALESCE(reference_level,''), COALESCE(reference_id,0), created_at, updated_at
+		       COALESCE(reference_level,''), COALESCE(reference_id,0), failed_login_attempts, locked_until, must_change_password, created_at, updated_at
 		FROM user_access
 		WHERE id_access = $1`
 
 	var access entities.UserAccess
-	var lastLogin sql.NullTime
+	var lastLogin, lockedUntil sql.NullTime
 	err := r.db.QueryRow(query, id).Scan(
 		&access.ID,
 		&access.Username,
@@ -58,6 +65,9 @@ func (r *userAccessPostgresRepository) FindByID(id int) (*entities.UserAccess, e
 		&lastLogin,
 		&access.ReferenceLevel,
 		&access.ReferenceID,
+		&access.FailedLoginAttempts,
+		&lockedUntil,
+		&access.MustChangePassword,
 		&access.CreatedAt,
 		&access.UpdatedAt,
 	)
@@ -71,6 +81,9 @@ func (r *userAccessPostgresRepository) FindByID(id int) (*entities.UserAccess, e
 	if lastLogin.Valid {
 		access.LastLogin = &lastLogin.Time
 	}
+	if lockedUntil.Valid {
+		access.LockedUntil = &lockedUntil.Time
+	}
 
 	return &access, nil
 }
@@ -78,12 +91,12 @@ func (r *userAccessPostgresRepository) FindByID(id int) (*entities.UserAccess, e
 func (r *userAccessPostgresRepository) FindByUsername(username string) (*entities.UserAccess, error) {
 	query := `
 		SELECT id_access, username, password, role, is_active, 
-		       last_login, COALESCE(reference_level,''), COALESCE(reference_id,0), created_at, updated_at
+		       last_login, COALESCE(reference_level,''), COALESCE(reference_id,0), failed_login_attempts, locked_until, must_change_password, created_at, updated_at
 		FROM user_access
 		WHERE username = $1 AND is_active = true`
 
 	var access entities.UserAccess
-	var lastLogin sql.NullTime
+	var lastLogin, lockedUntil sql.NullTime
 	var password string
 	err := r.db.QueryRow(query, username).Scan(
 		&access.ID,
@@ -94,6 +107,9 @@ func (r *userAccessPostgresRepository) FindByUsername(username string) (*entitie
 		&lastLogin,
 		&access.ReferenceLevel,
 		&access.ReferenceID,
+		&access.FailedLoginAttempts,
+		&lockedUntil,
+		&access.MustChangePassword,
 		&access.CreatedAt,
 		&access.UpdatedAt,
 	)
@@ -107,6 +123,9 @@ func (r *userAccessPostgresRepository) FindByUsername(username string) (*entitie
 	if lastLogin.Valid {
 		access.LastLogin = &lastLogin.Time
 	}
+	if lockedUntil.Valid {
+		access.LockedUntil = &lockedUntil.Time
+	}
 
 	// Set the password for authentication purposes
 	access.Password = password
@@ -114,14 +133,16 @@ func (r *userAccessPostgresRepository) FindByUsername(username string) (*entitie
 	return &access, nil
 }
 
-func (r *userAccessPostgresRepository) FindAll() ([]entities.UserAccess, error) {
+func (r *userAccessPostgresRepository) FindAll(scope entities.AccessScope) ([]entities.UserAccess, error) {
+	scopeCond, scopeArgs := userAccessScopeCondition(scope, 1)
 	query := `
 		SELECT id_access, username, role, is_active, last_login, 
-		       COALESCE(reference_level,''), COALESCE(reference_id,0), created_at, updated_at
-		FROM user_access
+		       COALESCE(reference_level,''), COALESCE(reference_id,0), failed_login_attempts, locked_until, must_change_password, created_at, updated_at
+		FROM user_access ua
+		WHERE ` + scopeCond + `
 		ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, scopeArgs...)
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +151,7 @@ func (r *userAccessPostgresRepository) FindAll() ([]entities.UserAccess, error)
 	var accesses []entities.UserAccess
 	for rows.Next() {
 		var access entities.UserAccess
-		var lastLogin sql.NullTime
+		var lastLogin, lockedUntil sql.NullTime
 		err := rows.Scan(
 			&access.ID,
 			&access.Username,
@@ -139,6 +160,9 @@ func (r *userAccessPostgresRepository) FindAll() ([]entities.UserAccess, error)
 			&lastLogin,
 			&access.ReferenceLevel,
 			&access.ReferenceID,
+			&access.FailedLoginAttempts,
+			&lockedUntil,
+			&access.MustChangePassword,
 			&access.CreatedAt,
 			&access.UpdatedAt,
 		)
@@ -149,6 +173,9 @@ func (r *userAccessPostgresRepository) FindAll() ([]entities.UserAccess, error)
 		if lastLogin.Valid {
 			access.LastLogin = &lastLogin.Time
 		}
+		if lockedUntil.Valid {
+			access.LockedUntil = &lockedUntil.Time
+		}
 
 		accesses = append(accesses, access)
 	}
@@ -156,12 +183,16 @@ func (r *userAccessPostgresRepository) FindAll() ([]entities.UserAccess, error)
 	return accesses, nil
 }
 
-func (r *userAccessPostgresRepository) FindAllWithPagination(limit, offset int) ([]entities.UserAccess, int, error) {
+func (r *userAccessPostgresRepository) FindAllWithPagination(limit, offset int, scope entities.AccessScope) ([]entities.UserAccess, int, error) {
+	// Limit the rows to the caller's part of the hierarchy
+	scopeCond, args := userAccessScopeCondition(scope, 1)
+	argIndex := len(args) + 1
+
 	// Count query
-	countQuery := `SELECT COUNT(*) FROM user_access`
+	countQuery := `SELECT COUNT(*) FROM user_access ua WHERE ` + scopeCond
 
 	var totalCount int
-	err := r.db.QueryRow(countQuery).Scan(&totalCount)
+	err := r.db.QueryRow(countQuery, args...).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -169,12 +200,13 @@ func (r *userAccessPostgresRepository) FindAllWithPagination(limit, offset int)
 	// Data query
 	dataQuery := `
 		SELECT id_access, username, role, is_active, last_login, 
-		       COALESCE(reference_level,''), COALESCE(reference_id,0), created_at, updated_at
-		FROM user_access
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2`
+		       COALESCE(reference_level,''), COALESCE(reference_id,0), failed_login_attempts, locked_until, must_change_password, created_at, updated_at
+		FROM user_access ua
+		WHERE ` + scopeCond + `
+		ORDER BY created_at DESC` +
+		fmt.Sprintf(` LIMIT $%d OFFSET $%d`, argIndex, argIndex+1)
 
-	rows, err := r.db.Query(dataQuery, limit, offset)
+	rows, err := r.db.Query(dataQuery, append(args, limit, offset)...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -183,7 +215,7 @@ func (r *userAccessPostgresRepository) FindAllWithPagination(limit, offset int)
 	var accesses []entities.UserAccess
 	for rows.Next() {
 		var access entities.UserAccess
-		var lastLogin sql.NullTime
+		var lastLogin, lockedUntil sql.NullTime
 		err := rows.Scan(
 			&access.ID,
 			&access.Username,
@@ -192,6 +224,9 @@ func (r *userAccessPostgresRepository) FindAllWithPagination(limit, offset int)
 			&lastLogin,
 			&access.ReferenceLevel,
 			&access.ReferenceID,
+			&access.FailedLoginAttempts,
+			&lockedUntil,
+			&access.MustChangePassword,
 			&access.CreatedAt,
 			&access.UpdatedAt,
 		)
@@ -202,6 +237,9 @@ func (r *userAccessPostgresRepository) FindAllWithPagination(limit, offset int)
 		if lastLogin.Valid {
 			access.LastLogin = &lastLogin.Time
 		}
+		if lockedUntil.Valid {
+			access.LockedUntil = &lockedUntil.Time
+		}
 
 		accesses = append(accesses, access)
 	}
@@ -209,6 +247,16 @@ func (r *userAccessPostgresRepository) FindAllWithPagination(limit, offset int)
 	return accesses, totalCount, nil
 }
 
+// ExistsInScope reports whether the user_access references a brand, cabang, outlet or pegawai inside the caller's part of the hierarchy
+func (r *userAccessPostgresRepository) ExistsInScope(id int, scope entities.AccessScope) (bool, error) {
+	scopeCond, scopeArgs := userAccessScopeCondition(scope, 2)
+	query := `SELECT EXISTS (SELECT 1 FROM user_access ua WHERE ua.id_access = $1 AND ` + scopeCond + `)`
+
+	var exists bool
+	err := r.db.QueryRow(query, append([]interface{}{id}, scopeArgs...)...).Scan(&exists)
+	return exists, err
+}
+
 func (r *userAccessPostgresRepository) Update(access *entities.UserAccess) error {
 	query := `
 		UPDATE user_access 
@@ -219,7 +267,16 @@ func (r *userAccessPostgresRepository) Update(access *entities.UserAccess) error
 	return err
 }
 
-func (r *userAccessPostgresRepository) UpdatePassword(id int, password string) error {
+// UpdatePassword stores a new password, records it in password_history and sets must_change_password
+func (r *userAccessPostgresRepository) UpdatePassword(id int, password string, mustChangePassword bool) error {
+	return updatePassword(r.db, id, password, mustChangePassword)
+}
+
+func (r *userAccessPostgresRepository) UpdatePasswordWithTx(tx *sql.Tx, id int, password string, mustChangePassword bool) error {
+	return updatePassword(tx, id, password, mustChangePassword)
+}
+
+func updatePassword(e execer, id int, password string, mustChangePassword bool) error {
 	// Hash the password before storing
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -227,14 +284,57 @@ func (r *userAccessPostgresRepository) UpdatePassword(id int, password string) e
 	}
 
 	query := `
-		UPDATE user_access 
-		SET password = $1, updated_at = NOW()
-		WHERE id_access = $2`
+		WITH updated AS (
+			UPDATE user_access
+			SET password = $1, must_change_password = $2, updated_at = NOW()
+			WHERE id_access = $3
+			RETURNING id_access, password
+		)
+		INSERT INTO password_history (id_access, password_hash, created_at)
+		SELECT id_access, password, NOW() FROM updated`
+
+	_, err = e.Exec(query, hashedPassword, mustChangePassword, id)
+	return err
+}
 
-	_, err = r.db.Exec(query, hashedPassword, id)
+func (r *userAccessPostgresRepository) SetMustChangePasswordWithTx(tx *sql.Tx, id int, mustChangePassword bool) error {
+	query := `UPDATE user_access SET must_change_password = $1, updated_at = NOW() WHERE id_access = $2`
+	_, err := tx.Exec(query, mustChangePassword, id)
 	return err
 }
 
+// FindPasswordHashes returns the current password hash followed by the last limit hashes of password_history
+func (r *userAccessPostgresRepository) FindPasswordHashes(id, limit int) ([]string, error) {
+	query := `
+		SELECT password FROM user_access WHERE id_access = $1
+		UNION ALL
+		(SELECT password_hash FROM password_history
+		WHERE id_access = $1
+		ORDER BY created_at DESC, id_password_history DESC
+		LIMIT $2)`
+
+	rows, err := r.db.Query(query, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
+
+func (r *userAccessPostgresRepository) BeginTransaction() (*sql.Tx, error) {
+	return r.db.Begin()
+}
+
 func (r *userAccessPostgresRepository) UpdateLastLogin(id int) error {
 	query := `
 		UPDATE user_access 
@@ -245,6 +345,16 @@ func (r *userAccessPostgresRepository) UpdateLastLogin(id int) error {
 	return err
 }
 
+// RegisterFailedLogin counts a wrong password and locks the user access for lockout once maxAttempts is reached
+func (r *userAccessPostgresRepository) RegisterFailedLogin(id, maxAttempts int, lockout time.Duration) (*time.Time, error) {
+	return registerFailedLogin(r.db, "user_access", "id_access", id, maxAttempts, lockout)
+}
+
+// ResetFailedLogins clears the failed attempt count and unlocks the user access
+func (r *userAccessPostgresRepository) ResetFailedLogins(id int) error {
+	return resetFailedLogins(r.db, "user_access", "id_access", id)
+}
+
 func (r *userAccessPostgresRepository) Delete(id int) error {
 	query := `DELETE FROM user_access WHERE id_access = $1`
 	_, err := r.db.Exec(query, id)
@@ -3,6 +3,7 @@ package repositories
 import (
 	"database/sql"
 	"laundry-backend/internal/entities"
+	"time"
 )
 
 type userPostgresRepository struct {
@@ -14,16 +15,19 @@ func NewUserRepository(db *sql.DB) UserRepository {
 }
 
 func (r *userPostgresRepository) FindByEmail(email string) (*entities.User, error) {
-	query := `SELECT id, email, password, name, role, created_at, updated_at FROM users WHERE email = $1`
+	query := `SELECT id, email, password, name, role, failed_login_attempts, locked_until, created_at, updated_at FROM users WHERE email = $1`
 	row := r.db.QueryRow(query, email)
 
 	var user entities.User
+	var lockedUntil sql.NullTime
 	err := row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.Password,
 		&user.Name,
 		&user.Role,
+		&user.FailedLoginAttempts,
+		&lockedUntil,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -34,6 +38,10 @@ func (r *userPostgresRepository) FindByEmail(email string) (*entities.User, erro
 		return nil, err
 	}
 
+	if lockedUntil.Valid {
+		user.LockedUntil = &lockedUntil.Time
+	}
+
 	return &user, nil
 }
 
@@ -41,4 +49,14 @@ func (r *userPostgresRepository) Create(user *entities.User) error {
 	query := `INSERT INTO users (email, password, name, role, created_at, updated_at) 
 	VALUES ($1, $2, $3, $4, NOW(), NOW()) RETURNING id`
 	return r.db.QueryRow(query, user.Email, user.Password, user.Name, user.Role).Scan(&user.ID)
+}
+
+// RegisterFailedLogin counts a wrong password and locks the user for lockout once maxAttempts is reached
+func (r *userPostgresRepository) RegisterFailedLogin(id, maxAttempts int, lockout time.Duration) (*time.Time, error) {
+	return registerFailedLogin(r.db, "users", "id", id, maxAttempts, lockout)
+}
+
+// ResetFailedLogins clears the failed attempt count and unlocks the user
+func (r *userPostgresRepository) ResetFailedLogins(id int) error {
+	return resetFailedLogins(r.db, "users", "id", id)
 }
\ No newline at end of file
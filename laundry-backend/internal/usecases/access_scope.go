@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/repositories"
+)
+
+// Rows outside the caller's part of the brand -> cabang -> outlet hierarchy are reported as not found,
+// so a branch cannot tell another branch's data apart from data that does not exist.
+
+// checkTransactionScope returns ErrTransactionNotFound unless the transaksi exists inside scope
+func checkTransactionScope(transactionRepo repositories.TransactionRepository, id int, scope entities.AccessScope) error {
+	inScope, err := transactionRepo.ExistsInScope(id, scope)
+	if err != nil {
+		return err
+	}
+	if !inScope {
+		return ErrTransactionNotFound
+	}
+	return nil
+}
+
+// checkCustomerScope returns ErrCustomerNotFound unless the pelanggan is registered at an outlet inside scope
+func checkCustomerScope(customerRepo repositories.CustomerRepository, id int, scope entities.AccessScope) error {
+	inScope, err := customerRepo.ExistsInScope(id, scope)
+	if err != nil {
+		return err
+	}
+	if !inScope {
+		return ErrCustomerNotFound
+	}
+	return nil
+}
+
+// checkOutletScope returns ErrOutletNotFound unless the outlet exists inside scope
+func checkOutletScope(outletRepo repositories.OutletRepository, id int, scope entities.AccessScope) error {
+	inScope, err := outletRepo.ExistsInScope(id, scope)
+	if err != nil {
+		return err
+	}
+	if !inScope {
+		return ErrOutletNotFound
+	}
+	return nil
+}
+
+// brandScope is the scope of a whole brand, to check that a row belongs to brandID rather than to the caller
+func brandScope(brandID int) entities.AccessScope {
+	return entities.AccessScope{Level: entities.ReferenceLevelBrand, ReferenceID: brandID}
+}
+
+// checkBrandScope returns notFound unless the brand exists inside scope
+func checkBrandScope(brandRepo repositories.BrandRepository, brandID int, scope entities.AccessScope, notFound error) error {
+	inScope, err := brandRepo.ExistsInScope(brandID, scope)
+	if err != nil {
+		return err
+	}
+	if !inScope {
+		return notFound
+	}
+	return nil
+}
+
+// checkBrandManager lets only an administrator or an account of the brand level change what a brand shares with
+// every outlet; a brand outside scope is reported with notFound and a lower level of the same brand with forbidden
+func checkBrandManager(brandRepo repositories.BrandRepository, brandID int, scope entities.AccessScope, notFound, forbidden error) error {
+	if err := checkBrandScope(brandRepo, brandID, scope, notFound); err != nil {
+		return err
+	}
+	if !scope.Global && scope.Level != entities.ReferenceLevelBrand {
+		return forbidden
+	}
+	return nil
+}
@@ -1,7 +1,7 @@
 package usecases
 
 import (
-	"errors"
+	"laundry-backend/internal/apperror"
 	"laundry-backend/internal/entities"
 	"laundry-backend/internal/repositories"
 	"laundry-backend/internal/utils"
@@ -11,18 +11,31 @@ import (
 
 type authUsecase struct {
 	userRepo repositories.UserRepository
+	tokens   *utils.TokenService
+	guard    *loginGuard
 }
 
-func NewAuthUsecase(userRepo repositories.UserRepository) AuthUsecase {
+func NewAuthUsecase(
+	userRepo repositories.UserRepository,
+	loginAuditRepo repositories.LoginAuditRepository,
+	tokens *utils.TokenService,
+	loginPolicy utils.LoginConfig,
+) AuthUsecase {
 	return &authUsecase{
 		userRepo: userRepo,
+		tokens:   tokens,
+		guard:    newLoginGuard(loginAuditRepo, loginPolicy),
 	}
 }
 
-func (u *authUsecase) Login(request entities.LoginRequest) (*entities.LoginResponse, error) {
+func (u *authUsecase) Login(request entities.LoginRequest, client entities.LoginClient) (*entities.LoginResponse, error) {
 	// Validasi input
 	if request.Email == "" || request.Password == "" {
-		return nil, errors.New("email dan password harus diisi")
+		return nil, apperror.Validation("email dan password harus diisi")
+	}
+
+	if err := u.guard.checkClient(entities.LoginTypeUsers, request.Email, client); err != nil {
+		return nil, err
 	}
 
 	// Cari user berdasarkan email
@@ -32,17 +45,37 @@ func (u *authUsecase) Login(request entities.LoginRequest) (*entities.LoginRespo
 	}
 
 	if user == nil {
-		return nil, errors.New("email atau password salah")
+		if err := u.guard.fail(entities.LoginTypeUsers, request.Email, client, entities.LoginFailureInvalidCredentials); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := u.guard.checkLocked(entities.LoginTypeUsers, request.Email, user.LockedUntil, client); err != nil {
+		return nil, err
 	}
 
 	// Verifikasi password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password))
 	if err != nil {
-		return nil, errors.New("email atau password salah")
+		if _, err := u.userRepo.RegisterFailedLogin(user.ID, u.guard.policy.MaxAttempts, u.guard.lockout()); err != nil {
+			return nil, err
+		}
+		if err := u.guard.fail(entities.LoginTypeUsers, request.Email, client, entities.LoginFailureInvalidCredentials); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := u.userRepo.ResetFailedLogins(user.ID); err != nil {
+		return nil, err
+	}
+	if err := u.guard.succeed(entities.LoginTypeUsers, request.Email, client); err != nil {
+		return nil, err
 	}
 
 	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, 0, user.Email, user.Role, "")
+	token, err := u.tokens.GenerateJWT(user.ID, 0, user.Email, user.Role, "", utils.AudienceAdmin, false)
 	if err != nil {
 		return nil, err
 	}
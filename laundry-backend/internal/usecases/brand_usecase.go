@@ -16,13 +16,20 @@ func NewBrandUsecase(brandRepo repositories.BrandRepository) BrandUsecase {
 }
 
 func (u *brandUsecase) CreateBrand(request entities.RegisterBrandRequest) error {
+	invoiceFormat, err := brandInvoiceFormat(request.InvoiceFormat)
+	if err != nil {
+		return err
+	}
+
 	brand := &entities.Brand{
-		Name:        request.Name,
-		Description: request.Description,
-		PICName:     request.PICName,
-		PICEmail:    request.PICEmail,
-		PICTelepon:  request.PICTelepon,
-		LogoURL:     request.LogoURL,
+		Name:          request.Name,
+		Description:   request.Description,
+		PICName:       request.PICName,
+		PICEmail:      request.PICEmail,
+		PICTelepon:    request.PICTelepon,
+		LogoURL:       request.LogoURL,
+		Code:          request.Code,
+		InvoiceFormat: invoiceFormat,
 	}
 
 	return u.brandRepo.Create(brand)
@@ -72,6 +79,11 @@ func (u *brandUsecase) GetAllBrandsDataTables(request entities.DataTablesRequest
 }
 
 func (u *brandUsecase) UpdateBrand(id int, request entities.RegisterBrandRequest) error {
+	invoiceFormat, err := brandInvoiceFormat(request.InvoiceFormat)
+	if err != nil {
+		return err
+	}
+
 	brand, err := u.brandRepo.FindByID(id)
 	if err != nil {
 		return err
@@ -87,10 +99,23 @@ func (u *brandUsecase) UpdateBrand(id int, request entities.RegisterBrandRequest
 	brand.PICEmail = request.PICEmail
 	brand.PICTelepon = request.PICTelepon
 	brand.LogoURL = request.LogoURL
+	brand.Code = request.Code
+	brand.InvoiceFormat = invoiceFormat
 
 	return u.brandRepo.Update(brand)
 }
 
+// brandInvoiceFormat returns the requested invoice format, the default one when none is given
+func brandInvoiceFormat(format string) (string, error) {
+	if format == "" {
+		return entities.DefaultInvoiceFormat, nil
+	}
+	if err := checkInvoiceFormat(format); err != nil {
+		return "", err
+	}
+	return format, nil
+}
+
 func (u *brandUsecase) DeleteBrand(id int) error {
 	return u.brandRepo.Delete(id)
 }
\ No newline at end of file
@@ -0,0 +1,171 @@
+package usecases
+
+import (
+	"errors"
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/repositories"
+	"time"
+)
+
+type calendarUsecase struct {
+	calendarRepo repositories.CalendarRepository
+	outletRepo   repositories.OutletRepository
+}
+
+func NewCalendarUsecase(calendarRepo repositories.CalendarRepository, outletRepo repositories.OutletRepository) CalendarUsecase {
+	return &calendarUsecase{
+		calendarRepo: calendarRepo,
+		outletRepo:   outletRepo,
+	}
+}
+
+// GetOpeningHours returns the hours of all seven weekdays, falling back to the outlet's jam_buka/jam_tutup
+func (u *calendarUsecase) GetOpeningHours(outletID int, scope entities.AccessScope) ([]entities.OpeningHours, error) {
+	if err := checkOutletScope(u.outletRepo, outletID, scope); err != nil {
+		return nil, err
+	}
+
+	outlet, err := u.outletRepo.FindByID(outletID)
+	if err != nil {
+		return nil, err
+	}
+	if outlet == nil {
+		return nil, ErrOutletNotFound
+	}
+
+	hours, err := u.calendarRepo.FindOpeningHours(outletID)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveOpeningHours(outlet, hours), nil
+}
+
+// UpdateOpeningHours replaces the weekly hours of the outlet and returns the hours now in effect
+func (u *calendarUsecase) UpdateOpeningHours(outletID int, request entities.OpeningHoursRequest, scope entities.AccessScope) ([]entities.OpeningHours, error) {
+	if err := checkOutletScope(u.outletRepo, outletID, scope); err != nil {
+		return nil, err
+	}
+
+	hours := make([]entities.OpeningHours, 0, len(request.Days))
+	for _, day := range request.Days {
+		hours = append(hours, entities.OpeningHours{
+			OutletID:  outletID,
+			Weekday:   day.Weekday,
+			OpenTime:  day.OpenTime,
+			CloseTime: day.CloseTime,
+			Closed:    day.Closed,
+		})
+	}
+	if err := u.calendarRepo.ReplaceOpeningHours(outletID, hours); err != nil {
+		return nil, err
+	}
+
+	return u.GetOpeningHours(outletID, scope)
+}
+
+// GetOutletHolidays returns the national, brand and outlet holidays that close the outlet between from and to.
+// A zero from is the outlet's today, a zero to a year after from.
+func (u *calendarUsecase) GetOutletHolidays(outletID int, from, to time.Time, scope entities.AccessScope) ([]entities.Holiday, error) {
+	if err := checkOutletScope(u.outletRepo, outletID, scope); err != nil {
+		return nil, err
+	}
+
+	if from.IsZero() {
+		outlet, err := u.outletRepo.FindByID(outletID)
+		if err != nil {
+			return nil, err
+		}
+		if outlet == nil {
+			return nil, ErrOutletNotFound
+		}
+		location, err := outletLocation(outlet)
+		if err != nil {
+			return nil, err
+		}
+		today := time.Now().In(location)
+		from = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if to.IsZero() {
+		to = from.AddDate(1, 0, 0)
+	}
+	if to.Before(from) {
+		return nil, ErrInvalidDateRange
+	}
+	return u.calendarRepo.FindHolidaysByOutlet(outletID, from, to)
+}
+
+// GetAvailability tells whether the outlet is open at the given time
+func (u *calendarUsecase) GetAvailability(outletID int, at time.Time, scope entities.AccessScope) (*entities.OutletAvailability, error) {
+	if err := checkOutletScope(u.outletRepo, outletID, scope); err != nil {
+		return nil, err
+	}
+
+	calendar, err := loadOutletCalendar(u.outletRepo, u.calendarRepo, outletID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := calendar.availability(at)
+	availability.OutletID = outletID
+	return &availability, nil
+}
+
+func (u *calendarUsecase) CreateHoliday(request entities.CreateHolidayRequest, scope entities.AccessScope) (*entities.Holiday, error) {
+	holiday := &entities.Holiday{
+		Type: request.Type,
+		Date: request.Date,
+		Name: request.Name,
+	}
+	switch request.Type {
+	case entities.HolidayBrand:
+		holiday.BrandID = &request.BrandID
+	case entities.HolidayOutlet:
+		holiday.OutletID = &request.OutletID
+	}
+
+	if err := u.checkHolidayScope(holiday, scope); err != nil {
+		return nil, err
+	}
+	if err := u.calendarRepo.CreateHoliday(holiday); err != nil {
+		return nil, err
+	}
+	return holiday, nil
+}
+
+func (u *calendarUsecase) DeleteHoliday(id int, scope entities.AccessScope) error {
+	holiday, err := u.calendarRepo.FindHolidayByID(id)
+	if err != nil {
+		return err
+	}
+	if holiday == nil {
+		return ErrHolidayNotFound
+	}
+
+	if err := u.checkHolidayScope(holiday, scope); err != nil {
+		// a holiday of another brand or outlet is reported as not found, like the rows it belongs to
+		if errors.Is(err, ErrBrandNotFound) || errors.Is(err, ErrOutletNotFound) {
+			return ErrHolidayNotFound
+		}
+		return err
+	}
+	return u.calendarRepo.DeleteHoliday(id)
+}
+
+// checkHolidayScope lets administrators manage every holiday, brand accounts the holidays of their brand
+// and anyone the holidays of an outlet inside their scope
+func (u *calendarUsecase) checkHolidayScope(holiday *entities.Holiday, scope entities.AccessScope) error {
+	switch {
+	case scope.Global:
+		return nil
+	case holiday.Type == entities.HolidayNational:
+		return ErrNationalHolidayForbidden
+	case holiday.Type == entities.HolidayBrand:
+		if scope.Level != entities.ReferenceLevelBrand || holiday.BrandID == nil || *holiday.BrandID != scope.ReferenceID {
+			return ErrBrandNotFound
+		}
+		return nil
+	case holiday.OutletID != nil:
+		return checkOutletScope(u.outletRepo, *holiday.OutletID, scope)
+	}
+	return ErrOutletNotFound
+}
@@ -7,15 +7,21 @@ import (
 
 type customerUsecase struct {
 	customerRepo repositories.CustomerRepository
+	outletRepo   repositories.OutletRepository
 }
 
-func NewCustomerUsecase(customerRepo repositories.CustomerRepository) CustomerUsecase {
+func NewCustomerUsecase(customerRepo repositories.CustomerRepository, outletRepo repositories.OutletRepository) CustomerUsecase {
 	return &customerUsecase{
 		customerRepo: customerRepo,
+		outletRepo:   outletRepo,
 	}
 }
 
-func (u *customerUsecase) CreateCustomer(request entities.RegisterCustomerRequest) error {
+func (u *customerUsecase) CreateCustomer(request entities.RegisterCustomerRequest, scope entities.AccessScope) error {
+	if err := checkOutletScope(u.outletRepo, request.OutletID, scope); err != nil {
+		return err
+	}
+
 	customer := &entities.Customer{
 		OutletID: request.OutletID,
 		Name:     request.Name,
@@ -27,19 +33,29 @@ func (u *customerUsecase) CreateCustomer(request entities.RegisterCustomerReques
 	return u.customerRepo.Create(customer)
 }
 
-func (u *customerUsecase) GetCustomerByID(id int) (*entities.Customer, error) {
-	return u.customerRepo.FindByID(id)
+func (u *customerUsecase) GetCustomerByID(id int, scope entities.AccessScope) (*entities.Customer, error) {
+	if err := checkCustomerScope(u.customerRepo, id, scope); err != nil {
+		return nil, err
+	}
+	customer, err := u.customerRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+	return customer, nil
 }
 
-func (u *customerUsecase) GetCustomersByOutletID(outletID int) ([]entities.Customer, error) {
-	return u.customerRepo.FindByOutletID(outletID)
+func (u *customerUsecase) GetCustomersByOutletID(outletID int, scope entities.AccessScope) ([]entities.Customer, error) {
+	return u.customerRepo.FindByOutletID(outletID, scope)
 }
 
-func (u *customerUsecase) GetAllCustomers() ([]entities.Customer, error) {
-	return u.customerRepo.FindAll()
+func (u *customerUsecase) GetAllCustomers(scope entities.AccessScope) ([]entities.Customer, error) {
+	return u.customerRepo.FindAll(scope)
 }
 
-func (u *customerUsecase) GetAllCustomersDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error) {
+func (u *customerUsecase) GetAllCustomersDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error) {
 	// Default ordering
 	orderBy := "id_pelanggan"
 	orderDir := "asc"
@@ -57,6 +73,7 @@ func (u *customerUsecase) GetAllCustomersDataTables(request entities.DataTablesR
 		request.Search.Value,
 		orderBy,
 		orderDir,
+		scope,
 	)
 	
 	if err != nil {
@@ -74,7 +91,14 @@ func (u *customerUsecase) GetAllCustomersDataTables(request entities.DataTablesR
 	return response, nil
 }
 
-func (u *customerUsecase) UpdateCustomer(id int, request entities.RegisterCustomerRequest) error {
+func (u *customerUsecase) UpdateCustomer(id int, request entities.RegisterCustomerRequest, scope entities.AccessScope) error {
+	if err := checkCustomerScope(u.customerRepo, id, scope); err != nil {
+		return err
+	}
+	if err := checkOutletScope(u.outletRepo, request.OutletID, scope); err != nil {
+		return err
+	}
+
 	customer, err := u.customerRepo.FindByID(id)
 	if err != nil {
 		return err
@@ -93,6 +117,9 @@ func (u *customerUsecase) UpdateCustomer(id int, request entities.RegisterCustom
 	return u.customerRepo.Update(customer)
 }
 
-func (u *customerUsecase) DeleteCustomer(id int) error {
+func (u *customerUsecase) DeleteCustomer(id int, scope entities.AccessScope) error {
+	if err := checkCustomerScope(u.customerRepo, id, scope); err != nil {
+		return err
+	}
 	return u.customerRepo.Delete(id)
 }
\ No newline at end of file
@@ -7,15 +7,33 @@ import (
 
 type employeeUsecase struct {
 	employeeRepo repositories.EmployeeRepository
+	outletRepo   repositories.OutletRepository
 }
 
-func NewEmployeeUsecase(employeeRepo repositories.EmployeeRepository) EmployeeUsecase {
+func NewEmployeeUsecase(employeeRepo repositories.EmployeeRepository, outletRepo repositories.OutletRepository) EmployeeUsecase {
 	return &employeeUsecase{
 		employeeRepo: employeeRepo,
+		outletRepo:   outletRepo,
 	}
 }
 
-func (u *employeeUsecase) CreateEmployee(request entities.RegisterEmployeeRequest) error {
+// checkEmployeeScope returns ErrEmployeeNotFound unless the pegawai works at an outlet inside scope
+func (u *employeeUsecase) checkEmployeeScope(id int, scope entities.AccessScope) error {
+	inScope, err := u.employeeRepo.ExistsInScope(id, scope)
+	if err != nil {
+		return err
+	}
+	if !inScope {
+		return ErrEmployeeNotFound
+	}
+	return nil
+}
+
+func (u *employeeUsecase) CreateEmployee(request entities.RegisterEmployeeRequest, scope entities.AccessScope) error {
+	if err := checkOutletScope(u.outletRepo, request.OutletID, scope); err != nil {
+		return err
+	}
+
 	// Hash the password before storing
 
 	employee := &entities.Employee{
@@ -36,15 +54,19 @@ func (u *employeeUsecase) CreateEmployee(request entities.RegisterEmployeeReques
 	return u.employeeRepo.Create(employee)
 }
 
-func (u *employeeUsecase) GetEmployeeByID(id int) (*entities.Employee, error) {
+func (u *employeeUsecase) GetEmployeeByID(id int, scope entities.AccessScope) (*entities.Employee, error) {
+	inScope, err := u.employeeRepo.ExistsInScope(id, scope)
+	if err != nil || !inScope {
+		return nil, err
+	}
 	return u.employeeRepo.FindByID(id)
 }
 
-func (u *employeeUsecase) GetAllEmployees() ([]entities.Employee, error) {
-	return u.employeeRepo.FindAll()
+func (u *employeeUsecase) GetAllEmployees(scope entities.AccessScope) ([]entities.Employee, error) {
+	return u.employeeRepo.FindAll(scope)
 }
 
-func (u *employeeUsecase) GetAllEmployeesDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error) {
+func (u *employeeUsecase) GetAllEmployeesDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error) {
 	// Default ordering
 	orderBy := "id_pegawai"
 	orderDir := "asc"
@@ -62,6 +84,7 @@ func (u *employeeUsecase) GetAllEmployeesDataTables(request entities.DataTablesR
 		request.Search.Value,
 		orderBy,
 		orderDir,
+		scope,
 	)
 
 	if err != nil {
@@ -79,7 +102,14 @@ func (u *employeeUsecase) GetAllEmployeesDataTables(request entities.DataTablesR
 	return response, nil
 }
 
-func (u *employeeUsecase) UpdateEmployee(id int, request entities.RegisterEmployeeRequest) error {
+func (u *employeeUsecase) UpdateEmployee(id int, request entities.RegisterEmployeeRequest, scope entities.AccessScope) error {
+	if err := u.checkEmployeeScope(id, scope); err != nil {
+		return err
+	}
+	if err := checkOutletScope(u.outletRepo, request.OutletID, scope); err != nil {
+		return err
+	}
+
 	employee, err := u.employeeRepo.FindByID(id)
 	if err != nil {
 		return err
@@ -105,6 +135,9 @@ func (u *employeeUsecase) UpdateEmployee(id int, request entities.RegisterEmploy
 	return u.employeeRepo.Update(employee)
 }
 
-func (u *employeeUsecase) DeleteEmployee(id int) error {
+func (u *employeeUsecase) DeleteEmployee(id int, scope entities.AccessScope) error {
+	if err := u.checkEmployeeScope(id, scope); err != nil {
+		return err
+	}
 	return u.employeeRepo.Delete(id)
 }
@@ -0,0 +1,101 @@
+package usecases
+
+import "laundry-backend/internal/apperror"
+
+var (
+	// ErrUserAccessNotFound is returned when the referenced user_access does not exist
+	ErrUserAccessNotFound = apperror.NotFound("user access not found")
+	// ErrUserAccessForbidden is returned when a user_access would be given, or already holds, a role or reference_level above the caller's
+	ErrUserAccessForbidden = apperror.Forbidden("user access above the caller's role or level")
+	// ErrAccountLocked is returned when a login is attempted on a username locked after too many wrong passwords
+	ErrAccountLocked = apperror.New(apperror.KindLocked, "account is temporarily locked")
+	// ErrTooManyLoginAttempts is returned when the client IP has failed to log in too often
+	ErrTooManyLoginAttempts = apperror.New(apperror.KindTooManyRequests, "too many failed login attempts")
+	// ErrWeakPassword is returned when a new password does not satisfy the password policy
+	ErrWeakPassword = apperror.Validation("password does not meet the password policy")
+	// ErrPasswordReused is returned when a new password is one of the recently used passwords
+	ErrPasswordReused = apperror.Validation("password was used recently")
+	// ErrInvalidCurrentPassword is returned when the current password given to change a password is wrong
+	ErrInvalidCurrentPassword = apperror.Validation("current password is incorrect")
+	// ErrInvalidResetToken is returned when a password reset token is unknown, expired or already used
+	ErrInvalidResetToken = apperror.Unauthorized("invalid password reset token")
+	// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired or already revoked
+	ErrInvalidRefreshToken = apperror.Unauthorized("invalid refresh token")
+	// ErrInvalidCredentials is returned when a super admin logs in with an unknown email or a wrong password
+	ErrInvalidCredentials = apperror.Unauthorized("invalid email or password")
+	// ErrCabangNotFound is returned when the cabang does not exist or is outside the caller's hierarchy
+	ErrCabangNotFound = apperror.NotFound("cabang not found")
+	// ErrOutletNotFound is returned when the outlet does not exist or is outside the caller's hierarchy
+	ErrOutletNotFound = apperror.NotFound("outlet not found")
+	// ErrBrandNotFound is returned when the brand does not exist or is outside the caller's hierarchy
+	ErrBrandNotFound = apperror.NotFound("brand not found")
+	// ErrEmployeeNotFound is returned when the pegawai does not exist or is outside the caller's hierarchy
+	ErrEmployeeNotFound = apperror.NotFound("employee not found")
+	// ErrCustomerNotFound is returned when the pelanggan does not exist or is outside the caller's hierarchy
+	ErrCustomerNotFound = apperror.NotFound("customer not found")
+	// ErrServiceNotFound is returned when the referenced layanan does not exist
+	ErrServiceNotFound = apperror.NotFound("service not found")
+	// ErrServiceForbidden is returned when an account below the brand level creates, changes or deletes a layanan of its brand
+	ErrServiceForbidden = apperror.Forbidden("only the brand can manage its services")
+	// ErrTransactionNotFound is returned when the referenced transaksi does not exist or is outside the caller's hierarchy
+	ErrTransactionNotFound = apperror.NotFound("transaction not found")
+	// ErrInvalidTransactionStatus is returned when the requested status is unknown
+	ErrInvalidTransactionStatus = apperror.Validation("invalid transaction status")
+	// ErrInvalidStatusTransition is returned when the status change is not allowed from the current status
+	ErrInvalidStatusTransition = apperror.Conflict("invalid transaction status transition")
+	// ErrOutstandingBalance is returned when a transaksi cannot be picked up before it is paid off
+	ErrOutstandingBalance = apperror.Conflict("transaction has an outstanding balance")
+	// ErrTransactionCancelled is returned when a payment is recorded or changed on a cancelled transaksi
+	ErrTransactionCancelled = apperror.Conflict("transaction is cancelled")
+	// ErrCancelReasonRequired is returned when a transaksi is cancelled without a reason
+	ErrCancelReasonRequired = apperror.Validation("cancellation reason is required")
+	// ErrInvalidRefundAmount is returned when a refund is negative or more than what was paid
+	ErrInvalidRefundAmount = apperror.Validation("invalid refund amount")
+	// ErrPaymentNotFound is returned when the referenced pembayaran does not exist
+	ErrPaymentNotFound = apperror.NotFound("payment not found")
+	// ErrInvalidPaymentStatus is returned when the payment status is not gagal, pending or sukses
+	ErrInvalidPaymentStatus = apperror.Validation("invalid payment status")
+	// ErrInvalidPaymentAmount is returned when a payment amount is zero or negative
+	ErrInvalidPaymentAmount = apperror.Validation("invalid payment amount")
+	// ErrPaymentMethodNotFound is returned when the payment method does not exist or is inactive
+	ErrPaymentMethodNotFound = apperror.NotFound("payment method not found")
+	// ErrInvalidPaymentMethodFee is returned when the merchant/admin fee settings of a payment method are invalid
+	ErrInvalidPaymentMethodFee = apperror.Validation("invalid payment method fee")
+	// ErrInvalidCallbackSignature is returned when a gateway callback is not signed with the payment method keys
+	ErrInvalidCallbackSignature = apperror.Unauthorized("invalid callback signature")
+	// ErrInvalidCallbackPayload is returned when a gateway callback body cannot be applied
+	ErrInvalidCallbackPayload = apperror.Validation("invalid callback payload")
+	// ErrPaymentAmountMismatch is returned when a callback amount does not match the payment or the outstanding balance
+	ErrPaymentAmountMismatch = apperror.Validation("payment amount mismatch")
+	// ErrInvalidInvoiceFormat is returned when a brand's invoice format lacks {OUTLET} or {SEQ}, has an unknown placeholder or is too long
+	ErrInvalidInvoiceFormat = apperror.Validation("invalid invoice format")
+	// ErrHolidayNotFound is returned when the hari_libur does not exist or is outside the caller's hierarchy
+	ErrHolidayNotFound = apperror.NotFound("holiday not found")
+	// ErrNationalHolidayForbidden is returned when anyone but an administrator manages a national holiday
+	ErrNationalHolidayForbidden = apperror.Forbidden("only administrators can manage national holidays")
+	// ErrInvalidDateRange is returned when the end of a date range is before its start
+	ErrInvalidDateRange = apperror.Validation("invalid date range")
+	// ErrServiceCategoryNotFound is returned when the referenced kategori_layanan does not exist
+	ErrServiceCategoryNotFound = apperror.NotFound("service category not found")
+	// ErrServiceCategoryForbidden is returned when anyone but an administrator manages the kategori_layanan shared by every brand
+	ErrServiceCategoryForbidden = apperror.Forbidden("only administrators can manage service categories")
+	// ErrModifierNotFound is returned when the modifier_layanan does not exist
+	ErrModifierNotFound = apperror.NotFound("modifier not found")
+	// ErrModifierForbidden is returned when an account below the brand level creates, changes or deletes a modifier
+	ErrModifierForbidden = apperror.Forbidden("only the brand can manage its modifiers")
+	// ErrInvalidModifier is returned when an add-on is not flat, or a modifier targets both a service and a category
+	// or a service of another brand
+	ErrInvalidModifier = apperror.Validation("invalid modifier")
+	// ErrModifierNotApplicable is returned when a modifier selected on an order line is inactive or does not apply to its service
+	ErrModifierNotApplicable = apperror.Validation("modifier does not apply to the service")
+	// ErrPricingRuleNotFound is returned when the aturan_harga does not exist or belongs to an outlet outside the caller's hierarchy
+	ErrPricingRuleNotFound = apperror.NotFound("pricing rule not found")
+	// ErrInvalidPricingRule is returned when two price tiers of a rule start at the same quantity
+	ErrInvalidPricingRule = apperror.Validation("invalid pricing rule")
+	// ErrPricingRuleForbidden is returned when an account below the brand level manages a rule for every outlet
+	ErrPricingRuleForbidden = apperror.Forbidden("only the brand can manage a pricing rule for every outlet")
+	// ErrIdempotencyKeyMismatch is returned when an Idempotency-Key is reused with a different request
+	ErrIdempotencyKeyMismatch = apperror.New(apperror.KindUnprocessable, "idempotency key was used with a different request")
+	// ErrIdempotencyKeyInProgress is returned when the request that reserved an Idempotency-Key has not finished yet
+	ErrIdempotencyKeyInProgress = apperror.Conflict("a request with this idempotency key is still being processed")
+)
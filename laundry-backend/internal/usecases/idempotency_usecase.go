@@ -0,0 +1,62 @@
+package usecases
+
+import (
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/repositories"
+	"time"
+)
+
+type idempotencyUsecase struct {
+	idempotencyRepo repositories.IdempotencyKeyRepository
+	expiry          time.Duration
+}
+
+// NewIdempotencyUsecase keeps the response of an idempotent request for expiry, after which its key can be reused
+func NewIdempotencyUsecase(idempotencyRepo repositories.IdempotencyKeyRepository, expiry time.Duration) IdempotencyUsecase {
+	return &idempotencyUsecase{
+		idempotencyRepo: idempotencyRepo,
+		expiry:          expiry,
+	}
+}
+
+// Begin reserves key for a request with the given body hash. When the key was used before it returns the stored record,
+// with CompletedAt set, for the caller to replay; a different body or a request still in flight is an error.
+func (u *idempotencyUsecase) Begin(owner, key, requestHash string) (*entities.IdempotencyKey, error) {
+	record := &entities.IdempotencyKey{
+		Owner:       owner,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiredAt:   time.Now().Add(u.expiry),
+	}
+	reserved, err := u.idempotencyRepo.Reserve(record)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return record, nil
+	}
+
+	existing, err := u.idempotencyRepo.FindByKey(owner, key)
+	if err != nil {
+		return nil, err
+	}
+	// another body is refused even while the first request is still in flight
+	if existing != nil && existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyMismatch
+	}
+	// a nil record was released between Reserve and FindByKey; the client may simply retry
+	if existing == nil || existing.CompletedAt == nil {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return existing, nil
+}
+
+func (u *idempotencyUsecase) Complete(record *entities.IdempotencyKey, statusCode int, responseBody string) error {
+	return u.idempotencyRepo.Complete(record.ID, statusCode, responseBody)
+}
+
+// Release forgets a reserved key whose request failed on the server, so the next retry is processed again
+func (u *idempotencyUsecase) Release(record *entities.IdempotencyKey) error {
+	return u.idempotencyRepo.Delete(record.ID)
+}
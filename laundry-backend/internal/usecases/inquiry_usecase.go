@@ -2,119 +2,98 @@ package usecases
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
+	"laundry-backend/internal/apperror"
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/gateway"
 	"laundry-backend/internal/repositories"
-	"math/rand"
+	"laundry-backend/internal/utils"
+	"math"
 	"time"
 
 	"github.com/golang-jwt/jwt"
 )
 
 type inquiryUsecase struct {
-	inquiryRepo    repositories.InquiryRepository
-	userAccessRepo repositories.UserAccessRepository
-	cabangRepo     repositories.CabangRepository
-	outletRepo     repositories.OutletRepository
-	employeeRepo   repositories.EmployeeRepository
-	paymentRepo    repositories.PaymentMethodRepository
-	serviceRepo    repositories.ServiceRepository
+	inquiryRepo     repositories.InquiryRepository
+	userAccessRepo  repositories.UserAccessRepository
+	cabangRepo      repositories.CabangRepository
+	outletRepo      repositories.OutletRepository
+	calendarRepo    repositories.CalendarRepository
+	employeeRepo    repositories.EmployeeRepository
+	customerRepo    repositories.CustomerRepository
+	paymentRepo     repositories.PaymentMethodRepository
+	serviceRepo     repositories.ServiceRepository
+	modifierRepo    repositories.ModifierRepository
+	pricingRuleRepo repositories.PricingRuleRepository
+	gateways        gateway.Resolver
 }
 
 func NewInquiryUsecase(inquiryRepo repositories.InquiryRepository, userAccessRepo repositories.UserAccessRepository,
 	cabangRepo repositories.CabangRepository,
 	outletRepo repositories.OutletRepository,
+	calendarRepo repositories.CalendarRepository,
 	employeeRepo repositories.EmployeeRepository,
+	customerRepo repositories.CustomerRepository,
 	paymentRepo repositories.PaymentMethodRepository,
-	serviceRepo repositories.ServiceRepository) InquiryUsecase {
+	serviceRepo repositories.ServiceRepository,
+	modifierRepo repositories.ModifierRepository,
+	pricingRuleRepo repositories.PricingRuleRepository,
+	gateways gateway.Resolver) InquiryUsecase {
 	return &inquiryUsecase{
-		inquiryRepo:    inquiryRepo,
-		userAccessRepo: userAccessRepo,
-		cabangRepo:     cabangRepo,
-		outletRepo:     outletRepo,
-		employeeRepo:   employeeRepo,
-		paymentRepo:    paymentRepo,
-		serviceRepo:    serviceRepo,
+		inquiryRepo:     inquiryRepo,
+		userAccessRepo:  userAccessRepo,
+		cabangRepo:      cabangRepo,
+		outletRepo:      outletRepo,
+		calendarRepo:    calendarRepo,
+		employeeRepo:    employeeRepo,
+		customerRepo:    customerRepo,
+		paymentRepo:     paymentRepo,
+		serviceRepo:     serviceRepo,
+		modifierRepo:    modifierRepo,
+		pricingRuleRepo: pricingRuleRepo,
+		gateways:        gateways,
 	}
 }
 
-func (u *inquiryUsecase) ProcessInquiry(request entities.InquiryRequest, claims jwt.MapClaims) (response *entities.InquiryResponse, err error) {
+func (u *inquiryUsecase) ProcessInquiry(request entities.InquiryRequest, claims jwt.MapClaims, scope entities.AccessScope) (response *entities.InquiryResponse, err error) {
 	var (
-		t        = time.Now()
-		outlerId int
+		t = time.Now()
 	)
-	// 1. validasi user access
-	userAccess, err := u.userAccessRepo.FindByID(request.UserID)
+	// 1-2. validasi user access dan outlet
+	userAccess, outletID, err := u.resolveOutlet(request.UserID, request.OutletID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("invalid userAccess")
-		}
 		return nil, err
 	}
-	// 2. get outlet
-	fmt.Println(":::", userAccess.ReferenceLevel)
-	if userAccess.ReferenceLevel != "cabang" {
-		switch userAccess.ReferenceLevel {
-		case "karyawan":
-			employee, err := u.employeeRepo.FindByID(userAccess.ReferenceID)
-			if err != nil {
-				return nil, err
-			}
-			outlerId = employee.OutletID
-		case "outlet":
-			outlet, err := u.outletRepo.FindByID(userAccess.ReferenceID)
-			if err != nil {
-				return nil, err
-			}
-			outlerId = outlet.ID
-		default:
-			return nil, errors.New("Invalid Reference Level")
-		}
-	} else {
-		if request.OutletID == 0 {
-			return nil, errors.New("Outlet ID CAnnot be Null")
-		}
-		//validasi outlet
-		outletArr, err := u.outletRepo.FindAll(entities.Outlet{
-			CabangID: userAccess.ReferenceID,
-			ID:       request.OutletID,
-		})
-		if err != nil {
-			if err == sql.ErrNoRows {
-				return nil, errors.New("invalid Package")
-			}
-			return nil, err
-		}
-		if len(outletArr) == 0 {
-			return nil, errors.New("invalid OutletID")
-		}
-	}
-	// 3. Validasi paket layanan
-	servicePackage, err := u.serviceRepo.FindByID(request.ServicePackageID)
+	request.OutletID = outletID
+	// 3. Validasi paket layanan untuk setiap item
+	details, totalPrice, estimatedReady, err := u.priceItems(request.Items, request.OutletID, t, &userAccess.Username)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("invalid Package")
-		}
 		return nil, err
 	}
 
-	// 4. Validate customer
-	valid, err := u.inquiryRepo.ValidateCustomer(request.CustomerID)
-	if err != nil {
+	// 4. Validate customer, another franchise's pelanggan is reported as not found
+	if err := checkCustomerScope(u.customerRepo, request.CustomerID, scope); err != nil {
 		return nil, err
 	}
-	if !valid {
-		return nil, errors.New("invalid customer")
-	}
 	// 5. validasi payment method
 	paymentMethod, err := u.paymentRepo.FindByID(request.PaymentMethodID)
 	if err != nil {
 		return nil, err
 	}
+	if paymentMethod == nil || paymentMethod.Status == "inactive" {
+		return nil, ErrPaymentMethodNotFound
+	}
+	fees := calculateFees(totalPrice, paymentMethod)
+	totalPrice = fees.GrandTotal
 
-	// Calculate subtotal
-	subtotal := servicePackage.Price * request.Quantity
+	if request.DownPayment < 0 {
+		return nil, apperror.Validation("invalid down payment")
+	}
+	var changeAmount float64
+	if request.DownPayment > totalPrice {
+		changeAmount = request.DownPayment - totalPrice
+	}
 
 	// Begin database transaction
 	tx, err := u.inquiryRepo.BeginTransaction()
@@ -123,22 +102,31 @@ func (u *inquiryUsecase) ProcessInquiry(request entities.InquiryRequest, claims
 	}
 
 	// Create transaction entity
-	if request.OutletID == 0 {
-		request.OutletID = outlerId
+	invoiceNumber, err := u.nextInvoiceNumber(tx, request.OutletID, t)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 	transaction := &entities.Transaction{
-		CustomerID:    request.CustomerID,
-		OutletID:      request.OutletID,
-		InvoiceNumber: generateInvoiceNumber(),
-		EntryDate:     &t,
-		Status:        "diterima", // Default status
-		Note:          request.Note,
-		CreatedAt:     t,
-		UpdatedAt:     t,
-		CreatedBy:     &userAccess.Username,
-		UpdatedBy:     &userAccess.Username,
-		UserID:        &userAccess.ID,
-		TotalPrice:    subtotal,
+		CustomerID:      request.CustomerID,
+		OutletID:        request.OutletID,
+		InvoiceNumber:   invoiceNumber,
+		EntryDate:       &t,
+		EstimatedReady:  estimatedReady,
+		Status:          statusDiterima, // Default status
+		Note:            request.Note,
+		CreatedAt:       t,
+		UpdatedAt:       t,
+		CreatedBy:       &userAccess.Username,
+		UpdatedBy:       &userAccess.Username,
+		UserID:          &userAccess.ID,
+		TotalPrice:      totalPrice,
+		ServiceSubtotal: fees.ServiceSubtotal,
+		AdminFee:        fees.AdminFee,
+		MerchantFee:     fees.MerchantFee,
+		FeeBearer:       fees.FeeBearer,
+		PaidAmount:      request.DownPayment,
+		ChangeAmount:    changeAmount,
 	}
 
 	// Insert transaction with transaction
@@ -148,51 +136,46 @@ func (u *inquiryUsecase) ProcessInquiry(request entities.InquiryRequest, claims
 		return nil, fmt.Errorf("failed to insert transaction: %w", err)
 	}
 
-	// Create transaction detail
-	detail := &entities.TransactionDetail{
-		TransactionID: id,
-		ServiceID:     request.ServicePackageID,
-		Quantity:      &request.Quantity,
-		Price:         &servicePackage.Price,
-		Subtotal:      &subtotal,
-		CreatedAt:     t,
-		UpdatedAt:     t,
-		CreatedBy:     &userAccess.Username,
-		UpdatedBy:     &userAccess.Username,
-	}
-
-	// Insert transaction detail with transaction
-	err = u.inquiryRepo.InsertTransactionDetailWithTx(tx, detail)
-	if err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("failed to insert transaction detail: %w", err)
+	// Insert every transaction detail within the same transaction
+	for i := range details {
+		details[i].TransactionID = id
+		err = u.inquiryRepo.InsertTransactionDetailWithTx(tx, &details[i])
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to insert transaction detail: %w", err)
+		}
 	}
 
-	// Create initial payment record with default values
-	payment := &entities.Payment{
-		TransactionID:   id,
-		PaymentMethodID: paymentMethod.ID,
-		PaymentDate:     &t,
-		Amount:          0,                        // Default to 0 as no payment has been made yet
-		Method:          paymentMethod.NamaMetode, // Default to empty as no payment method selected yet
-		CreatedAt:       t,
-		UpdatedAt:       t,
-	}
+	// Record the down payment (uang muka) paid at drop-off, if any
+	var payment *entities.Payment
+	if request.DownPayment > 0 {
+		payment = &entities.Payment{
+			TransactionID:   id,
+			PaymentMethodID: paymentMethod.ID,
+			PaymentDate:     &t,
+			Amount:          request.DownPayment,
+			Method:          paymentMethod.NamaMetode,
+			Status:          entities.PaymentStatusSuccess,
+			Note:            "Uang muka",
+			CreatedAt:       t,
+			UpdatedAt:       t,
+		}
 
-	// Insert payment record
-	err = u.inquiryRepo.InsertPaymentWithTx(tx, payment)
-	if err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("failed to insert payment: %w", err)
+		err = u.inquiryRepo.InsertPaymentWithTx(tx, payment)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to insert payment: %w", err)
+		}
 	}
 
 	// Create initial history status transaction record
 	history := &entities.HistoryStatusTransaction{
 		TransactionID: id,
-		OldStatus:     "diterima", // No old status as this is the initial status
-		NewStatus:     "diterima",
+		OldStatus:     statusDiterima, // No old status as this is the initial status
+		NewStatus:     statusDiterima,
 		ChangeTime:    &t,
 		Description:   "Transaksi baru dibuat",
+		ChangedBy:     userAccess.Username,
 		CreatedAt:     t,
 		UpdatedAt:     t,
 	}
@@ -210,33 +193,266 @@ func (u *inquiryUsecase) ProcessInquiry(request entities.InquiryRequest, claims
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	transaction.TotalPaid = request.DownPayment
+	transaction.PaymentStatus = entities.PaymentStatusPaid
+	var charge *entities.PaymentCharge
+	if request.DownPayment < totalPrice {
+		transaction.Outstanding = totalPrice - request.DownPayment
+		transaction.PaymentStatus = entities.PaymentStatusUnpaid
+		charge = u.openCharge(transaction, paymentMethod, t)
+	}
+
 	// Prepare the response
 	response = &entities.InquiryResponse{
 		Transaction:        *transaction,
-		TransactionDetails: []entities.TransactionDetail{*detail},
-		Payment:            *payment,
+		TransactionDetails: details,
+		FeeBreakdown:       fees,
+		Payment:            payment,
+		Charge:             charge,
 		History:            *history,
 	}
 
 	return response, nil
 }
 
-// generateInvoiceNumber generates a unique invoice number
-func generateInvoiceNumber() string {
-	// Use current timestamp and random number to generate unique invoice number
-	now := time.Now()
-	year := now.Year()
-	month := int(now.Month())
-	day := now.Day()
-	hour := now.Hour()
-	minute := now.Minute()
-	second := now.Second()
-
-	// Generate a random 4-digit number
-	rand.Seed(time.Now().UnixNano())
-	random := rand.Intn(9000) + 1000
-
-	return fmt.Sprintf("INV%d%02d%02d%02d%02d%02d%d", year, month, day, hour, minute, second, random)
+// openCharge opens a gateway charge for the outstanding balance of a committed transaksi and records it as a pending
+// payment; online gateways return a payment link or QR payload. It runs after the commit so no lock is held while the
+// gateway answers. When the charge cannot be opened the order stays belum lunas and the balance can still be collected
+// through POST /transactions/:id/payments.
+func (u *inquiryUsecase) openCharge(transaction *entities.Transaction, paymentMethod *entities.PaymentMethod, t time.Time) *entities.PaymentCharge {
+	charge, err := u.gateways.Resolve(paymentMethod).CreateCharge(gateway.ChargeRequest{
+		ReferenceNo: transaction.InvoiceNumber,
+		Amount:      transaction.Outstanding,
+		Description: "Pembayaran " + transaction.InvoiceNumber,
+	})
+	if err != nil {
+		utils.LoggMsg("ProcessInquiry", fmt.Sprintf("Failed to create payment charge for transaction %d", transaction.ID), err)
+		return nil
+	}
+
+	// Only charges with a gateway reference can be reconciled by callbacks later
+	if charge.ReferenceNo == "" {
+		return nil
+	}
+	err = u.inquiryRepo.InsertPayment(&entities.Payment{
+		TransactionID:      transaction.ID,
+		PaymentMethodID:    paymentMethod.ID,
+		PaymentDate:        &t,
+		Amount:             charge.Amount,
+		Method:             paymentMethod.NamaMetode,
+		PartnerReferenceNo: charge.ReferenceNo,
+		Status:             charge.Status,
+		CreatedAt:          t,
+		UpdatedAt:          t,
+	})
+	if err != nil {
+		utils.LoggMsg("ProcessInquiry", fmt.Sprintf("Failed to record payment charge %s", charge.ReferenceNo), err)
+		return nil
+	}
+	return charge
+}
+
+// QuoteInquiry prices the items of an inquiry the way ProcessInquiry would, without saving anything
+func (u *inquiryUsecase) QuoteInquiry(request entities.QuoteRequest) (*entities.InquiryQuote, error) {
+	t := time.Now()
+	userAccess, outletID, err := u.resolveOutlet(request.UserID, request.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	details, totalPrice, estimatedReady, err := u.priceItems(request.Items, outletID, t, &userAccess.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	fees := entities.FeeBreakdown{ServiceSubtotal: totalPrice, GrandTotal: totalPrice}
+	if request.PaymentMethodID > 0 {
+		paymentMethod, err := u.paymentRepo.FindByID(request.PaymentMethodID)
+		if err != nil {
+			return nil, err
+		}
+		if paymentMethod == nil || paymentMethod.Status == "inactive" {
+			return nil, ErrPaymentMethodNotFound
+		}
+		fees = calculateFees(totalPrice, paymentMethod)
+	}
+
+	return &entities.InquiryQuote{
+		OutletID:       outletID,
+		Items:          details,
+		FeeBreakdown:   fees,
+		TotalPrice:     fees.GrandTotal,
+		EstimatedReady: estimatedReady,
+	}, nil
+}
+
+// resolveOutlet returns the user access of userID and the outlet it takes an inquiry at: the outlet of
+// an employee or outlet account, which outletID may only repeat, or for a cabang account outletID, which must be
+// an outlet of that cabang
+func (u *inquiryUsecase) resolveOutlet(userID, outletID int) (*entities.UserAccess, int, error) {
+	var ownOutletID int
+	userAccess, err := u.userAccessRepo.FindByID(userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, 0, ErrUserAccessNotFound
+		}
+		return nil, 0, err
+	}
+	if userAccess == nil {
+		return nil, 0, ErrUserAccessNotFound
+	}
+	if userAccess.ReferenceLevel != "cabang" {
+		switch userAccess.ReferenceLevel {
+		case "karyawan":
+			employee, err := u.employeeRepo.FindByID(userAccess.ReferenceID)
+			if err != nil {
+				return nil, 0, err
+			}
+			if employee == nil {
+				return nil, 0, ErrOutletNotFound
+			}
+			ownOutletID = employee.OutletID
+		case "outlet":
+			outlet, err := u.outletRepo.FindByID(userAccess.ReferenceID)
+			if err != nil {
+				return nil, 0, err
+			}
+			if outlet == nil {
+				return nil, 0, ErrOutletNotFound
+			}
+			ownOutletID = outlet.ID
+		default:
+			return nil, 0, apperror.Forbidden("Invalid Reference Level")
+		}
+		// an employee or outlet account only takes orders at its own outlet
+		if outletID != 0 && outletID != ownOutletID {
+			return nil, 0, ErrOutletNotFound
+		}
+	} else {
+		if outletID == 0 {
+			return nil, 0, apperror.Validation("Outlet ID CAnnot be Null")
+		}
+		//validasi outlet
+		outletArr, err := u.outletRepo.FindAll(entities.Outlet{
+			CabangID: userAccess.ReferenceID,
+			ID:       outletID,
+		}, entities.AccessScope{Level: entities.ReferenceLevelCabang, ReferenceID: userAccess.ReferenceID})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, 0, ErrOutletNotFound
+			}
+			return nil, 0, err
+		}
+		if len(outletArr) == 0 {
+			return nil, 0, ErrOutletNotFound
+		}
+	}
+	if outletID == 0 {
+		outletID = ownOutletID
+	}
+	return userAccess, outletID, nil
+}
+
+// priceItems prices every order line with the pricing rule of its service at the outlet and its modifiers,
+// and estimates when each line is ready as of t. It returns the lines, their total and when the order is ready.
+func (u *inquiryUsecase) priceItems(items []entities.InquiryItemRequest, outletID int, t time.Time,
+	username *string) ([]entities.TransactionDetail, float64, *time.Time, error) {
+	calendar, err := loadOutletCalendar(u.outletRepo, u.calendarRepo, outletID, t)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if len(items) == 0 {
+		return nil, 0, nil, apperror.Validation("items cannot be empty")
+	}
+	var (
+		details        []entities.TransactionDetail
+		totalPrice     float64
+		estimatedReady *time.Time
+		// soldAt caches whether the outlet belongs to a brand, by brand id
+		soldAt = map[int]bool{}
+	)
+	for i, item := range items {
+		if item.Quantity <= 0 {
+			return nil, 0, nil, apperror.Validation(fmt.Sprintf("invalid quantity on item %d", i+1))
+		}
+		servicePackage, err := u.serviceRepo.FindByID(item.ServicePackageID)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if servicePackage == nil {
+			return nil, 0, nil, fmt.Errorf("%w: item %d", ErrServiceNotFound, i+1)
+		}
+		// an outlet only sells the services of its own brand
+		sold, checked := soldAt[servicePackage.BrandID]
+		if !checked {
+			if sold, err = u.outletRepo.ExistsInScope(outletID, brandScope(servicePackage.BrandID)); err != nil {
+				return nil, 0, nil, err
+			}
+			soldAt[servicePackage.BrandID] = sold
+		}
+		if !sold {
+			return nil, 0, nil, fmt.Errorf("%w: item %d", ErrServiceNotFound, i+1)
+		}
+		rule, err := u.pricingRuleRepo.FindEffective(servicePackage.ID, outletID)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+
+		quantity := item.Quantity
+		line := priceLine(servicePackage, rule, quantity)
+		chargedQuantity, price, basePrice := line.ChargedQuantity, line.UnitPrice, line.BasePrice
+		modifiers, surcharge, timing, err := u.lineModifiers(i+1, item, servicePackage, basePrice)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		subtotal := basePrice + surcharge
+		totalPrice += subtotal
+
+		// the order is ready when its longest item is
+		itemReady := calendar.estimateCompletion(t, timing)
+		if itemReady != nil && (estimatedReady == nil || itemReady.After(*estimatedReady)) {
+			estimatedReady = itemReady
+		}
+
+		details = append(details, entities.TransactionDetail{
+			ServiceID:       servicePackage.ID,
+			Quantity:        &quantity,
+			ChargedQuantity: &chargedQuantity,
+			Price:           &price,
+			BasePrice:       &basePrice,
+			SurchargeTotal:  surcharge,
+			Subtotal:        &subtotal,
+			EstimatedReady:  itemReady,
+			Note:            item.Note,
+			CreatedAt:       t,
+			UpdatedAt:       t,
+			CreatedBy:       username,
+			UpdatedBy:       username,
+			Modifiers:       modifiers,
+		})
+	}
+	return details, totalPrice, estimatedReady, nil
+}
+
+// calculateFees adds the admin and merchant fee of a payment method to the service subtotal.
+// The fees are always recorded; they only raise the grand total when the customer bears them.
+func calculateFees(subtotal float64, paymentMethod *entities.PaymentMethod) entities.FeeBreakdown {
+	fees := entities.FeeBreakdown{
+		ServiceSubtotal: subtotal,
+		AdminFee:        paymentMethod.AdminFee,
+		MerchantFee:     paymentMethod.MerchantFee,
+		FeeBearer:       paymentMethod.FeeBearer,
+		GrandTotal:      subtotal,
+	}
+	if paymentMethod.MerchantFeeType == entities.MerchantFeePercent {
+		fees.MerchantFee = math.Round(subtotal*paymentMethod.MerchantFee) / 100
+	}
+	if fees.FeeBearer == "" {
+		fees.FeeBearer = entities.FeeBearerCustomer
+	}
+	if fees.FeeBearer == entities.FeeBearerCustomer {
+		fees.GrandTotal += fees.AdminFee + fees.MerchantFee
+	}
+	return fees
 }
 
 // stringPtr returns a pointer to the given string
@@ -2,12 +2,13 @@ package usecases
 
 import (
 	"laundry-backend/internal/entities"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 )
 
 type AuthUsecase interface {
-	Login(request entities.LoginRequest) (*entities.LoginResponse, error)
+	Login(request entities.LoginRequest, client entities.LoginClient) (*entities.LoginResponse, error)
 }
 
 type BrandUsecase interface {
@@ -30,75 +31,121 @@ type CabangUsecase interface {
 }
 
 type OutletUsecase interface {
-	CreateOutlet(request entities.RegisterOutletRequest) error
-	GetOutletByID(id int) (*entities.Outlet, error)
-	GetOutletsByCabangID(cabangID int) ([]entities.Outlet, error)
-	GetAllOutlets() ([]entities.Outlet, error)
-	GetAllOutletsDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error)
-	UpdateOutlet(id int, request entities.RegisterOutletRequest) error
-	DeleteOutlet(id int) error
+	CreateOutlet(request entities.RegisterOutletRequest, scope entities.AccessScope) error
+	GetOutletByID(id int, scope entities.AccessScope) (*entities.Outlet, error)
+	GetOutletsByCabangID(cabangID int, scope entities.AccessScope) ([]entities.Outlet, error)
+	GetAllOutlets(scope entities.AccessScope) ([]entities.Outlet, error)
+	GetAllOutletsDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error)
+	UpdateOutlet(id int, request entities.RegisterOutletRequest, scope entities.AccessScope) error
+	DeleteOutlet(id int, scope entities.AccessScope) error
+}
+
+type CalendarUsecase interface {
+	GetOpeningHours(outletID int, scope entities.AccessScope) ([]entities.OpeningHours, error)
+	UpdateOpeningHours(outletID int, request entities.OpeningHoursRequest, scope entities.AccessScope) ([]entities.OpeningHours, error)
+	GetOutletHolidays(outletID int, from, to time.Time, scope entities.AccessScope) ([]entities.Holiday, error)
+	GetAvailability(outletID int, at time.Time, scope entities.AccessScope) (*entities.OutletAvailability, error)
+	CreateHoliday(request entities.CreateHolidayRequest, scope entities.AccessScope) (*entities.Holiday, error)
+	DeleteHoliday(id int, scope entities.AccessScope) error
+}
+
+type ModifierUsecase interface {
+	CreateModifier(request entities.CreateModifierRequest, scope entities.AccessScope) (*entities.Modifier, error)
+	GetModifierByID(id int, scope entities.AccessScope) (*entities.Modifier, error)
+	GetModifiers(filter entities.ModifierFilter, scope entities.AccessScope) ([]entities.Modifier, error)
+	UpdateModifier(id int, request entities.UpdateModifierRequest, scope entities.AccessScope) (*entities.Modifier, error)
+	DeleteModifier(id int, scope entities.AccessScope) error
+}
+
+type PricingRuleUsecase interface {
+	GetPricingRules(serviceID int, scope entities.AccessScope) ([]entities.PricingRule, error)
+	SavePricingRule(serviceID int, request entities.SavePricingRuleRequest, scope entities.AccessScope) (*entities.PricingRule, error)
+	DeletePricingRule(id int, scope entities.AccessScope) error
 }
 
 type InquiryUsecase interface {
-	ProcessInquiry(request entities.InquiryRequest, claims jwt.MapClaims) (*entities.InquiryResponse, error)
+	ProcessInquiry(request entities.InquiryRequest, claims jwt.MapClaims, scope entities.AccessScope) (*entities.InquiryResponse, error)
+	QuoteInquiry(request entities.QuoteRequest) (*entities.InquiryQuote, error)
 }
 
 type EmployeeUsecase interface {
-	CreateEmployee(request entities.RegisterEmployeeRequest) error
-	GetEmployeeByID(id int) (*entities.Employee, error)
-	GetAllEmployees() ([]entities.Employee, error)
-	GetAllEmployeesDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error)
-	UpdateEmployee(id int, request entities.RegisterEmployeeRequest) error
-	DeleteEmployee(id int) error
+	CreateEmployee(request entities.RegisterEmployeeRequest, scope entities.AccessScope) error
+	GetEmployeeByID(id int, scope entities.AccessScope) (*entities.Employee, error)
+	GetAllEmployees(scope entities.AccessScope) ([]entities.Employee, error)
+	GetAllEmployeesDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error)
+	UpdateEmployee(id int, request entities.RegisterEmployeeRequest, scope entities.AccessScope) error
+	DeleteEmployee(id int, scope entities.AccessScope) error
 }
 
 type CustomerUsecase interface {
-	CreateCustomer(request entities.RegisterCustomerRequest) error
-	GetCustomerByID(id int) (*entities.Customer, error)
-	GetCustomersByOutletID(outletID int) ([]entities.Customer, error)
-	GetAllCustomers() ([]entities.Customer, error)
-	GetAllCustomersDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error)
-	UpdateCustomer(id int, request entities.RegisterCustomerRequest) error
-	DeleteCustomer(id int) error
+	CreateCustomer(request entities.RegisterCustomerRequest, scope entities.AccessScope) error
+	GetCustomerByID(id int, scope entities.AccessScope) (*entities.Customer, error)
+	GetCustomersByOutletID(outletID int, scope entities.AccessScope) ([]entities.Customer, error)
+	GetAllCustomers(scope entities.AccessScope) ([]entities.Customer, error)
+	GetAllCustomersDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error)
+	UpdateCustomer(id int, request entities.RegisterCustomerRequest, scope entities.AccessScope) error
+	DeleteCustomer(id int, scope entities.AccessScope) error
 }
 
 type ServiceUsecase interface {
-	CreateService(request entities.CreateServiceRequest) error
-	GetServiceByID(id int) (*entities.Service, error)
-	GetAllServices() ([]entities.Service, error)
-	GetAllServicesDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error)
-	UpdateService(id int, request entities.UpdateServiceRequest) error
-	DeleteService(id int) error
-	GetServicesByCategoryID(categoryID int) ([]entities.Service, error)
+	CreateService(request entities.CreateServiceRequest, scope entities.AccessScope) error
+	GetServiceByID(id int, scope entities.AccessScope) (*entities.Service, error)
+	GetAllServices(scope entities.AccessScope) ([]entities.Service, error)
+	GetAllServicesDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error)
+	UpdateService(id int, request entities.UpdateServiceRequest, scope entities.AccessScope) error
+	DeleteService(id int, scope entities.AccessScope) error
+	GetServicesByCategoryID(categoryID int, scope entities.AccessScope) ([]entities.Service, error)
 }
 
 type ServiceCategoryUsecase interface {
-	CreateServiceCategory(request entities.CreateServiceCategoryRequest) error
+	CreateServiceCategory(request entities.CreateServiceCategoryRequest, scope entities.AccessScope) error
 	GetServiceCategoryByID(id int) (*entities.ServiceCategory, error)
 	GetAllServiceCategories() ([]entities.ServiceCategory, error)
 	GetAllServiceCategoriesDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error)
-	UpdateServiceCategory(id int, request entities.UpdateServiceCategoryRequest) error
-	DeleteServiceCategory(id int) error
+	UpdateServiceCategory(id int, request entities.UpdateServiceCategoryRequest, scope entities.AccessScope) error
+	DeleteServiceCategory(id int, scope entities.AccessScope) error
 }
 
 type UserAccessUsecase interface {
-	CreateUserAccess(request entities.CreateUserAccessRequest) error
-	GetUserAccessByID(id int) (*entities.UserAccess, error)
-	GetAllUserAccess() ([]entities.UserAccess, error)
-	GetAllUserAccessDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error)
-	UpdateUserAccess(id int, request entities.UpdateUserAccessRequest) error
-	UpdateUserPassword(id int, request entities.UpdateUserPasswordRequest) error
-	DeleteUserAccess(id int) error
-	AuthenticateUser(request entities.UserLoginRequest) (*entities.UserLoginResponse, error)
+	CreateUserAccess(request entities.CreateUserAccessRequest, scope entities.AccessScope) error
+	GetUserAccessByID(id int, scope entities.AccessScope) (*entities.UserAccess, error)
+	GetAllUserAccess(scope entities.AccessScope) ([]entities.UserAccess, error)
+	GetAllUserAccessDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error)
+	UpdateUserAccess(id int, request entities.UpdateUserAccessRequest, scope entities.AccessScope) error
+	UpdateUserPassword(id int, request entities.UpdateUserPasswordRequest, self bool, scope entities.AccessScope, client entities.LoginClient) error
+	MustChangePassword(id int) (bool, error)
+	DeleteUserAccess(id int, scope entities.AccessScope) error
+	AuthenticateUser(request entities.UserLoginRequest, client entities.LoginClient) (*entities.UserLoginResponse, error)
+	UnlockUserAccess(id int, scope entities.AccessScope) error
+	IssuePasswordReset(id int, scope entities.AccessScope) (*entities.PasswordResetResponse, error)
+	ResetPassword(request entities.ResetPasswordRequest) error
+	RefreshToken(request entities.RefreshTokenRequest) (*entities.UserLoginResponse, error)
+	Logout(request entities.RefreshTokenRequest) error
+	RevokeSessions(id int, scope entities.AccessScope) (*entities.RevokeSessionsResponse, error)
 }
 
 type TransactionUsecase interface {
-	GetAllTransactions() ([]entities.Transaction, error)
-	GetAllTransactionsDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error)
-	GetTransactionByID(id int) (*entities.Transaction, error)
-	GetTransactionsByOutletID(outletID int) ([]entities.Transaction, error)
-	GetTransactionDetails(transactionID int) ([]entities.TransactionDetail, error)
-	UpdateTransactionStatus(id int, request entities.UpdateTransactionStatusRequest) error
-	UpdatePaymentStatus(id int, request entities.UpdatePaymentStatusRequest) error
-	ProcessPaymentCallback(request entities.PaymentCallbackRequest) error
+	GetAllTransactions(scope entities.AccessScope) ([]entities.Transaction, error)
+	GetAllTransactionsDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error)
+	GetTransactionByID(id int, scope entities.AccessScope) (*entities.Transaction, error)
+	GetTransactionsByOutletID(outletID int, scope entities.AccessScope) ([]entities.Transaction, error)
+	GetTransactionDetails(transactionID int, scope entities.AccessScope) ([]entities.TransactionDetail, error)
+	GetTransactionHistory(transactionID int, scope entities.AccessScope) (*entities.TransactionTimelineResponse, error)
+	UpdateTransactionStatus(id int, request entities.UpdateTransactionStatusRequest, claims jwt.MapClaims, scope entities.AccessScope) (*entities.HistoryStatusTransaction, error)
+	CancelTransaction(id int, request entities.CancelTransactionRequest, claims jwt.MapClaims, scope entities.AccessScope) (*entities.TransactionCancellationResponse, error)
+	GetRevenueReport(outletID int, from, to time.Time, scope entities.AccessScope) (*entities.RevenueReport, error)
+}
+
+type PaymentUsecase interface {
+	CreatePayment(transactionID int, request entities.CreatePaymentRequest, scope entities.AccessScope) (*entities.Payment, error)
+	GetPaymentsByTransactionID(transactionID int, scope entities.AccessScope) (*entities.TransactionPaymentsResponse, error)
+	UpdatePaymentStatus(id int, request entities.UpdatePaymentStatusRequest, scope entities.AccessScope) error
+	SyncPaymentStatus(id int, scope entities.AccessScope) (*entities.Payment, error)
+	ProcessPaymentCallback(payload []byte, signature, merchantKey string) (*entities.PaymentCallbackLog, error)
+}
+
+type IdempotencyUsecase interface {
+	Begin(owner, key, requestHash string) (*entities.IdempotencyKey, error)
+	Complete(record *entities.IdempotencyKey, statusCode int, responseBody string) error
+	Release(record *entities.IdempotencyKey) error
 }
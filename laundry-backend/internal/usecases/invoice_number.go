@@ -0,0 +1,194 @@
+package usecases
+
+import (
+	"database/sql"
+	"fmt"
+	"laundry-backend/internal/entities"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxInvoiceNumberLength is the size of transaksi.nomor_invoice
+	maxInvoiceNumberLength = 50
+	// defaultInvoiceSeqWidth pads {SEQ} when no width is given
+	defaultInvoiceSeqWidth = 5
+	// maxInvoiceSeqWidth keeps {SEQ:n} within what fits in nomor_invoice
+	maxInvoiceSeqWidth = 10
+	// maxInvoiceSeqDigits is the digits of the largest sequence number (int32 in urutan_invoice)
+	maxInvoiceSeqDigits = 10
+	// maxInvoiceCodeLength is the size of brand.kode_brand and outlet.kode_outlet
+	maxInvoiceCodeLength = 10
+
+	// fallbackBrandCode is used while a brand has no kode_brand
+	fallbackBrandCode = "INV"
+	// fallbackOutletCodePrefix starts the code of an outlet without kode_outlet. Codes are alphanumeric,
+	// so the id of one outlet can never render like the kode_outlet of another, such as "012".
+	fallbackOutletCodePrefix = "_"
+	// maxInvoiceOutletLength is what {OUTLET} renders to at most: a kode_outlet or the prefixed id of an outlet
+	maxInvoiceOutletLength = len(fallbackOutletCodePrefix) + maxInvoiceSeqDigits
+)
+
+// invoiceTokenPattern matches the placeholders of an invoice format
+var invoiceTokenPattern = regexp.MustCompile(`\{(BRAND|OUTLET|(?:YYYY|YY|MM|DD)+|SEQ(?::(\d+))?)\}`)
+
+// invoiceDateLayouts maps the date parts of a placeholder to Go time layouts
+var invoiceDateLayouts = []struct {
+	token  string
+	layout string
+}{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MM", "01"},
+	{"DD", "02"},
+}
+
+// checkInvoiceFormat returns ErrInvalidInvoiceFormat unless format contains {OUTLET} and {SEQ},
+// has no unknown placeholder and always renders within nomor_invoice. As the sequence restarts on the finest
+// date part, a month needs a year and a day needs both, or the numbers repeat the next year or month.
+func checkInvoiceFormat(format string) error {
+	var hasOutlet, hasSeq bool
+	length := 0
+	last := 0
+	for _, match := range invoiceTokenPattern.FindAllStringSubmatchIndex(format, -1) {
+		length += len(format[last:match[0]])
+		last = match[1]
+
+		token := format[match[2]:match[3]]
+		switch {
+		case token == "BRAND":
+			length += maxInvoiceCodeLength
+		case token == "OUTLET":
+			hasOutlet = true
+			length += maxInvoiceOutletLength
+		case strings.HasPrefix(token, "SEQ"):
+			hasSeq = true
+			width := defaultInvoiceSeqWidth
+			if match[4] >= 0 {
+				width, _ = strconv.Atoi(format[match[4]:match[5]])
+				if width < 1 || width > maxInvoiceSeqWidth {
+					return ErrInvalidInvoiceFormat
+				}
+			}
+			if width < maxInvoiceSeqDigits {
+				width = maxInvoiceSeqDigits
+			}
+			length += width
+		default:
+			length += len(formatInvoiceDate(token, time.Time{}))
+		}
+	}
+	length += len(format[last:])
+
+	// a brace left outside a placeholder is a typo such as {SEQ5} or {YYMMM}
+	literals := invoiceTokenPattern.ReplaceAllString(format, "")
+	if strings.ContainsAny(literals, "{}") {
+		return ErrInvalidInvoiceFormat
+	}
+	if !hasOutlet || !hasSeq || length > maxInvoiceNumberLength {
+		return ErrInvalidInvoiceFormat
+	}
+	hasYear, hasMonth, hasDay := invoiceDateParts(format)
+	if (hasMonth && !hasYear) || (hasDay && !(hasYear && hasMonth)) {
+		return ErrInvalidInvoiceFormat
+	}
+	return nil
+}
+
+// invoiceDateParts reports which date parts the placeholders of format use
+func invoiceDateParts(format string) (hasYear, hasMonth, hasDay bool) {
+	for _, match := range invoiceTokenPattern.FindAllStringSubmatch(format, -1) {
+		token := match[1]
+		if token == "BRAND" || token == "OUTLET" || strings.HasPrefix(token, "SEQ") {
+			continue
+		}
+		hasYear = hasYear || strings.Contains(token, "YY")
+		hasMonth = hasMonth || strings.Contains(token, "MM")
+		hasDay = hasDay || strings.Contains(token, "DD")
+	}
+	return hasYear, hasMonth, hasDay
+}
+
+// invoicePeriod is the key the sequence restarts on: the finest date part used by format,
+// so {YYMM} numbers per month, {YYMMDD} per day and a format without a date never restarts
+func invoicePeriod(format string, t time.Time) string {
+	hasYear, hasMonth, hasDay := invoiceDateParts(format)
+	switch {
+	case hasDay:
+		return t.Format("20060102")
+	case hasMonth:
+		return t.Format("200601")
+	case hasYear:
+		return t.Format("2006")
+	}
+	return ""
+}
+
+// formatInvoiceDate renders a date placeholder such as YYMM
+func formatInvoiceDate(token string, t time.Time) string {
+	var b strings.Builder
+	for token != "" {
+		for _, part := range invoiceDateLayouts {
+			if strings.HasPrefix(token, part.token) {
+				b.WriteString(t.Format(part.layout))
+				token = token[len(part.token):]
+				break
+			}
+		}
+	}
+	return b.String()
+}
+
+// formatInvoiceNumber renders the invoice format of settings for sequence number seq issued at t
+func formatInvoiceNumber(settings entities.InvoiceSettings, seq int, t time.Time) string {
+	brandCode := settings.BrandCode
+	if brandCode == "" {
+		brandCode = fallbackBrandCode
+	}
+	outletCode := settings.OutletCode
+	if outletCode == "" {
+		outletCode = fmt.Sprintf("%s%03d", fallbackOutletCodePrefix, settings.OutletID)
+	}
+
+	return invoiceTokenPattern.ReplaceAllStringFunc(settings.Format, func(placeholder string) string {
+		match := invoiceTokenPattern.FindStringSubmatch(placeholder)
+		token := match[1]
+		switch {
+		case token == "BRAND":
+			return brandCode
+		case token == "OUTLET":
+			return outletCode
+		case strings.HasPrefix(token, "SEQ"):
+			width := defaultInvoiceSeqWidth
+			if match[2] != "" {
+				width, _ = strconv.Atoi(match[2])
+			}
+			return fmt.Sprintf("%0*d", width, seq)
+		}
+		return formatInvoiceDate(token, t)
+	})
+}
+
+// nextInvoiceNumber takes the next number of the outlet's sequence inside tx. The sequence row stays locked
+// until tx ends, so concurrent transactions of one outlet are numbered one after the other and a rollback
+// gives the number back instead of leaving a gap.
+func (u *inquiryUsecase) nextInvoiceNumber(tx *sql.Tx, outletID int, t time.Time) (string, error) {
+	settings, err := u.inquiryRepo.FindInvoiceSettingsWithTx(tx, outletID)
+	if err != nil {
+		return "", err
+	}
+	if settings == nil {
+		return "", ErrOutletNotFound
+	}
+	if settings.Format == "" {
+		settings.Format = entities.DefaultInvoiceFormat
+	}
+
+	seq, err := u.inquiryRepo.NextInvoiceSequenceWithTx(tx, outletID, invoicePeriod(settings.Format, t))
+	if err != nil {
+		return "", err
+	}
+	return formatInvoiceNumber(*settings, seq, t), nil
+}
@@ -0,0 +1,54 @@
+package usecases
+
+import (
+	"laundry-backend/internal/entities"
+	"testing"
+	"time"
+)
+
+func TestCheckInvoiceFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		valid  bool
+	}{
+		{format: entities.DefaultInvoiceFormat, valid: true},
+		{format: "{OUTLET}{SEQ}", valid: true},
+		{format: "{OUTLET}-{YY}-{SEQ}", valid: true},
+		{format: "{OUTLET}-{YYYY}{MM}-{SEQ}", valid: true},
+		{format: "{OUTLET}/{YYMMDD}/{SEQ:4}", valid: true},
+		{format: "{BRAND}-{SEQ}"},
+		{format: "{OUTLET}-{YYMM}"},
+		{format: "{OUTLET}-{SEQ5}"},
+		{format: "{OUTLET}-{SEQ:11}"},
+		// the month would repeat every year, the day every month
+		{format: "{OUTLET}-{MM}-{SEQ}"},
+		{format: "{OUTLET}{DD}{SEQ}"},
+		{format: "{OUTLET}-{YY}{DD}-{SEQ}"},
+		{format: "{OUTLET}-{MMDD}-{SEQ}"},
+	}
+
+	for _, tt := range tests {
+		err := checkInvoiceFormat(tt.format)
+		if tt.valid && err != nil {
+			t.Errorf("checkInvoiceFormat(%q) = %v, want nil", tt.format, err)
+		}
+		if !tt.valid && err != ErrInvalidInvoiceFormat {
+			t.Errorf("checkInvoiceFormat(%q) = %v, want %v", tt.format, err, ErrInvalidInvoiceFormat)
+		}
+	}
+}
+
+func TestFormatInvoiceNumberFallbackOutletCode(t *testing.T) {
+	issued := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	settings := entities.InvoiceSettings{Format: "{OUTLET}-{YYMM}-{SEQ:3}", OutletID: 12}
+
+	if got, want := formatInvoiceNumber(settings, 7, issued), "_012-2610-007"; got != want {
+		t.Errorf("without kode_outlet = %q, want %q", got, want)
+	}
+
+	// another outlet whose kode_outlet looks like the id of the first
+	settings.OutletID, settings.OutletCode = 40, "012"
+	if got, want := formatInvoiceNumber(settings, 7, issued), "012-2610-007"; got != want {
+		t.Errorf("with kode_outlet = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package usecases
+
+import (
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/repositories"
+	"laundry-backend/internal/utils"
+	"time"
+)
+
+// loginGuard throttles password logins per client IP and per username and audits every attempt.
+// The per-username counter lives on the users/user_access row, the per-IP count comes from login_audit.
+type loginGuard struct {
+	auditRepo repositories.LoginAuditRepository
+	policy    utils.LoginConfig
+}
+
+func newLoginGuard(auditRepo repositories.LoginAuditRepository, policy utils.LoginConfig) *loginGuard {
+	return &loginGuard{auditRepo: auditRepo, policy: policy}
+}
+
+// lockout is how long a username stays locked and how far back failed attempts of an IP are counted
+func (g *loginGuard) lockout() time.Duration {
+	return time.Duration(g.policy.LockoutMinutes) * time.Minute
+}
+
+// checkClient refuses the attempt when the client IP already failed too often
+func (g *loginGuard) checkClient(loginType, username string, client entities.LoginClient) error {
+	if g.policy.IPMaxAttempts <= 0 || client.IPAddress == "" {
+		return nil
+	}
+
+	failed, err := g.auditRepo.CountFailedByIP(client.IPAddress, time.Now().Add(-g.lockout()))
+	if err != nil {
+		return err
+	}
+	if failed < g.policy.IPMaxAttempts {
+		return nil
+	}
+
+	if err := g.fail(loginType, username, client, entities.LoginFailureTooManyAttempts); err != nil {
+		return err
+	}
+	return ErrTooManyLoginAttempts
+}
+
+// checkLocked refuses the attempt while the username is locked, without checking the password
+func (g *loginGuard) checkLocked(loginType, username string, lockedUntil *time.Time, client entities.LoginClient) error {
+	if lockedUntil == nil || !time.Now().Before(*lockedUntil) {
+		return nil
+	}
+
+	if err := g.fail(loginType, username, client, entities.LoginFailureAccountLocked); err != nil {
+		return err
+	}
+	return ErrAccountLocked
+}
+
+// fail records a rejected attempt
+func (g *loginGuard) fail(loginType, username string, client entities.LoginClient, reason string) error {
+	return g.auditRepo.Create(&entities.LoginAudit{
+		LoginType:     loginType,
+		Username:      username,
+		IPAddress:     client.IPAddress,
+		UserAgent:     client.UserAgent,
+		FailureReason: reason,
+	})
+}
+
+// succeed records a successful attempt
+func (g *loginGuard) succeed(loginType, username string, client entities.LoginClient) error {
+	return g.auditRepo.Create(&entities.LoginAudit{
+		LoginType: loginType,
+		Username:  username,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Success:   true,
+	})
+}
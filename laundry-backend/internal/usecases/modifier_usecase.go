@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/repositories"
+)
+
+type modifierUsecase struct {
+	modifierRepo        repositories.ModifierRepository
+	brandRepo           repositories.BrandRepository
+	serviceRepo         repositories.ServiceRepository
+	serviceCategoryRepo repositories.ServiceCategoryRepository
+}
+
+func NewModifierUsecase(modifierRepo repositories.ModifierRepository, brandRepo repositories.BrandRepository,
+	serviceRepo repositories.ServiceRepository, serviceCategoryRepo repositories.ServiceCategoryRepository) ModifierUsecase {
+	return &modifierUsecase{
+		modifierRepo:        modifierRepo,
+		brandRepo:           brandRepo,
+		serviceRepo:         serviceRepo,
+		serviceCategoryRepo: serviceCategoryRepo,
+	}
+}
+
+// CreateModifier adds a modifier to a brand; only an administrator or an account of that brand may do so
+func (u *modifierUsecase) CreateModifier(request entities.CreateModifierRequest, scope entities.AccessScope) (*entities.Modifier, error) {
+	brand, err := u.brandRepo.FindByID(request.BrandID)
+	if err != nil {
+		return nil, err
+	}
+	if brand == nil {
+		return nil, ErrBrandNotFound
+	}
+	if err := checkBrandManager(u.brandRepo, brand.ID, scope, ErrBrandNotFound, ErrModifierForbidden); err != nil {
+		return nil, err
+	}
+
+	modifier := &entities.Modifier{
+		BrandID:    request.BrandID,
+		ServiceID:  request.ServiceID,
+		CategoryID: request.CategoryID,
+		Name:       request.Name,
+		Type:       request.Type,
+		ValueType:  request.ValueType,
+		Value:      request.Value,
+		Estimation: request.Estimation,
+		Unit:       request.Unit,
+		Active:     request.Active == nil || *request.Active,
+	}
+	if err := u.checkModifier(modifier); err != nil {
+		return nil, err
+	}
+	if err := u.modifierRepo.Create(modifier); err != nil {
+		return nil, err
+	}
+	return modifier, nil
+}
+
+// GetModifierByID returns a modifier of the caller's brand; another brand's modifier is reported as not found
+func (u *modifierUsecase) GetModifierByID(id int, scope entities.AccessScope) (*entities.Modifier, error) {
+	modifier, err := u.modifierRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if modifier == nil {
+		return nil, ErrModifierNotFound
+	}
+	if err := checkBrandScope(u.brandRepo, modifier.BrandID, scope, ErrModifierNotFound); err != nil {
+		return nil, err
+	}
+	return modifier, nil
+}
+
+func (u *modifierUsecase) GetModifiers(filter entities.ModifierFilter, scope entities.AccessScope) ([]entities.Modifier, error) {
+	return u.modifierRepo.FindAll(filter, scope)
+}
+
+// findManagedModifier returns a modifier the caller may change: of their own brand, and only at the brand level
+func (u *modifierUsecase) findManagedModifier(id int, scope entities.AccessScope) (*entities.Modifier, error) {
+	modifier, err := u.GetModifierByID(id, scope)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBrandManager(u.brandRepo, modifier.BrandID, scope, ErrModifierNotFound, ErrModifierForbidden); err != nil {
+		return nil, err
+	}
+	return modifier, nil
+}
+
+// UpdateModifier replaces the settings of a modifier; the lines it was already applied to keep their copy
+func (u *modifierUsecase) UpdateModifier(id int, request entities.UpdateModifierRequest, scope entities.AccessScope) (*entities.Modifier, error) {
+	modifier, err := u.findManagedModifier(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	modifier.ServiceID = request.ServiceID
+	modifier.CategoryID = request.CategoryID
+	modifier.Name = request.Name
+	modifier.Type = request.Type
+	modifier.ValueType = request.ValueType
+	modifier.Value = request.Value
+	modifier.Estimation = request.Estimation
+	modifier.Unit = request.Unit
+	if request.Active != nil {
+		modifier.Active = *request.Active
+	}
+	if err := u.checkModifier(modifier); err != nil {
+		return nil, err
+	}
+	if err := u.modifierRepo.Update(modifier); err != nil {
+		return nil, err
+	}
+	return modifier, nil
+}
+
+func (u *modifierUsecase) DeleteModifier(id int, scope entities.AccessScope) error {
+	if _, err := u.findManagedModifier(id, scope); err != nil {
+		return err
+	}
+	return u.modifierRepo.Delete(id)
+}
+
+// checkModifier rejects an add-on that is not flat and a modifier that targets both a service and a category
+// or a service of another brand
+func (u *modifierUsecase) checkModifier(modifier *entities.Modifier) error {
+	if modifier.Type == entities.ModifierAddon && modifier.ValueType != entities.ModifierValueFlat {
+		return ErrInvalidModifier
+	}
+	if modifier.ServiceID != nil && modifier.CategoryID != nil {
+		return ErrInvalidModifier
+	}
+	if modifier.Estimation <= 0 {
+		modifier.Estimation, modifier.Unit = 0, ""
+	}
+
+	if modifier.ServiceID != nil {
+		service, err := u.serviceRepo.FindByID(*modifier.ServiceID)
+		if err != nil {
+			return err
+		}
+		if service == nil {
+			return ErrServiceNotFound
+		}
+		if service.BrandID != modifier.BrandID {
+			return ErrInvalidModifier
+		}
+	}
+	if modifier.CategoryID != nil {
+		category, err := u.serviceCategoryRepo.FindByID(*modifier.CategoryID)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return ErrServiceCategoryNotFound
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"fmt"
+	"laundry-backend/internal/apperror"
+	"laundry-backend/internal/entities"
+	"math"
+	"time"
+)
+
+// modifierApplies reports whether modifier can be selected on an order line of service
+func modifierApplies(modifier *entities.Modifier, service *entities.Service) bool {
+	switch {
+	case !modifier.Active || modifier.BrandID != service.BrandID:
+		return false
+	case modifier.ServiceID != nil:
+		return *modifier.ServiceID == service.ID
+	case modifier.CategoryID != nil:
+		return *modifier.CategoryID == service.CategoryID
+	}
+	return true
+}
+
+// modifierAmount returns what modifier adds to an order line whose base price is base: a percentage of it,
+// a flat amount once per line, or the add-on price for every piece
+func modifierAmount(modifier *entities.Modifier, base, quantity float64) float64 {
+	switch {
+	case modifier.Type == entities.ModifierAddon:
+		return modifier.Value * quantity
+	case modifier.ValueType == entities.ModifierValuePercent:
+		return math.Round(base*modifier.Value) / 100
+	}
+	return modifier.Value
+}
+
+// modifierDuration is the durasi_pengerjaan a modifier gives its service, 0 when it keeps the service's own
+func modifierDuration(modifier *entities.Modifier) time.Duration {
+	if modifier.Estimation <= 0 {
+		return 0
+	}
+	if modifier.Unit == entities.DurationUnitHour {
+		return time.Duration(modifier.Estimation) * time.Hour
+	}
+	return time.Duration(modifier.Estimation) * 24 * time.Hour
+}
+
+// lineModifiers prices the modifiers selected on order line number line of service, whose base price is base.
+// It returns the modifiers as they are stored on the line, their total, and the service with the shortest
+// durasi_pengerjaan given by a selected modifier, such as express, to estimate the line with.
+func (u *inquiryUsecase) lineModifiers(line int, item entities.InquiryItemRequest, service *entities.Service,
+	base float64) ([]entities.TransactionDetailModifier, float64, *entities.Service, error) {
+	var (
+		applied  = []entities.TransactionDetailModifier{}
+		total    float64
+		timing   = service
+		shortest time.Duration
+		selected = make(map[int]bool, len(item.Modifiers))
+	)
+	for _, selection := range item.Modifiers {
+		if selected[selection.ModifierID] {
+			return nil, 0, nil, apperror.Validation(fmt.Sprintf("duplicate modifier %d on item %d", selection.ModifierID, line))
+		}
+		selected[selection.ModifierID] = true
+
+		modifier, err := u.modifierRepo.FindByID(selection.ModifierID)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if modifier == nil {
+			return nil, 0, nil, fmt.Errorf("%w: item %d", ErrModifierNotFound, line)
+		}
+		if !modifierApplies(modifier, service) {
+			return nil, 0, nil, fmt.Errorf("%w: item %d", ErrModifierNotApplicable, line)
+		}
+
+		// a surcharge is charged once per line, an add-on for every piece
+		quantity := 1.0
+		if modifier.Type == entities.ModifierAddon && selection.Quantity > 0 {
+			quantity = selection.Quantity
+		}
+		amount := modifierAmount(modifier, base, quantity)
+		total += amount
+
+		if duration := modifierDuration(modifier); duration > 0 && (shortest == 0 || duration < shortest) {
+			shortest = duration
+			override := *service
+			override.Estimation, override.Unit = modifier.Estimation, modifier.Unit
+			timing = &override
+		}
+
+		modifierID := modifier.ID
+		applied = append(applied, entities.TransactionDetailModifier{
+			ModifierID: &modifierID,
+			Name:       modifier.Name,
+			Type:       modifier.Type,
+			ValueType:  modifier.ValueType,
+			Value:      modifier.Value,
+			Quantity:   quantity,
+			Amount:     amount,
+		})
+	}
+	return applied, total, timing, nil
+}
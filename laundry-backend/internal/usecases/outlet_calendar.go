@@ -0,0 +1,279 @@
+package usecases
+
+import (
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/repositories"
+	"time"
+)
+
+// maxEstimateDays bounds how far ahead an estimated completion is searched for,
+// so an outlet that is never open does not loop forever
+const maxEstimateDays = 366
+
+// openingDay is when an outlet opens and closes on one weekday, as the time since midnight.
+// A closeAt past 24h means the outlet closes after midnight.
+type openingDay struct {
+	closed  bool
+	allDay  bool
+	openAt  time.Duration
+	closeAt time.Duration
+}
+
+// outletCalendar tells when an outlet is open from its weekly opening hours and its holidays,
+// both read as wall-clock times and dates in the outlet's time zone
+type outletCalendar struct {
+	days     [7]openingDay
+	holidays map[string]entities.Holiday
+	location *time.Location
+}
+
+// newOutletCalendar builds the calendar from the effective hours of every weekday;
+// a day without valid hours, or with the same opening and closing time, is open all day
+func newOutletCalendar(hours []entities.OpeningHours, holidays []entities.Holiday, location *time.Location) outletCalendar {
+	calendar := outletCalendar{holidays: make(map[string]entities.Holiday, len(holidays)), location: location}
+	for _, holiday := range holidays {
+		calendar.holidays[holiday.Date] = holiday
+	}
+
+	for day := range calendar.days {
+		calendar.days[day] = openingDay{allDay: true}
+	}
+	for _, hours := range hours {
+		if hours.Weekday < 0 || hours.Weekday >= len(calendar.days) {
+			continue
+		}
+		calendar.days[hours.Weekday] = newOpeningDay(hours)
+	}
+	return calendar
+}
+
+func newOpeningDay(hours entities.OpeningHours) openingDay {
+	if hours.Closed {
+		return openingDay{closed: true}
+	}
+
+	openAt, openErr := parseClock(hours.OpenTime)
+	closeAt, closeErr := parseClock(hours.CloseTime)
+	if openErr != nil || closeErr != nil || openAt == closeAt {
+		return openingDay{allDay: true}
+	}
+	if closeAt < openAt {
+		closeAt += 24 * time.Hour
+	}
+	return openingDay{openAt: openAt, closeAt: closeAt}
+}
+
+// effectiveOpeningHours returns the hours of every weekday: the stored jam_operasional_outlet row
+// where there is one, the outlet's jam_buka/jam_tutup otherwise
+func effectiveOpeningHours(outlet *entities.Outlet, stored []entities.OpeningHours) []entities.OpeningHours {
+	week := make([]entities.OpeningHours, 7)
+	for day := range week {
+		week[day] = entities.OpeningHours{
+			OutletID:  outlet.ID,
+			Weekday:   day,
+			OpenTime:  outlet.OpenTime,
+			CloseTime: outlet.CloseTime,
+		}
+	}
+	for _, hours := range stored {
+		if hours.Weekday >= 0 && hours.Weekday < len(week) {
+			week[hours.Weekday] = hours
+		}
+	}
+	return week
+}
+
+// loadOutletCalendar reads the outlet's opening hours and its holidays from the day before from,
+// for a window that opened then, up to the estimate horizon
+func loadOutletCalendar(outletRepo repositories.OutletRepository, calendarRepo repositories.CalendarRepository,
+	outletID int, from time.Time) (outletCalendar, error) {
+	outlet, err := outletRepo.FindByID(outletID)
+	if err != nil {
+		return outletCalendar{}, err
+	}
+	if outlet == nil {
+		return outletCalendar{}, ErrOutletNotFound
+	}
+	location, err := outletLocation(outlet)
+	if err != nil {
+		return outletCalendar{}, err
+	}
+
+	hours, err := calendarRepo.FindOpeningHours(outletID)
+	if err != nil {
+		return outletCalendar{}, err
+	}
+	holidays, err := calendarRepo.FindHolidaysByOutlet(outletID, from.AddDate(0, 0, -1), from.AddDate(0, 0, maxEstimateDays))
+	if err != nil {
+		return outletCalendar{}, err
+	}
+	return newOutletCalendar(effectiveOpeningHours(outlet, hours), holidays, location), nil
+}
+
+// outletLocation returns the outlet's zona_waktu, the default one for an outlet stored without it
+func outletLocation(outlet *entities.Outlet) (*time.Location, error) {
+	if outlet.Timezone == "" {
+		return time.LoadLocation(entities.DefaultOutletTimezone)
+	}
+	return time.LoadLocation(outlet.Timezone)
+}
+
+// parseClock parses a TIME column such as 08:00:00 into the time since midnight
+func parseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04:05", value)
+	if err != nil {
+		clock, err = time.Parse("15:04", value)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute +
+		time.Duration(clock.Second())*time.Second, nil
+}
+
+// window returns when the outlet opens and closes for the outlet's date of day, false when it is closed all day
+func (c outletCalendar) window(day time.Time) (time.Time, time.Time, bool) {
+	day = day.In(c.location)
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, c.location)
+	if _, ok := c.holidays[midnight.Format("2006-01-02")]; ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	opening := c.days[midnight.Weekday()]
+	switch {
+	case opening.closed:
+		return time.Time{}, time.Time{}, false
+	case opening.allDay:
+		return midnight, midnight.AddDate(0, 0, 1), true
+	}
+	return midnight.Add(opening.openAt), midnight.Add(opening.closeAt), true
+}
+
+// availability tells whether the outlet is open at, with when it closes or else when and why it is closed,
+// in the outlet's time zone. Windows that follow each other without a break, such as two days open all day, count as one.
+func (c outletCalendar) availability(at time.Time) entities.OutletAvailability {
+	at = at.In(c.location)
+	availability := entities.OutletAvailability{At: at}
+	var closesAt time.Time
+	closes := c.eachWindow(at, func(start, end time.Time) bool {
+		switch {
+		case availability.Open && start.Equal(closesAt):
+			closesAt = end
+			return false
+		case availability.Open:
+			return true
+		case !start.After(at):
+			availability.Open = true
+			closesAt = end
+			return false
+		}
+		availability.OpensAt = &start
+		return true
+	})
+	if availability.Open {
+		// an outlet open around the clock does not close within the horizon
+		if closes {
+			availability.ClosesAt = &closesAt
+		}
+		return availability
+	}
+
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, c.location)
+	if holiday, ok := c.holidays[day.Format("2006-01-02")]; ok {
+		availability.Reason = entities.ClosedHoliday
+		availability.Holiday = &holiday
+	} else if c.days[day.Weekday()].closed {
+		availability.Reason = entities.ClosedDay
+	} else {
+		availability.Reason = entities.ClosedOutsideHours
+	}
+	return availability
+}
+
+// eachWindow calls fn with every opening window that ends after from, in order, until fn returns true.
+// It returns false when no window within maxEstimateDays satisfied fn.
+func (c outletCalendar) eachWindow(from time.Time, fn func(start, end time.Time) bool) bool {
+	// start a day early for a window that opened yesterday and closes after midnight
+	day := from.In(c.location).AddDate(0, 0, -1)
+	for i := 0; i <= maxEstimateDays; i++ {
+		start, end, ok := c.window(day.AddDate(0, 0, i))
+		if !ok || !end.After(from) {
+			continue
+		}
+		if fn(start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// addOpenHours returns the moment d of opening hours have passed since from
+func (c outletCalendar) addOpenHours(from time.Time, d time.Duration) (time.Time, bool) {
+	var ready time.Time
+	found := c.eachWindow(from, func(start, end time.Time) bool {
+		if start.Before(from) {
+			start = from
+		}
+		if available := end.Sub(start); d > available {
+			d -= available
+			return false
+		}
+		ready = start.Add(d)
+		return true
+	})
+	return ready, found
+}
+
+// addOpenDays returns the same time of day as from, days opening days later; an order received
+// while the outlet is closed counts from the next opening
+func (c outletCalendar) addOpenDays(from time.Time, days int) (time.Time, bool) {
+	var (
+		ready   time.Time
+		offset  time.Duration
+		counted = -1
+	)
+	found := c.eachWindow(from, func(start, end time.Time) bool {
+		if counted < 0 {
+			// the window the order is received in
+			if from.After(start) {
+				offset = from.Sub(start)
+			}
+			counted = 0
+			return false
+		}
+		counted++
+		if counted < days {
+			return false
+		}
+		ready = start.Add(offset)
+		if ready.After(end) {
+			ready = end
+		}
+		return true
+	})
+	return ready, found
+}
+
+// estimateCompletion returns when an item of service received at from should be ready, in the time zone of from
+// like the other timestamps of the transaksi; nil when the service has no durasi_pengerjaan or the outlet
+// is not open within the horizon
+func (c outletCalendar) estimateCompletion(from time.Time, service *entities.Service) *time.Time {
+	if service.Estimation <= 0 {
+		return nil
+	}
+
+	var (
+		ready time.Time
+		ok    bool
+	)
+	if service.Unit == entities.DurationUnitHour {
+		ready, ok = c.addOpenHours(from, time.Duration(service.Estimation)*time.Hour)
+	} else {
+		ready, ok = c.addOpenDays(from, service.Estimation)
+	}
+	if !ok {
+		return nil
+	}
+	ready = ready.In(from.Location())
+	return &ready
+}
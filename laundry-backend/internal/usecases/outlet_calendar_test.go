@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"laundry-backend/internal/entities"
+	"testing"
+	"time"
+)
+
+// testCalendar is open 08:00-17:00 in WITA, Saturday 10:00-02:00 and closed on Sunday,
+// with a holiday on Tuesday 2026-10-20
+func testCalendar(t *testing.T) (outletCalendar, func(day, hour, minute int) time.Time) {
+	t.Helper()
+	location, err := time.LoadLocation("Asia/Makassar")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	outlet := &entities.Outlet{ID: 1, OpenTime: "08:00:00", CloseTime: "17:00:00"}
+	stored := []entities.OpeningHours{
+		{OutletID: 1, Weekday: 0, Closed: true},
+		{OutletID: 1, Weekday: 6, OpenTime: "10:00:00", CloseTime: "02:00:00"},
+	}
+	holidays := []entities.Holiday{{Type: entities.HolidayNational, Date: "2026-10-20", Name: "Libur"}}
+
+	calendar := newOutletCalendar(effectiveOpeningHours(outlet, stored), holidays, location)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, location)
+	}
+	return calendar, at
+}
+
+func TestOutletCalendarAvailability(t *testing.T) {
+	calendar, at := testCalendar(t)
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		open     bool
+		reason   string
+		closesAt time.Time
+		opensAt  time.Time
+	}{
+		{name: "saturday evening", at: at(17, 23, 0), open: true, closesAt: at(18, 2, 0)},
+		{name: "after midnight on saturday's hours", at: at(18, 1, 0), open: true, closesAt: at(18, 2, 0)},
+		{name: "closed sunday", at: at(18, 3, 0), reason: entities.ClosedDay, opensAt: at(19, 8, 0)},
+		{name: "before opening", at: at(19, 7, 0), reason: entities.ClosedOutsideHours, opensAt: at(19, 8, 0)},
+		{name: "within hours", at: at(19, 12, 0), open: true, closesAt: at(19, 17, 0)},
+		{name: "holiday", at: at(20, 12, 0), reason: entities.ClosedHoliday, opensAt: at(21, 8, 0)},
+		// 00:30 UTC is 08:30 WITA, but still 07:30 in a server running on WIB
+		{name: "time given in UTC", at: at(19, 8, 30).UTC(), open: true, closesAt: at(19, 17, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calendar.availability(tt.at)
+			if got.Open != tt.open || got.Reason != tt.reason {
+				t.Fatalf("availability(%v) open = %v, reason = %q, want %v, %q", tt.at, got.Open, got.Reason, tt.open, tt.reason)
+			}
+			if tt.open && (got.ClosesAt == nil || !got.ClosesAt.Equal(tt.closesAt)) {
+				t.Errorf("availability(%v) closes at %v, want %v", tt.at, got.ClosesAt, tt.closesAt)
+			}
+			if !tt.open && (got.OpensAt == nil || !got.OpensAt.Equal(tt.opensAt)) {
+				t.Errorf("availability(%v) opens at %v, want %v", tt.at, got.OpensAt, tt.opensAt)
+			}
+			if tt.reason == entities.ClosedHoliday && got.Holiday == nil {
+				t.Errorf("availability(%v) has no holiday", tt.at)
+			}
+		})
+	}
+}
+
+func TestOutletCalendarAddOpenDays(t *testing.T) {
+	calendar, at := testCalendar(t)
+
+	tests := []struct {
+		name string
+		from time.Time
+		days int
+		want time.Time
+	}{
+		{name: "next opening day", from: at(16, 10, 0), days: 1, want: at(17, 12, 0)},
+		{name: "skips the holiday", from: at(19, 10, 0), days: 1, want: at(21, 10, 0)},
+		{name: "skips sunday and the holiday", from: at(16, 9, 0), days: 3, want: at(21, 9, 0)},
+		{name: "received while closed counts from the next opening", from: at(18, 12, 0), days: 1, want: at(21, 8, 0)},
+		// 13 hours into saturday's window, more than monday is open
+		{name: "capped at closing time", from: at(17, 23, 0), days: 1, want: at(19, 17, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := calendar.addOpenDays(tt.from, tt.days)
+			if !ok || !got.Equal(tt.want) {
+				t.Errorf("addOpenDays(%v, %d) = %v, %v, want %v", tt.from, tt.days, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutletCalendarAddOpenHours(t *testing.T) {
+	calendar, at := testCalendar(t)
+
+	tests := []struct {
+		name  string
+		from  time.Time
+		hours int
+		want  time.Time
+	}{
+		{name: "within the window", from: at(19, 9, 0), hours: 3, want: at(19, 12, 0)},
+		{name: "skips the holiday", from: at(19, 16, 0), hours: 3, want: at(21, 10, 0)},
+		{name: "across midnight and the closed sunday", from: at(17, 23, 0), hours: 4, want: at(19, 9, 0)},
+		{name: "received while closed", from: at(18, 12, 0), hours: 2, want: at(19, 10, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := calendar.addOpenHours(tt.from, time.Duration(tt.hours)*time.Hour)
+			if !ok || !got.Equal(tt.want) {
+				t.Errorf("addOpenHours(%v, %dh) = %v, %v, want %v", tt.from, tt.hours, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutletCalendarNeverOpen(t *testing.T) {
+	hours := make([]entities.OpeningHours, 7)
+	for day := range hours {
+		hours[day] = entities.OpeningHours{Weekday: day, Closed: true}
+	}
+	calendar := newOutletCalendar(hours, nil, time.UTC)
+
+	if _, ok := calendar.addOpenDays(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC), 1); ok {
+		t.Error("addOpenDays found a day on a calendar that is never open")
+	}
+}
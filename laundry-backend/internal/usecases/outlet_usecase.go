@@ -7,15 +7,33 @@ import (
 
 type outletUsecase struct {
 	outletRepo repositories.OutletRepository
+	cabangRepo repositories.CabangRepository
 }
 
-func NewOutletUsecase(outletRepo repositories.OutletRepository) OutletUsecase {
+func NewOutletUsecase(outletRepo repositories.OutletRepository, cabangRepo repositories.CabangRepository) OutletUsecase {
 	return &outletUsecase{
 		outletRepo: outletRepo,
+		cabangRepo: cabangRepo,
 	}
 }
 
-func (u *outletUsecase) CreateOutlet(request entities.RegisterOutletRequest) error {
+// checkCabangScope returns ErrCabangNotFound unless the cabang exists inside scope
+func (u *outletUsecase) checkCabangScope(cabangID int, scope entities.AccessScope) error {
+	inScope, err := u.cabangRepo.ExistsInScope(cabangID, scope)
+	if err != nil {
+		return err
+	}
+	if !inScope {
+		return ErrCabangNotFound
+	}
+	return nil
+}
+
+func (u *outletUsecase) CreateOutlet(request entities.RegisterOutletRequest, scope entities.AccessScope) error {
+	if err := u.checkCabangScope(request.CabangID, scope); err != nil {
+		return err
+	}
+
 	// Convert float64 to pointers
 	var latPtr, lonPtr *float64
 	if request.Latitude != 0 {
@@ -41,24 +59,38 @@ func (u *outletUsecase) CreateOutlet(request entities.RegisterOutletRequest) err
 		PICName:    request.PICName,
 		PICEmail:   request.PICEmail,
 		PICTelepon: request.PICTelepon,
+		Code:       request.Code,
+		Timezone:   entities.DefaultOutletTimezone,
+
+		RequirePaidOnPickup: true,
+	}
+	if request.RequirePaidOnPickup != nil {
+		outlet.RequirePaidOnPickup = *request.RequirePaidOnPickup
+	}
+	if request.Timezone != "" {
+		outlet.Timezone = request.Timezone
 	}
 
 	return u.outletRepo.Create(outlet)
 }
 
-func (u *outletUsecase) GetOutletByID(id int) (*entities.Outlet, error) {
+func (u *outletUsecase) GetOutletByID(id int, scope entities.AccessScope) (*entities.Outlet, error) {
+	inScope, err := u.outletRepo.ExistsInScope(id, scope)
+	if err != nil || !inScope {
+		return nil, err
+	}
 	return u.outletRepo.FindByID(id)
 }
 
-func (u *outletUsecase) GetOutletsByCabangID(cabangID int) ([]entities.Outlet, error) {
-	return u.outletRepo.FindByCabangID(cabangID)
+func (u *outletUsecase) GetOutletsByCabangID(cabangID int, scope entities.AccessScope) ([]entities.Outlet, error) {
+	return u.outletRepo.FindByCabangID(cabangID, scope)
 }
 
-func (u *outletUsecase) GetAllOutlets() ([]entities.Outlet, error) {
-	return u.outletRepo.FindAll(entities.Outlet{})
+func (u *outletUsecase) GetAllOutlets(scope entities.AccessScope) ([]entities.Outlet, error) {
+	return u.outletRepo.FindAll(entities.Outlet{}, scope)
 }
 
-func (u *outletUsecase) GetAllOutletsDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error) {
+func (u *outletUsecase) GetAllOutletsDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error) {
 	// Default ordering
 	orderBy := "id"
 	orderDir := "asc"
@@ -76,6 +108,7 @@ func (u *outletUsecase) GetAllOutletsDataTables(request entities.DataTablesReque
 		request.Search.Value,
 		orderBy,
 		orderDir,
+		scope,
 	)
 
 	if err != nil {
@@ -93,7 +126,11 @@ func (u *outletUsecase) GetAllOutletsDataTables(request entities.DataTablesReque
 	return response, nil
 }
 
-func (u *outletUsecase) UpdateOutlet(id int, request entities.RegisterOutletRequest) error {
+func (u *outletUsecase) UpdateOutlet(id int, request entities.RegisterOutletRequest, scope entities.AccessScope) error {
+	if err := checkOutletScope(u.outletRepo, id, scope); err != nil {
+		return err
+	}
+
 	outlet, err := u.outletRepo.FindByID(id)
 	if err != nil {
 		return err
@@ -103,6 +140,13 @@ func (u *outletUsecase) UpdateOutlet(id int, request entities.RegisterOutletRequ
 		return nil // Outlet tidak ditemukan
 	}
 
+	// Moving the outlet is only allowed to a cabang the caller can see as well
+	if request.CabangID != outlet.CabangID {
+		if err := u.checkCabangScope(request.CabangID, scope); err != nil {
+			return err
+		}
+	}
+
 	// Convert float64 to pointers
 	var latPtr, lonPtr *float64
 	if request.Latitude != 0 {
@@ -127,10 +171,20 @@ func (u *outletUsecase) UpdateOutlet(id int, request entities.RegisterOutletRequ
 	outlet.PICName = request.PICName
 	outlet.PICEmail = request.PICEmail
 	outlet.PICTelepon = request.PICTelepon
+	outlet.Code = request.Code
+	if request.RequirePaidOnPickup != nil {
+		outlet.RequirePaidOnPickup = *request.RequirePaidOnPickup
+	}
+	if request.Timezone != "" {
+		outlet.Timezone = request.Timezone
+	}
 
 	return u.outletRepo.Update(outlet)
 }
 
-func (u *outletUsecase) DeleteOutlet(id int) error {
+func (u *outletUsecase) DeleteOutlet(id int, scope entities.AccessScope) error {
+	if err := checkOutletScope(u.outletRepo, id, scope); err != nil {
+		return err
+	}
 	return u.outletRepo.Delete(id)
 }
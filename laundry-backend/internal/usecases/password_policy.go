@@ -0,0 +1,55 @@
+package usecases
+
+import (
+	"fmt"
+	"laundry-backend/internal/utils"
+	"strings"
+	"unicode"
+)
+
+// checkPasswordPolicy returns ErrWeakPassword naming the first rule of policy that password breaks
+func checkPasswordPolicy(policy utils.PasswordConfig, username, password string) error {
+	if len([]rune(password)) < policy.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrWeakPassword, policy.MinLength)
+	}
+	if strings.EqualFold(password, username) {
+		return fmt.Errorf("%w: must not be the username", ErrWeakPassword)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	switch {
+	case policy.RequireUpper && !hasUpper:
+		return fmt.Errorf("%w: must contain an uppercase letter", ErrWeakPassword)
+	case policy.RequireLower && !hasLower:
+		return fmt.Errorf("%w: must contain a lowercase letter", ErrWeakPassword)
+	case policy.RequireDigit && !hasDigit:
+		return fmt.Errorf("%w: must contain a digit", ErrWeakPassword)
+	case policy.RequireSymbol && !hasSymbol:
+		return fmt.Errorf("%w: must contain a symbol", ErrWeakPassword)
+	}
+
+	return nil
+}
+
+// checkPasswordReuse returns ErrPasswordReused when password matches one of the previous password hashes
+func checkPasswordReuse(hashes []string, password string) error {
+	for _, hash := range hashes {
+		if utils.CheckPasswordHash(password, hash) {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
@@ -10,5 +10,7 @@ type PaymentMethodUsecase interface {
 	GetAllPaymentMethods() ([]entities.PaymentMethod, error)
 	GetAllPaymentMethodsDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error)
 	UpdatePaymentMethod(id int, request entities.UpdatePaymentMethodRequest) error
+	UpdatePaymentMethodSecrets(id int, request entities.UpdatePaymentMethodSecretsRequest) error
+	RotatePaymentMethodSecrets(updatedBy string) (*entities.RotatePaymentMethodSecretsResponse, error)
 	DeletePaymentMethod(id int) error
 }
\ No newline at end of file
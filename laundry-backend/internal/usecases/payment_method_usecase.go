@@ -1,8 +1,10 @@
 package usecases
 
 import (
+	"fmt"
 	"laundry-backend/internal/entities"
 	"laundry-backend/internal/repositories"
+	"laundry-backend/internal/utils"
 )
 
 type paymentMethodUsecase struct {
@@ -17,26 +19,43 @@ func NewPaymentMethodUsecase(paymentMethodRepo repositories.PaymentMethodReposit
 
 func (u *paymentMethodUsecase) CreatePaymentMethod(request entities.CreatePaymentMethodRequest) error {
 	paymentMethod := &entities.PaymentMethod{
-		NamaMetode:  request.NamaMetode,
-		URL:         request.URL,
-		SKey:        request.SKey,
-		MKey:        request.MKey,
-		MerchantFee: request.MerchantFee,
-		AdminFee:    request.AdminFee,
-		Status:      request.Status,
-		CreatedBy:   request.CreatedBy,
-		UpdatedBy:   request.UpdatedBy,
+		NamaMetode:      request.NamaMetode,
+		URL:             request.URL,
+		SKey:            request.SKey,
+		MKey:            request.MKey,
+		MerchantFee:     request.MerchantFee,
+		AdminFee:        request.AdminFee,
+		MerchantFeeType: request.MerchantFeeType,
+		FeeBearer:       request.FeeBearer,
+		Status:          request.Status,
+		CreatedBy:       request.CreatedBy,
+		UpdatedBy:       request.UpdatedBy,
+	}
+	if err := validatePaymentMethodFees(paymentMethod); err != nil {
+		return err
 	}
 
 	return u.paymentMethodRepo.Create(paymentMethod)
 }
 
 func (u *paymentMethodUsecase) GetPaymentMethodByID(id int) (*entities.PaymentMethod, error) {
-	return u.paymentMethodRepo.FindByID(id)
+	paymentMethod, err := u.paymentMethodRepo.FindByID(id)
+	if err != nil || paymentMethod == nil {
+		return paymentMethod, err
+	}
+	maskPaymentMethodSecrets(paymentMethod)
+	return paymentMethod, nil
 }
 
 func (u *paymentMethodUsecase) GetAllPaymentMethods() ([]entities.PaymentMethod, error) {
-	return u.paymentMethodRepo.FindAll()
+	paymentMethods, err := u.paymentMethodRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range paymentMethods {
+		maskPaymentMethodSecrets(&paymentMethods[i])
+	}
+	return paymentMethods, nil
 }
 
 func (u *paymentMethodUsecase) GetAllPaymentMethodsDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error) {
@@ -62,6 +81,9 @@ func (u *paymentMethodUsecase) GetAllPaymentMethodsDataTables(request entities.D
 	if err != nil {
 		return nil, err
 	}
+	for i := range paymentMethods {
+		maskPaymentMethodSecrets(&paymentMethods[i])
+	}
 	
 	// Create response
 	response := &entities.DataTablesResponse{
@@ -86,16 +108,77 @@ func (u *paymentMethodUsecase) UpdatePaymentMethod(id int, request entities.Upda
 
 	paymentMethod.NamaMetode = request.NamaMetode
 	paymentMethod.URL = request.URL
-	paymentMethod.SKey = request.SKey
-	paymentMethod.MKey = request.MKey
 	paymentMethod.MerchantFee = request.MerchantFee
 	paymentMethod.AdminFee = request.AdminFee
+	paymentMethod.MerchantFeeType = request.MerchantFeeType
+	paymentMethod.FeeBearer = request.FeeBearer
 	paymentMethod.Status = request.Status
 	paymentMethod.UpdatedBy = request.UpdatedBy
+	if err := validatePaymentMethodFees(paymentMethod); err != nil {
+		return err
+	}
 
 	return u.paymentMethodRepo.Update(paymentMethod)
 }
 
+func (u *paymentMethodUsecase) UpdatePaymentMethodSecrets(id int, request entities.UpdatePaymentMethodSecretsRequest) error {
+	paymentMethod, err := u.paymentMethodRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if paymentMethod == nil {
+		return ErrPaymentMethodNotFound
+	}
+
+	// only the keys that were sent are replaced
+	if request.SKey != "" {
+		paymentMethod.SKey = request.SKey
+	}
+	if request.MKey != "" {
+		paymentMethod.MKey = request.MKey
+	}
+	paymentMethod.UpdatedBy = request.UpdatedBy
+
+	return u.paymentMethodRepo.UpdateSecrets(paymentMethod)
+}
+
+func (u *paymentMethodUsecase) RotatePaymentMethodSecrets(updatedBy string) (*entities.RotatePaymentMethodSecretsResponse, error) {
+	rotated, err := u.paymentMethodRepo.RotateSecrets(updatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.RotatePaymentMethodSecretsResponse{Rotated: rotated}, nil
+}
+
 func (u *paymentMethodUsecase) DeletePaymentMethod(id int) error {
 	return u.paymentMethodRepo.Delete(id)
-}
\ No newline at end of file
+}
+
+// maskPaymentMethodSecrets hides the gateway keys before a payment method leaves the API
+func maskPaymentMethodSecrets(paymentMethod *entities.PaymentMethod) {
+	paymentMethod.SKey = utils.MaskSecret(paymentMethod.SKey)
+	paymentMethod.MKey = utils.MaskSecret(paymentMethod.MKey)
+}
+
+// validatePaymentMethodFees defaults the fee type and bearer and rejects negative or unknown fee settings
+func validatePaymentMethodFees(paymentMethod *entities.PaymentMethod) error {
+	if paymentMethod.MerchantFeeType == "" {
+		paymentMethod.MerchantFeeType = entities.MerchantFeeFlat
+	}
+	if paymentMethod.FeeBearer == "" {
+		paymentMethod.FeeBearer = entities.FeeBearerCustomer
+	}
+
+	switch {
+	case paymentMethod.MerchantFee < 0 || paymentMethod.AdminFee < 0:
+		return fmt.Errorf("%w: fees cannot be negative", ErrInvalidPaymentMethodFee)
+	case paymentMethod.MerchantFeeType != entities.MerchantFeeFlat && paymentMethod.MerchantFeeType != entities.MerchantFeePercent:
+		return fmt.Errorf("%w: unknown merchant_fee_type %s", ErrInvalidPaymentMethodFee, paymentMethod.MerchantFeeType)
+	case paymentMethod.MerchantFeeType == entities.MerchantFeePercent && paymentMethod.MerchantFee > 100:
+		return fmt.Errorf("%w: merchant_fee percentage cannot exceed 100", ErrInvalidPaymentMethodFee)
+	case paymentMethod.FeeBearer != entities.FeeBearerCustomer && paymentMethod.FeeBearer != entities.FeeBearerMerchant:
+		return fmt.Errorf("%w: unknown fee_bearer %s", ErrInvalidPaymentMethodFee, paymentMethod.FeeBearer)
+	}
+
+	return nil
+}
@@ -0,0 +1,397 @@
+package usecases
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"laundry-backend/internal/apperror"
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/gateway"
+	"laundry-backend/internal/repositories"
+	"laundry-backend/internal/utils"
+	"time"
+)
+
+// validPaymentStatuses are the statuses a pembayaran row may hold
+var validPaymentStatuses = map[string]bool{
+	entities.PaymentStatusFailed:  true,
+	entities.PaymentStatusPending: true,
+	entities.PaymentStatusSuccess: true,
+}
+
+type paymentUsecase struct {
+	paymentRepo       repositories.PaymentRepository
+	transactionRepo   repositories.TransactionRepository
+	paymentMethodRepo repositories.PaymentMethodRepository
+	gateways          gateway.Resolver
+}
+
+func NewPaymentUsecase(paymentRepo repositories.PaymentRepository,
+	transactionRepo repositories.TransactionRepository,
+	paymentMethodRepo repositories.PaymentMethodRepository,
+	gateways gateway.Resolver) PaymentUsecase {
+	return &paymentUsecase{
+		paymentRepo:       paymentRepo,
+		transactionRepo:   transactionRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		gateways:          gateways,
+	}
+}
+
+func (u *paymentUsecase) CreatePayment(transactionID int, request entities.CreatePaymentRequest, scope entities.AccessScope) (*entities.Payment, error) {
+	if request.Status == "" {
+		request.Status = entities.PaymentStatusPending
+	}
+	if !validPaymentStatuses[request.Status] {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPaymentStatus, request.Status)
+	}
+	if request.Amount <= 0 {
+		return nil, ErrInvalidPaymentAmount
+	}
+
+	if err := checkTransactionScope(u.transactionRepo, transactionID, scope); err != nil {
+		return nil, err
+	}
+	transaction, err := u.transactionRepo.FindByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	paymentMethod, err := u.paymentMethodRepo.FindByID(request.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentMethod == nil || paymentMethod.Status == "inactive" {
+		return nil, ErrPaymentMethodNotFound
+	}
+
+	t := time.Now()
+	payment := &entities.Payment{
+		TransactionID:      transaction.ID,
+		PaymentDate:        &t,
+		Amount:             request.Amount,
+		PaymentMethodID:    paymentMethod.ID,
+		Method:             paymentMethod.NamaMetode,
+		PartnerReferenceNo: request.PartnerReferenceNo,
+		Status:             request.Status,
+		Note:               request.Note,
+		CreatedAt:          t,
+		UpdatedAt:          t,
+	}
+
+	if err := u.savePayment(payment, u.paymentRepo.CreateWithTx); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// savePayment writes a payment and recalculates uang_bayar/uang_kembalian of its transaksi in one DB transaction.
+// The transaksi is locked first, like processPaymentCallback does, so concurrent payments see each other's rows
+// and none is written after CancelTransaction refunded it.
+func (u *paymentUsecase) savePayment(payment *entities.Payment, write func(tx *sql.Tx, payment *entities.Payment) error) error {
+	tx, err := u.paymentRepo.BeginTransaction()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	transaction, err := u.transactionRepo.FindByIDForUpdateWithTx(tx, payment.TransactionID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to find transaction: %w", err)
+	}
+	if transaction == nil {
+		tx.Rollback()
+		return ErrTransactionNotFound
+	}
+	if transaction.Status == statusDibatalkan {
+		tx.Rollback()
+		return ErrTransactionCancelled
+	}
+
+	if err := u.savePaymentWithTx(tx, payment, write); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (u *paymentUsecase) savePaymentWithTx(tx *sql.Tx, payment *entities.Payment, write func(tx *sql.Tx, payment *entities.Payment) error) error {
+	if err := write(tx, payment); err != nil {
+		return fmt.Errorf("failed to save payment: %w", err)
+	}
+
+	if err := u.transactionRepo.UpdatePaidAmountWithTx(tx, payment.TransactionID); err != nil {
+		return fmt.Errorf("failed to update paid amount: %w", err)
+	}
+
+	return nil
+}
+
+func (u *paymentUsecase) GetPaymentsByTransactionID(transactionID int, scope entities.AccessScope) (*entities.TransactionPaymentsResponse, error) {
+	if err := checkTransactionScope(u.transactionRepo, transactionID, scope); err != nil {
+		return nil, err
+	}
+	transaction, err := u.transactionRepo.FindByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	payments, err := u.paymentRepo.FindByTransactionID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err := u.paymentRepo.FindRefundsByTransactionID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	var totalRefunded float64
+	for _, refund := range refunds {
+		if refund.Status == entities.PaymentStatusSuccess {
+			totalRefunded += refund.Amount
+		}
+	}
+
+	return &entities.TransactionPaymentsResponse{
+		TransactionID: transaction.ID,
+		TotalPrice:    transaction.TotalPrice,
+		TotalPaid:     transaction.TotalPaid,
+		ChangeAmount:  transaction.ChangeAmount,
+		Outstanding:   transaction.Outstanding,
+		PaymentStatus: transaction.PaymentStatus,
+		Payments:      payments,
+		TotalRefunded: totalRefunded,
+		Refunds:       refunds,
+	}, nil
+}
+
+// findPaymentInScope returns ErrPaymentNotFound unless the pembayaran belongs to a transaksi inside scope
+func (u *paymentUsecase) findPaymentInScope(id int, scope entities.AccessScope) (*entities.Payment, error) {
+	payment, err := u.paymentRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, ErrPaymentNotFound
+	}
+
+	err = checkTransactionScope(u.transactionRepo, payment.TransactionID, scope)
+	if errors.Is(err, ErrTransactionNotFound) {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (u *paymentUsecase) UpdatePaymentStatus(id int, request entities.UpdatePaymentStatusRequest, scope entities.AccessScope) error {
+	if !validPaymentStatuses[request.Status] {
+		return fmt.Errorf("%w: %s", ErrInvalidPaymentStatus, request.Status)
+	}
+
+	payment, err := u.findPaymentInScope(id, scope)
+	if err != nil {
+		return err
+	}
+
+	payment.Status = request.Status
+	if request.StatusCode != "" {
+		payment.PartnerStatusCode = request.StatusCode
+	}
+	if request.StatusMessage != "" {
+		payment.PartnerStatusMessage = request.StatusMessage
+	}
+
+	return u.savePayment(payment, u.paymentRepo.UpdateStatusWithTx)
+}
+
+// SyncPaymentStatus asks the gateway for the current status of a pending payment, for callbacks that never arrived.
+// A payment whose gateway cannot report a status, such as cash, is returned unchanged.
+func (u *paymentUsecase) SyncPaymentStatus(id int, scope entities.AccessScope) (*entities.Payment, error) {
+	payment, err := u.findPaymentInScope(id, scope)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != entities.PaymentStatusPending || payment.PartnerReferenceNo == "" {
+		return payment, nil
+	}
+
+	paymentMethod, err := u.paymentMethodRepo.FindByID(payment.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentMethod == nil {
+		return nil, ErrPaymentMethodNotFound
+	}
+
+	charge, err := u.gateways.Resolve(paymentMethod).QueryStatus(payment.PartnerReferenceNo)
+	if errors.Is(err, gateway.ErrStatusUnavailable) {
+		return payment, nil
+	}
+	if err != nil {
+		return nil, apperror.Upstream("failed to query payment gateway", err)
+	}
+	if charge.Status == payment.Status {
+		return payment, nil
+	}
+
+	payment.Status = charge.Status
+	if err := u.savePayment(payment, u.paymentRepo.UpdateStatusWithTx); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// ProcessPaymentCallback verifies and applies a raw gateway callback; every call is recorded in callback_pembayaran
+func (u *paymentUsecase) ProcessPaymentCallback(payload []byte, signature, merchantKey string) (*entities.PaymentCallbackLog, error) {
+	callbackLog := &entities.PaymentCallbackLog{
+		Payload:   string(payload),
+		Signature: signature,
+		CreatedAt: time.Now(),
+	}
+
+	err := u.processPaymentCallback(callbackLog, payload, merchantKey)
+	if err != nil {
+		callbackLog.Result = entities.CallbackResultRejected
+		callbackLog.Message = err.Error()
+	}
+
+	if logErr := u.paymentRepo.CreateCallbackLog(callbackLog); logErr != nil {
+		utils.LoggMsg("ProcessPaymentCallback", "Failed to record payment callback", logErr)
+	}
+
+	return callbackLog, err
+}
+
+func (u *paymentUsecase) processPaymentCallback(callbackLog *entities.PaymentCallbackLog, payload []byte, merchantKey string) error {
+	var request entities.PaymentCallbackRequest
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidCallbackPayload, err.Error())
+	}
+	callbackLog.PaymentMethodID = request.PaymentMethodID
+	callbackLog.TransactionID = request.TransactionID
+	callbackLog.ReferenceNo = request.PaymentReferenceNumber
+
+	if request.PaymentReferenceNumber == "" {
+		return fmt.Errorf("%w: missing nomor_referensi_pembayaran", ErrInvalidCallbackPayload)
+	}
+	if !validPaymentStatuses[request.PaymentStatus] {
+		return fmt.Errorf("%w: %s", ErrInvalidPaymentStatus, request.PaymentStatus)
+	}
+
+	// The signature is checked with the keys of the payment method the gateway belongs to
+	paymentMethod, err := u.paymentMethodRepo.FindByID(request.PaymentMethodID)
+	if err != nil {
+		return err
+	}
+	if paymentMethod == nil {
+		return ErrPaymentMethodNotFound
+	}
+	if subtle.ConstantTimeCompare([]byte(merchantKey), []byte(paymentMethod.MKey)) != 1 ||
+		!utils.VerifyPayloadSignature(paymentMethod.SKey, payload, callbackLog.Signature) {
+		return ErrInvalidCallbackSignature
+	}
+	callbackLog.SignatureValid = true
+
+	tx, err := u.paymentRepo.BeginTransaction()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Lock the transaksi so concurrent retries see each other's payment
+	transaction, err := u.transactionRepo.FindByIDForUpdateWithTx(tx, request.TransactionID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to find transaction: %w", err)
+	}
+	if transaction == nil {
+		tx.Rollback()
+		return ErrTransactionNotFound
+	}
+	if transaction.Status == statusDibatalkan {
+		tx.Rollback()
+		return fmt.Errorf("%w: transaction is cancelled", ErrInvalidCallbackPayload)
+	}
+
+	payment, err := u.paymentRepo.FindByReferenceNoWithTx(tx, paymentMethod.ID, request.PaymentReferenceNumber)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if payment != nil {
+		if payment.TransactionID != transaction.ID {
+			tx.Rollback()
+			return fmt.Errorf("%w: reference belongs to another transaction", ErrInvalidCallbackPayload)
+		}
+		// Gateways retry callbacks; a settled payment or a repeated status is acknowledged without changes
+		if payment.Status == entities.PaymentStatusSuccess || payment.Status == request.PaymentStatus {
+			tx.Rollback()
+			callbackLog.Result = entities.CallbackResultDuplicate
+			return nil
+		}
+		if request.PaidAmount > 0 && request.PaidAmount != payment.Amount {
+			tx.Rollback()
+			return fmt.Errorf("%w: expected %.2f, got %.2f", ErrPaymentAmountMismatch, payment.Amount, request.PaidAmount)
+		}
+	} else {
+		t := time.Now()
+		payment = &entities.Payment{
+			TransactionID:      transaction.ID,
+			PaymentDate:        &t,
+			Amount:             request.PaidAmount,
+			PaymentMethodID:    paymentMethod.ID,
+			Method:             paymentMethod.NamaMetode,
+			PartnerReferenceNo: request.PaymentReferenceNumber,
+			CreatedAt:          t,
+			UpdatedAt:          t,
+		}
+	}
+
+	if request.PaymentStatus == entities.PaymentStatusSuccess {
+		if payment.Amount <= 0 {
+			tx.Rollback()
+			return ErrInvalidPaymentAmount
+		}
+		if payment.Amount > transaction.Outstanding {
+			tx.Rollback()
+			return fmt.Errorf("%w: outstanding %.2f, got %.2f", ErrPaymentAmountMismatch, transaction.Outstanding, payment.Amount)
+		}
+	}
+
+	payment.Status = request.PaymentStatus
+	payment.PartnerStatusCode = request.StatusCode
+	payment.PartnerStatusMessage = request.StatusMessage
+
+	write := u.paymentRepo.UpdateStatusWithTx
+	if payment.ID == 0 {
+		write = u.paymentRepo.CreateWithTx
+	}
+	if err := u.savePaymentWithTx(tx, payment, write); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	callbackLog.Result = entities.CallbackResultProcessed
+	return nil
+}
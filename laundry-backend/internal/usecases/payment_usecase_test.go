@@ -0,0 +1,401 @@
+package usecases
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/gateway"
+	"laundry-backend/internal/repositories"
+	"laundry-backend/internal/utils"
+	"math"
+	"testing"
+)
+
+// noopDriver hands out database transactions that do nothing, so usecases can begin, commit and roll back
+// against in-memory repositories
+type noopDriver struct{}
+
+type noopConn struct{}
+
+type noopTx struct{}
+
+func (noopDriver) Open(string) (driver.Conn, error) { return noopConn{}, nil }
+
+func (noopConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("noop driver runs no queries")
+}
+func (noopConn) Close() error              { return nil }
+func (noopConn) Begin() (driver.Tx, error) { return noopTx{}, nil }
+
+func (noopTx) Commit() error   { return nil }
+func (noopTx) Rollback() error { return nil }
+
+func init() {
+	sql.Register("usecases-noop", noopDriver{})
+}
+
+// fakePaymentRepo keeps pembayaran rows and callback logs in memory
+type fakePaymentRepo struct {
+	repositories.PaymentRepository
+	db           *sql.DB
+	payments     []entities.Payment
+	callbackLogs []entities.PaymentCallbackLog
+}
+
+func (r *fakePaymentRepo) BeginTransaction() (*sql.Tx, error) {
+	return r.db.Begin()
+}
+
+func (r *fakePaymentRepo) FindByReferenceNoWithTx(tx *sql.Tx, paymentMethodID int, referenceNo string) (*entities.Payment, error) {
+	for _, payment := range r.payments {
+		if payment.PaymentMethodID == paymentMethodID && payment.PartnerReferenceNo == referenceNo {
+			return &payment, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakePaymentRepo) FindByID(id int) (*entities.Payment, error) {
+	for _, payment := range r.payments {
+		if payment.ID == id {
+			return &payment, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakePaymentRepo) CreateWithTx(tx *sql.Tx, payment *entities.Payment) error {
+	payment.ID = len(r.payments) + 1
+	r.payments = append(r.payments, *payment)
+	return nil
+}
+
+func (r *fakePaymentRepo) UpdateStatusWithTx(tx *sql.Tx, payment *entities.Payment) error {
+	for i := range r.payments {
+		if r.payments[i].ID == payment.ID {
+			r.payments[i] = *payment
+		}
+	}
+	return nil
+}
+
+func (r *fakePaymentRepo) CreateCallbackLog(log *entities.PaymentCallbackLog) error {
+	log.ID = len(r.callbackLogs) + 1
+	r.callbackLogs = append(r.callbackLogs, *log)
+	return nil
+}
+
+// fakeTransactionRepo keeps transaksi rows in memory and sums their payments like UpdatePaidAmountWithTx does
+type fakeTransactionRepo struct {
+	repositories.TransactionRepository
+	payments     *fakePaymentRepo
+	transactions map[int]*entities.Transaction
+}
+
+func (r *fakeTransactionRepo) ExistsInScope(id int, scope entities.AccessScope) (bool, error) {
+	_, ok := r.transactions[id]
+	return ok, nil
+}
+
+func (r *fakeTransactionRepo) FindByID(id int) (*entities.Transaction, error) {
+	return r.FindByIDForUpdateWithTx(nil, id)
+}
+
+func (r *fakeTransactionRepo) FindByIDForUpdateWithTx(tx *sql.Tx, id int) (*entities.Transaction, error) {
+	transaction, ok := r.transactions[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *transaction
+	found.Outstanding = math.Max(found.TotalPrice-found.TotalPaid, 0)
+	found.PaymentStatus = entities.PaymentStatusPaid
+	if found.Outstanding > 0 {
+		found.PaymentStatus = entities.PaymentStatusUnpaid
+	}
+	return &found, nil
+}
+
+func (r *fakeTransactionRepo) UpdatePaidAmountWithTx(tx *sql.Tx, transactionID int) error {
+	var paid float64
+	for _, payment := range r.payments.payments {
+		if payment.TransactionID == transactionID && payment.Status == entities.PaymentStatusSuccess {
+			paid += payment.Amount
+		}
+	}
+	transaction := r.transactions[transactionID]
+	transaction.TotalPaid = paid
+	transaction.ChangeAmount = math.Max(paid-transaction.TotalPrice, 0)
+	return nil
+}
+
+type fakePaymentMethodRepo struct {
+	repositories.PaymentMethodRepository
+	methods map[int]*entities.PaymentMethod
+}
+
+func (r *fakePaymentMethodRepo) FindByID(id int) (*entities.PaymentMethod, error) {
+	return r.methods[id], nil
+}
+
+// fakeGateway signs callbacks the way a payment gateway does
+type fakeGateway struct {
+	merchantKey string
+	secretKey   string
+}
+
+func (g fakeGateway) callback(t *testing.T, request entities.PaymentCallbackRequest) ([]byte, string) {
+	t.Helper()
+	payload, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload, utils.SignPayload(g.secretKey, payload)
+}
+
+func TestProcessPaymentCallback(t *testing.T) {
+	const transactionID, paymentMethodID = 7, 3
+	gw := fakeGateway{merchantKey: "merchant-key", secretKey: "secret-key"}
+	settle := entities.PaymentCallbackRequest{
+		TransactionID:          transactionID,
+		PaymentMethodID:        paymentMethodID,
+		PaymentStatus:          entities.PaymentStatusSuccess,
+		PaymentReferenceNumber: "REF-1",
+		PaidAmount:             50000,
+	}
+
+	tests := []struct {
+		name string
+		// pending is the status of the gateway charge REF-1 before the callback, empty for no charge
+		pending string
+		// paid is what the order already has in successful payments
+		paid    float64
+		request entities.PaymentCallbackRequest
+		// sign overrides the signature the gateway sends
+		sign          func(payload []byte) string
+		merchantKey   string
+		wantErr       error
+		wantResult    string
+		wantStatus    string
+		wantPaid      float64
+		wantPayStatus string
+	}{
+		{
+			name:          "valid signature settles the charge",
+			pending:       entities.PaymentStatusPending,
+			request:       settle,
+			wantResult:    entities.CallbackResultProcessed,
+			wantStatus:    entities.PaymentStatusSuccess,
+			wantPaid:      50000,
+			wantPayStatus: entities.PaymentStatusPaid,
+		},
+		{
+			name:          "invalid signature",
+			pending:       entities.PaymentStatusPending,
+			request:       settle,
+			sign:          func(payload []byte) string { return utils.SignPayload("another-secret", payload) },
+			wantErr:       ErrInvalidCallbackSignature,
+			wantResult:    entities.CallbackResultRejected,
+			wantStatus:    entities.PaymentStatusPending,
+			wantPayStatus: entities.PaymentStatusUnpaid,
+		},
+		{
+			name:          "wrong merchant key",
+			pending:       entities.PaymentStatusPending,
+			request:       settle,
+			merchantKey:   "another-merchant",
+			wantErr:       ErrInvalidCallbackSignature,
+			wantResult:    entities.CallbackResultRejected,
+			wantStatus:    entities.PaymentStatusPending,
+			wantPayStatus: entities.PaymentStatusUnpaid,
+		},
+		{
+			name:          "duplicate reference is acknowledged without changes",
+			pending:       entities.PaymentStatusSuccess,
+			paid:          50000,
+			request:       settle,
+			wantResult:    entities.CallbackResultDuplicate,
+			wantStatus:    entities.PaymentStatusSuccess,
+			wantPaid:      50000,
+			wantPayStatus: entities.PaymentStatusPaid,
+		},
+		{
+			name:    "amount mismatch",
+			pending: entities.PaymentStatusPending,
+			request: func() entities.PaymentCallbackRequest {
+				request := settle
+				request.PaidAmount = 40000
+				return request
+			}(),
+			wantErr:       ErrPaymentAmountMismatch,
+			wantResult:    entities.CallbackResultRejected,
+			wantStatus:    entities.PaymentStatusPending,
+			wantPayStatus: entities.PaymentStatusUnpaid,
+		},
+		{
+			name: "partial payment leaves the order belum lunas",
+			request: func() entities.PaymentCallbackRequest {
+				request := settle
+				request.PaidAmount = 20000
+				return request
+			}(),
+			wantResult:    entities.CallbackResultProcessed,
+			wantStatus:    entities.PaymentStatusSuccess,
+			wantPaid:      20000,
+			wantPayStatus: entities.PaymentStatusUnpaid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := sql.Open("usecases-noop", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			paymentRepo := &fakePaymentRepo{db: db}
+			if tt.pending != "" {
+				paymentRepo.payments = []entities.Payment{{
+					ID:                 1,
+					TransactionID:      transactionID,
+					PaymentMethodID:    paymentMethodID,
+					Amount:             50000,
+					PartnerReferenceNo: "REF-1",
+					Status:             tt.pending,
+				}}
+			}
+			transactionRepo := &fakeTransactionRepo{
+				payments: paymentRepo,
+				transactions: map[int]*entities.Transaction{
+					transactionID: {ID: transactionID, TotalPrice: 50000, TotalPaid: tt.paid, Status: statusDiterima},
+				},
+			}
+			paymentMethodRepo := &fakePaymentMethodRepo{methods: map[int]*entities.PaymentMethod{
+				paymentMethodID: {ID: paymentMethodID, NamaMetode: "QRIS", MKey: gw.merchantKey, SKey: gw.secretKey},
+			}}
+			usecase := NewPaymentUsecase(paymentRepo, transactionRepo, paymentMethodRepo, nil)
+
+			payload, signature := gw.callback(t, tt.request)
+			if tt.sign != nil {
+				signature = tt.sign(payload)
+			}
+			merchantKey := gw.merchantKey
+			if tt.merchantKey != "" {
+				merchantKey = tt.merchantKey
+			}
+
+			callbackLog, err := usecase.ProcessPaymentCallback(payload, signature, merchantKey)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if callbackLog.Result != tt.wantResult {
+				t.Errorf("result = %q, want %q", callbackLog.Result, tt.wantResult)
+			}
+			if callbackLog.SignatureValid != !errors.Is(tt.wantErr, ErrInvalidCallbackSignature) {
+				t.Errorf("signature valid = %v", callbackLog.SignatureValid)
+			}
+			if len(paymentRepo.callbackLogs) != 1 {
+				t.Errorf("recorded %d callback logs, want 1", len(paymentRepo.callbackLogs))
+			}
+
+			if len(paymentRepo.payments) != 1 {
+				t.Fatalf("got %d payments, want 1", len(paymentRepo.payments))
+			}
+			if status := paymentRepo.payments[0].Status; status != tt.wantStatus {
+				t.Errorf("payment status = %q, want %q", status, tt.wantStatus)
+			}
+
+			transaction, _ := transactionRepo.FindByIDForUpdateWithTx(nil, transactionID)
+			if transaction.TotalPaid != tt.wantPaid {
+				t.Errorf("total paid = %.2f, want %.2f", transaction.TotalPaid, tt.wantPaid)
+			}
+			if transaction.PaymentStatus != tt.wantPayStatus {
+				t.Errorf("payment status of the order = %q, want %q", transaction.PaymentStatus, tt.wantPayStatus)
+			}
+		})
+	}
+}
+
+func TestSyncPaymentStatusLeavesCashPending(t *testing.T) {
+	db, err := sql.Open("usecases-noop", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	paymentRepo := &fakePaymentRepo{db: db, payments: []entities.Payment{{
+		ID:                 1,
+		TransactionID:      7,
+		PaymentMethodID:    1,
+		Amount:             50000,
+		PartnerReferenceNo: "KASIR-1",
+		Status:             entities.PaymentStatusPending,
+	}}}
+	transactionRepo := &fakeTransactionRepo{
+		payments:     paymentRepo,
+		transactions: map[int]*entities.Transaction{7: {ID: 7, TotalPrice: 50000, Status: statusDiterima}},
+	}
+	paymentMethodRepo := &fakePaymentMethodRepo{methods: map[int]*entities.PaymentMethod{
+		1: {ID: 1, NamaMetode: "Tunai"},
+	}}
+	usecase := NewPaymentUsecase(paymentRepo, transactionRepo, paymentMethodRepo, gateway.NewResolver(nil))
+
+	payment, err := usecase.SyncPaymentStatus(1, entities.AccessScope{Global: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.Status != entities.PaymentStatusPending || paymentRepo.payments[0].Status != entities.PaymentStatusPending {
+		t.Errorf("cash payment status = %q, stored %q, want it to stay pending", payment.Status, paymentRepo.payments[0].Status)
+	}
+	if transactionRepo.transactions[7].TotalPaid != 0 {
+		t.Errorf("total paid = %.2f, want 0", transactionRepo.transactions[7].TotalPaid)
+	}
+}
+
+func TestPaymentsRefusedOnCancelledTransaction(t *testing.T) {
+	db, err := sql.Open("usecases-noop", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	paymentRepo := &fakePaymentRepo{db: db, payments: []entities.Payment{{
+		ID:              1,
+		TransactionID:   7,
+		PaymentMethodID: 1,
+		Amount:          50000,
+		Status:          entities.PaymentStatusFailed,
+	}}}
+	transactionRepo := &fakeTransactionRepo{
+		payments:     paymentRepo,
+		transactions: map[int]*entities.Transaction{7: {ID: 7, TotalPrice: 50000, Status: statusDibatalkan}},
+	}
+	paymentMethodRepo := &fakePaymentMethodRepo{methods: map[int]*entities.PaymentMethod{
+		1: {ID: 1, NamaMetode: "Tunai"},
+	}}
+	usecase := NewPaymentUsecase(paymentRepo, transactionRepo, paymentMethodRepo, nil)
+	scope := entities.AccessScope{Global: true}
+
+	_, err = usecase.CreatePayment(7, entities.CreatePaymentRequest{
+		PaymentMethodID: 1,
+		Amount:          50000,
+		Status:          entities.PaymentStatusSuccess,
+	}, scope)
+	if !errors.Is(err, ErrTransactionCancelled) {
+		t.Errorf("CreatePayment error = %v, want %v", err, ErrTransactionCancelled)
+	}
+
+	err = usecase.UpdatePaymentStatus(1, entities.UpdatePaymentStatusRequest{Status: entities.PaymentStatusSuccess}, scope)
+	if !errors.Is(err, ErrTransactionCancelled) {
+		t.Errorf("UpdatePaymentStatus error = %v, want %v", err, ErrTransactionCancelled)
+	}
+
+	if len(paymentRepo.payments) != 1 || paymentRepo.payments[0].Status != entities.PaymentStatusFailed {
+		t.Errorf("payments = %+v, want the failed payment unchanged", paymentRepo.payments)
+	}
+	if transactionRepo.transactions[7].TotalPaid != 0 {
+		t.Errorf("total paid = %.2f, want 0", transactionRepo.transactions[7].TotalPaid)
+	}
+}
@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"laundry-backend/internal/entities"
+	"math"
+	"sort"
+)
+
+// linePrice is what an order line costs before its modifiers
+type linePrice struct {
+	// ChargedQuantity is the quantity received after rounding and the minimum charge
+	ChargedQuantity float64
+	// UnitPrice is the price of the first tier, the service price unless the rule replaces it
+	UnitPrice float64
+	// BasePrice is ChargedQuantity priced through the tiers
+	BasePrice float64
+}
+
+// roundCents rounds an amount of money to two decimals
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// roundUpQuantity rounds quantity up to a multiple of step; a step of 0 keeps it as it is.
+// The ratio is rounded first so 1.1 kg at a 0.1 step stays 1.1 instead of becoming 1.2.
+func roundUpQuantity(quantity, step float64) float64 {
+	if step <= 0 {
+		return quantity
+	}
+	steps := math.Ceil(math.Round(quantity/step*1e6) / 1e6)
+	return roundCents(steps * step)
+}
+
+// tieredPrice prices quantity at unitPrice up to the first tier and at each tier's price above its From,
+// so a larger load never costs less than a smaller one
+func tieredPrice(quantity, unitPrice float64, tiers []entities.PriceTier) float64 {
+	sorted := make([]entities.PriceTier, len(tiers))
+	copy(sorted, tiers)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].From < sorted[j].From })
+
+	var (
+		total float64
+		from  float64
+		price = unitPrice
+	)
+	for _, tier := range sorted {
+		if quantity <= tier.From {
+			break
+		}
+		total += (tier.From - from) * price
+		from, price = tier.From, tier.UnitPrice
+	}
+	total += (quantity - from) * price
+	return roundCents(total)
+}
+
+// priceLine prices quantity of service under rule, which may be nil for a service without a pricing rule
+func priceLine(service *entities.Service, rule *entities.PricingRule, quantity float64) linePrice {
+	price := linePrice{ChargedQuantity: quantity, UnitPrice: service.Price}
+	if rule == nil {
+		price.BasePrice = roundCents(quantity * service.Price)
+		return price
+	}
+
+	if rule.UnitPrice != nil {
+		price.UnitPrice = *rule.UnitPrice
+	}
+	price.ChargedQuantity = roundUpQuantity(quantity, rule.RoundingStep)
+	if price.ChargedQuantity < rule.MinimumQuantity {
+		price.ChargedQuantity = rule.MinimumQuantity
+	}
+	price.BasePrice = tieredPrice(price.ChargedQuantity, price.UnitPrice, rule.Tiers)
+	return price
+}
+
+// checkPriceTiers returns ErrInvalidPricingRule when two tiers start at the same quantity
+func checkPriceTiers(tiers []entities.PriceTier) error {
+	seen := make(map[float64]bool, len(tiers))
+	for _, tier := range tiers {
+		if seen[tier.From] {
+			return ErrInvalidPricingRule
+		}
+		seen[tier.From] = true
+	}
+	return nil
+}
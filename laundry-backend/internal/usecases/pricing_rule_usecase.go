@@ -0,0 +1,114 @@
+package usecases
+
+import (
+	"errors"
+	"laundry-backend/internal/entities"
+	"laundry-backend/internal/repositories"
+)
+
+type pricingRuleUsecase struct {
+	pricingRuleRepo repositories.PricingRuleRepository
+	serviceRepo     repositories.ServiceRepository
+	outletRepo      repositories.OutletRepository
+	brandRepo       repositories.BrandRepository
+}
+
+func NewPricingRuleUsecase(pricingRuleRepo repositories.PricingRuleRepository, serviceRepo repositories.ServiceRepository,
+	outletRepo repositories.OutletRepository, brandRepo repositories.BrandRepository) PricingRuleUsecase {
+	return &pricingRuleUsecase{
+		pricingRuleRepo: pricingRuleRepo,
+		serviceRepo:     serviceRepo,
+		outletRepo:      outletRepo,
+		brandRepo:       brandRepo,
+	}
+}
+
+// GetPricingRules returns the rule of the service for every outlet and the rules of the single outlets the caller sees
+func (u *pricingRuleUsecase) GetPricingRules(serviceID int, scope entities.AccessScope) ([]entities.PricingRule, error) {
+	if _, err := u.findService(serviceID, scope); err != nil {
+		return nil, err
+	}
+	return u.pricingRuleRepo.FindByService(serviceID, scope)
+}
+
+// SavePricingRule creates or replaces the rule of the service at request.OutletID, or for every outlet of its brand
+// when it is 0, which only an administrator or an account of that brand may do
+func (u *pricingRuleUsecase) SavePricingRule(serviceID int, request entities.SavePricingRuleRequest, scope entities.AccessScope) (*entities.PricingRule, error) {
+	service, err := u.findService(serviceID, scope)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPriceTiers(request.Tiers); err != nil {
+		return nil, err
+	}
+
+	rule := &entities.PricingRule{
+		ServiceID:       serviceID,
+		UnitPrice:       request.UnitPrice,
+		MinimumQuantity: request.MinimumQuantity,
+		RoundingStep:    request.RoundingStep,
+		Tiers:           request.Tiers,
+	}
+	if rule.Tiers == nil {
+		rule.Tiers = []entities.PriceTier{}
+	}
+	if request.OutletID > 0 {
+		if err := checkOutletScope(u.outletRepo, request.OutletID, scope); err != nil {
+			return nil, err
+		}
+		// the outlet must sell the service, so it has to belong to the service's brand
+		if err := checkOutletScope(u.outletRepo, request.OutletID, brandScope(service.BrandID)); err != nil {
+			return nil, err
+		}
+		rule.OutletID = &request.OutletID
+	} else if err := checkBrandManager(u.brandRepo, service.BrandID, scope, ErrServiceNotFound, ErrPricingRuleForbidden); err != nil {
+		return nil, err
+	}
+
+	if err := u.pricingRuleRepo.Save(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (u *pricingRuleUsecase) DeletePricingRule(id int, scope entities.AccessScope) error {
+	rule, err := u.pricingRuleRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		return ErrPricingRuleNotFound
+	}
+
+	// a rule outside the caller's hierarchy is reported as not found
+	if rule.OutletID != nil {
+		err = checkOutletScope(u.outletRepo, *rule.OutletID, scope)
+	} else {
+		var service *entities.Service
+		if service, err = u.findService(rule.ServiceID, scope); err == nil {
+			err = checkBrandManager(u.brandRepo, service.BrandID, scope, ErrServiceNotFound, ErrPricingRuleForbidden)
+		}
+	}
+	if errors.Is(err, ErrOutletNotFound) || errors.Is(err, ErrServiceNotFound) {
+		return ErrPricingRuleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return u.pricingRuleRepo.Delete(id)
+}
+
+// findService returns a layanan of the caller's brand; another brand's layanan is reported as not found
+func (u *pricingRuleUsecase) findService(serviceID int, scope entities.AccessScope) (*entities.Service, error) {
+	service, err := u.serviceRepo.FindByID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return nil, ErrServiceNotFound
+	}
+	if err := checkBrandScope(u.brandRepo, service.BrandID, scope, ErrServiceNotFound); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
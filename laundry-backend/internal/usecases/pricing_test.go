@@ -0,0 +1,109 @@
+package usecases
+
+import (
+	"laundry-backend/internal/entities"
+	"testing"
+)
+
+func TestPriceLine(t *testing.T) {
+	service := &entities.Service{ID: 1, Price: 8000}
+	override := 9000.0
+
+	tests := []struct {
+		name     string
+		rule     *entities.PricingRule
+		quantity float64
+		want     linePrice
+	}{
+		{
+			name:     "no rule",
+			quantity: 2.3,
+			want:     linePrice{ChargedQuantity: 2.3, UnitPrice: 8000, BasePrice: 18400},
+		},
+		{
+			name:     "minimum charge",
+			rule:     &entities.PricingRule{MinimumQuantity: 3},
+			quantity: 1.2,
+			want:     linePrice{ChargedQuantity: 3, UnitPrice: 8000, BasePrice: 24000},
+		},
+		{
+			name:     "rounded up to 0.5",
+			rule:     &entities.PricingRule{RoundingStep: 0.5},
+			quantity: 2.1,
+			want:     linePrice{ChargedQuantity: 2.5, UnitPrice: 8000, BasePrice: 20000},
+		},
+		{
+			name:     "already a multiple of 0.5",
+			rule:     &entities.PricingRule{RoundingStep: 0.5},
+			quantity: 2.5,
+			want:     linePrice{ChargedQuantity: 2.5, UnitPrice: 8000, BasePrice: 20000},
+		},
+		{
+			name:     "1.1 at a 0.1 step",
+			rule:     &entities.PricingRule{RoundingStep: 0.1},
+			quantity: 1.1,
+			want:     linePrice{ChargedQuantity: 1.1, UnitPrice: 8000, BasePrice: 8800},
+		},
+		{
+			name: "exactly on a tier boundary",
+			rule: &entities.PricingRule{Tiers: []entities.PriceTier{
+				{From: 5, UnitPrice: 6000},
+			}},
+			quantity: 5,
+			want:     linePrice{ChargedQuantity: 5, UnitPrice: 8000, BasePrice: 40000},
+		},
+		{
+			name: "past a tier boundary",
+			rule: &entities.PricingRule{Tiers: []entities.PriceTier{
+				{From: 5, UnitPrice: 6000},
+			}},
+			quantity: 6,
+			want:     linePrice{ChargedQuantity: 6, UnitPrice: 8000, BasePrice: 46000},
+		},
+		{
+			name: "multiple tiers in any order",
+			rule: &entities.PricingRule{Tiers: []entities.PriceTier{
+				{From: 10, UnitPrice: 5000},
+				{From: 5, UnitPrice: 6000},
+			}},
+			quantity: 12,
+			// 5 x 8000 + 5 x 6000 + 2 x 5000
+			want: linePrice{ChargedQuantity: 12, UnitPrice: 8000, BasePrice: 80000},
+		},
+		{
+			name: "unit price override",
+			rule: &entities.PricingRule{UnitPrice: &override, Tiers: []entities.PriceTier{
+				{From: 5, UnitPrice: 6000},
+			}},
+			quantity: 7,
+			// 5 x 9000 + 2 x 6000
+			want: linePrice{ChargedQuantity: 7, UnitPrice: 9000, BasePrice: 57000},
+		},
+		{
+			name: "rounding, minimum and tiers together",
+			rule: &entities.PricingRule{MinimumQuantity: 3, RoundingStep: 0.5, Tiers: []entities.PriceTier{
+				{From: 3, UnitPrice: 7000},
+			}},
+			quantity: 3.2,
+			// 3.5 charged: 3 x 8000 + 0.5 x 7000
+			want: linePrice{ChargedQuantity: 3.5, UnitPrice: 8000, BasePrice: 27500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := priceLine(service, tt.rule, tt.quantity); got != tt.want {
+				t.Errorf("priceLine(%v) = %+v, want %+v", tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPriceTiers(t *testing.T) {
+	if err := checkPriceTiers([]entities.PriceTier{{From: 5, UnitPrice: 6000}, {From: 10, UnitPrice: 5000}}); err != nil {
+		t.Errorf("distinct tiers: %v", err)
+	}
+	if err := checkPriceTiers([]entities.PriceTier{{From: 5, UnitPrice: 6000}, {From: 5, UnitPrice: 5000}}); err != ErrInvalidPricingRule {
+		t.Errorf("tiers starting at the same quantity: error = %v, want %v", err, ErrInvalidPricingRule)
+	}
+}
@@ -15,7 +15,12 @@ func NewServiceCategoryUsecase(serviceCategoryRepo repositories.ServiceCategoryR
 	}
 }
 
-func (u *serviceCategoryUsecase) CreateServiceCategory(request entities.CreateServiceCategoryRequest) error {
+// CreateServiceCategory adds a kategori_layanan; categories are shared by every brand, so only administrators manage them
+func (u *serviceCategoryUsecase) CreateServiceCategory(request entities.CreateServiceCategoryRequest, scope entities.AccessScope) error {
+	if !scope.Global {
+		return ErrServiceCategoryForbidden
+	}
+
 	category := &entities.ServiceCategory{
 		Name:        request.Name,
 		Description: request.Description,
@@ -76,12 +81,19 @@ func (u *serviceCategoryUsecase) GetAllServiceCategoriesDataTables(request entit
 	return response, nil
 }
 
-func (u *serviceCategoryUsecase) UpdateServiceCategory(id int, request entities.UpdateServiceCategoryRequest) error {
+func (u *serviceCategoryUsecase) UpdateServiceCategory(id int, request entities.UpdateServiceCategoryRequest, scope entities.AccessScope) error {
+	if !scope.Global {
+		return ErrServiceCategoryForbidden
+	}
+
 	// First get the existing category
 	existingCategory, err := u.serviceCategoryRepo.FindByID(id)
 	if err != nil {
 		return err
 	}
+	if existingCategory == nil {
+		return ErrServiceCategoryNotFound
+	}
 
 	category := &entities.ServiceCategory{
 		ID:          id,
@@ -93,6 +105,9 @@ func (u *serviceCategoryUsecase) UpdateServiceCategory(id int, request entities.
 	return u.serviceCategoryRepo.Update(category)
 }
 
-func (u *serviceCategoryUsecase) DeleteServiceCategory(id int) error {
+func (u *serviceCategoryUsecase) DeleteServiceCategory(id int, scope entities.AccessScope) error {
+	if !scope.Global {
+		return ErrServiceCategoryForbidden
+	}
 	return u.serviceCategoryRepo.Delete(id)
 }
\ No newline at end of file
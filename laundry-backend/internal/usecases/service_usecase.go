@@ -7,15 +7,22 @@ import (
 
 type serviceUsecase struct {
 	serviceRepo repositories.ServiceRepository
+	brandRepo   repositories.BrandRepository
 }
 
-func NewServiceUsecase(serviceRepo repositories.ServiceRepository) ServiceUsecase {
+func NewServiceUsecase(serviceRepo repositories.ServiceRepository, brandRepo repositories.BrandRepository) ServiceUsecase {
 	return &serviceUsecase{
 		serviceRepo: serviceRepo,
+		brandRepo:   brandRepo,
 	}
 }
 
-func (u *serviceUsecase) CreateService(request entities.CreateServiceRequest) error {
+// CreateService adds a layanan to a brand; only an administrator or an account of that brand may do so
+func (u *serviceUsecase) CreateService(request entities.CreateServiceRequest, scope entities.AccessScope) error {
+	if err := checkBrandManager(u.brandRepo, request.BrandID, scope, ErrBrandNotFound, ErrServiceForbidden); err != nil {
+		return err
+	}
+
 	service := &entities.Service{
 		BrandID:     request.BrandID,
 		CategoryID:  request.CategoryID,
@@ -29,15 +36,26 @@ func (u *serviceUsecase) CreateService(request entities.CreateServiceRequest) er
 	return u.serviceRepo.Create(service)
 }
 
-func (u *serviceUsecase) GetServiceByID(id int) (*entities.Service, error) {
-	return u.serviceRepo.FindByID(id)
+// GetServiceByID returns a layanan of the caller's brand; another brand's layanan is reported as not found
+func (u *serviceUsecase) GetServiceByID(id int, scope entities.AccessScope) (*entities.Service, error) {
+	service, err := u.serviceRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return nil, ErrServiceNotFound
+	}
+	if err := checkBrandScope(u.brandRepo, service.BrandID, scope, ErrServiceNotFound); err != nil {
+		return nil, err
+	}
+	return service, nil
 }
 
-func (u *serviceUsecase) GetAllServices() ([]entities.Service, error) {
-	return u.serviceRepo.FindAll()
+func (u *serviceUsecase) GetAllServices(scope entities.AccessScope) ([]entities.Service, error) {
+	return u.serviceRepo.FindAll(scope)
 }
 
-func (u *serviceUsecase) GetAllServicesDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error) {
+func (u *serviceUsecase) GetAllServicesDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error) {
 	// Get order column
 	var orderBy string
 	var orderDir string
@@ -69,6 +87,7 @@ func (u *serviceUsecase) GetAllServicesDataTables(request entities.DataTablesReq
 		request.Search.Value,
 		orderBy,
 		orderDir,
+		scope,
 	)
 	if err != nil {
 		return nil, err
@@ -84,9 +103,9 @@ func (u *serviceUsecase) GetAllServicesDataTables(request entities.DataTablesReq
 	return response, nil
 }
 
-func (u *serviceUsecase) UpdateService(id int, request entities.UpdateServiceRequest) error {
+func (u *serviceUsecase) UpdateService(id int, request entities.UpdateServiceRequest, scope entities.AccessScope) error {
 	// First get the existing service to preserve the BrandID
-	existingService, err := u.serviceRepo.FindByID(id)
+	existingService, err := u.findManagedService(id, scope)
 	if err != nil {
 		return err
 	}
@@ -105,10 +124,25 @@ func (u *serviceUsecase) UpdateService(id int, request entities.UpdateServiceReq
 	return u.serviceRepo.Update(service)
 }
 
-func (u *serviceUsecase) DeleteService(id int) error {
+func (u *serviceUsecase) DeleteService(id int, scope entities.AccessScope) error {
+	if _, err := u.findManagedService(id, scope); err != nil {
+		return err
+	}
 	return u.serviceRepo.Delete(id)
 }
 
-func (u *serviceUsecase) GetServicesByCategoryID(categoryID int) ([]entities.Service, error) {
-	return u.serviceRepo.FindByCategoryID(categoryID)
+func (u *serviceUsecase) GetServicesByCategoryID(categoryID int, scope entities.AccessScope) ([]entities.Service, error) {
+	return u.serviceRepo.FindByCategoryID(categoryID, scope)
+}
+
+// findManagedService returns a layanan the caller may change: of their own brand, and only at the brand level
+func (u *serviceUsecase) findManagedService(id int, scope entities.AccessScope) (*entities.Service, error) {
+	service, err := u.GetServiceByID(id, scope)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBrandManager(u.brandRepo, service.BrandID, scope, ErrServiceNotFound, ErrServiceForbidden); err != nil {
+		return nil, err
+	}
+	return service, nil
 }
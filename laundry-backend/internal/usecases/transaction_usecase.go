@@ -1,26 +1,73 @@
 package usecases
 
 import (
+	"database/sql"
 	"fmt"
 	"laundry-backend/internal/entities"
+	"laundry-backend/internal/gateway"
 	"laundry-backend/internal/repositories"
+	"laundry-backend/internal/utils"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	statusDiterima   = "diterima"
+	statusDiproses   = "diproses"
+	statusSelesai    = "selesai"
+	statusDiambil    = "diambil"
+	statusDibatalkan = "dibatalkan"
 )
 
+// transactionStatusTransitions lists, for every status, the statuses it may move to next
+var transactionStatusTransitions = map[string][]string{
+	statusDiterima:   {statusDiproses, statusDibatalkan},
+	statusDiproses:   {statusSelesai, statusDibatalkan},
+	statusSelesai:    {statusDiambil},
+	statusDiambil:    {},
+	statusDibatalkan: {},
+}
+
+// canTransitionStatus reports whether a transaction may move from one status to another
+func canTransitionStatus(from, to string) bool {
+	for _, next := range transactionStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
 type transactionUsecase struct {
-	transactionRepo repositories.TransactionRepository
+	transactionRepo   repositories.TransactionRepository
+	outletRepo        repositories.OutletRepository
+	paymentRepo       repositories.PaymentRepository
+	paymentMethodRepo repositories.PaymentMethodRepository
+	gateways          gateway.Resolver
 }
 
-func NewTransactionUsecase(transactionRepo repositories.TransactionRepository) TransactionUsecase {
+func NewTransactionUsecase(transactionRepo repositories.TransactionRepository,
+	outletRepo repositories.OutletRepository,
+	paymentRepo repositories.PaymentRepository,
+	paymentMethodRepo repositories.PaymentMethodRepository,
+	gateways gateway.Resolver) TransactionUsecase {
 	return &transactionUsecase{
-		transactionRepo: transactionRepo,
+		transactionRepo:   transactionRepo,
+		outletRepo:        outletRepo,
+		paymentRepo:       paymentRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		gateways:          gateways,
 	}
 }
 
-func (u *transactionUsecase) GetAllTransactions() ([]entities.Transaction, error) {
-	return u.transactionRepo.FindAll()
+func (u *transactionUsecase) GetAllTransactions(scope entities.AccessScope) ([]entities.Transaction, error) {
+	return u.transactionRepo.FindAll(scope)
 }
 
-func (u *transactionUsecase) GetAllTransactionsDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error) {
+func (u *transactionUsecase) GetAllTransactionsDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error) {
 	// Get order column
 	var orderBy string
 	var orderDir string
@@ -51,6 +98,7 @@ func (u *transactionUsecase) GetAllTransactionsDataTables(request entities.DataT
 		request.Search.Value,
 		orderBy,
 		orderDir,
+		scope,
 	)
 	if err != nil {
 		return nil, err
@@ -66,80 +114,350 @@ func (u *transactionUsecase) GetAllTransactionsDataTables(request entities.DataT
 	return response, nil
 }
 
-func (u *transactionUsecase) GetTransactionByID(id int) (*entities.Transaction, error) {
-	return u.transactionRepo.FindByID(id)
+func (u *transactionUsecase) GetTransactionByID(id int, scope entities.AccessScope) (*entities.Transaction, error) {
+	if err := checkTransactionScope(u.transactionRepo, id, scope); err != nil {
+		return nil, err
+	}
+	transaction, err := u.transactionRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, ErrTransactionNotFound
+	}
+	return transaction, nil
 }
 
-func (u *transactionUsecase) GetTransactionsByOutletID(outletID int) ([]entities.Transaction, error) {
-	return u.transactionRepo.FindByOutletID(outletID)
+func (u *transactionUsecase) GetTransactionsByOutletID(outletID int, scope entities.AccessScope) ([]entities.Transaction, error) {
+	return u.transactionRepo.FindByOutletID(outletID, scope)
 }
 
-func (u *transactionUsecase) GetTransactionDetails(transactionID int) ([]entities.TransactionDetail, error) {
+func (u *transactionUsecase) GetTransactionDetails(transactionID int, scope entities.AccessScope) ([]entities.TransactionDetail, error) {
+	if err := checkTransactionScope(u.transactionRepo, transactionID, scope); err != nil {
+		return nil, err
+	}
 	return u.transactionRepo.FindDetailsByTransactionID(transactionID)
 }
 
-func (u *transactionUsecase) UpdateTransactionStatus(id int, request entities.UpdateTransactionStatusRequest) error {
-	// Validate the status value
-	validStatuses := map[string]bool{
-		"diterima":  true,
-		"diproses":  true,
-		"selesai":   true,
-		"diambil":   true,
+func (u *transactionUsecase) GetTransactionHistory(transactionID int, scope entities.AccessScope) (*entities.TransactionTimelineResponse, error) {
+	if err := checkTransactionScope(u.transactionRepo, transactionID, scope); err != nil {
+		return nil, err
+	}
+
+	transaction, err := u.transactionRepo.FindByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	histories, err := u.transactionRepo.FindHistoryByTransactionID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.TransactionTimelineResponse{
+		TransactionID:  transaction.ID,
+		InvoiceNumber:  transaction.InvoiceNumber,
+		CurrentStatus:  transaction.Status,
+		History:        histories,
+		StageDurations: computeStageDurations(histories, time.Now()),
+	}, nil
+}
+
+// computeStageDurations turns an ordered history into the time spent in each status.
+// A stage ends when the next history entry starts; the last stage is still running
+// at now unless its status is final (no further transitions allowed).
+func computeStageDurations(histories []entities.HistoryStatusTransaction, now time.Time) []entities.StatusStageDuration {
+	var stages []entities.StatusStageDuration
+	for i, history := range histories {
+		if history.ChangeTime == nil {
+			continue
+		}
+		stage := entities.StatusStageDuration{
+			Status:    history.NewStatus,
+			StartedAt: *history.ChangeTime,
+		}
+
+		end := now
+		if i+1 < len(histories) && histories[i+1].ChangeTime != nil {
+			end = *histories[i+1].ChangeTime
+			stage.EndedAt = &end
+		} else if len(transactionStatusTransitions[history.NewStatus]) == 0 {
+			// Final status, nothing left to measure
+			continue
+		} else {
+			stage.Ongoing = true
+		}
+
+		duration := end.Sub(stage.StartedAt)
+		if duration < 0 {
+			duration = 0
+		}
+		stage.DurationSeconds = int64(duration.Seconds())
+		stage.Duration = duration.Truncate(time.Second).String()
+		stages = append(stages, stage)
+	}
+
+	return stages
+}
+
+func (u *transactionUsecase) UpdateTransactionStatus(id int, request entities.UpdateTransactionStatusRequest, claims jwt.MapClaims, scope entities.AccessScope) (*entities.HistoryStatusTransaction, error) {
+	if _, ok := transactionStatusTransitions[request.Status]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTransactionStatus, request.Status)
+	}
+	actor, _ := claims["username"].(string)
+
+	if err := checkTransactionScope(u.transactionRepo, id, scope); err != nil {
+		return nil, err
+	}
+
+	// Begin database transaction
+	tx, err := u.transactionRepo.BeginTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Lock the row so concurrent status changes are serialized
+	transaction, err := u.transactionRepo.FindByIDForUpdateWithTx(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to find transaction: %w", err)
+	}
+	if transaction == nil {
+		tx.Rollback()
+		return nil, ErrTransactionNotFound
+	}
+
+	oldStatus := transaction.Status
+	if !canTransitionStatus(oldStatus, request.Status) {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, oldStatus, request.Status)
+	}
+	// Cancelling needs a reason and refunds, see CancelTransaction
+	if request.Status == statusDibatalkan {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: use the cancel endpoint to cancel a transaction", ErrInvalidStatusTransition)
+	}
+
+	// Outlets may require the order to be paid off before it is handed over
+	if request.Status == statusDiambil && transaction.Outstanding > 0 {
+		outlet, err := u.outletRepo.FindByID(transaction.OutletID)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to find outlet: %w", err)
+		}
+		if outlet == nil || outlet.RequirePaidOnPickup {
+			tx.Rollback()
+			return nil, fmt.Errorf("%w: %.2f", ErrOutstandingBalance, transaction.Outstanding)
+		}
+	}
+
+	description := request.Note
+	if description == "" {
+		description = fmt.Sprintf("Status diubah dari %s ke %s", oldStatus, request.Status)
 	}
-	
-	if !validStatuses[request.Status] {
-		return fmt.Errorf("invalid transaction status: %s", request.Status)
+	history, err := u.changeStatusWithTx(tx, transaction, request.Status, description, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
-	
-	return u.transactionRepo.UpdateTransactionStatus(id, request.Status)
+
+	return history, nil
 }
 
-func (u *transactionUsecase) UpdatePaymentStatus(id int, request entities.UpdatePaymentStatusRequest) error {
-	// Validate the status value
-	validStatuses := map[string]bool{
-		"lunas":       true,
-		"belum lunas": true,
+// changeStatusWithTx moves a locked transaksi to status, stamps its dates and records the history row
+func (u *transactionUsecase) changeStatusWithTx(tx *sql.Tx, transaction *entities.Transaction, status, description, actor string) (*entities.HistoryStatusTransaction, error) {
+	oldStatus := transaction.Status
+	t := time.Now()
+	switch status {
+	case statusSelesai:
+		transaction.CompletionDate = &t
+	case statusDiambil:
+		if transaction.CompletionDate == nil {
+			transaction.CompletionDate = &t
+		}
+		transaction.PickupDate = &t
 	}
-	
-	if !validStatuses[request.Status] {
-		return fmt.Errorf("invalid payment status: %s", request.Status)
+	transaction.Status = status
+	transaction.UpdatedAt = t
+	transaction.UpdatedBy = &actor
+
+	err := u.transactionRepo.UpdateTransactionStatusWithTx(tx, transaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update transaction status: %w", err)
 	}
-	
-	return u.transactionRepo.UpdatePaymentStatus(id, request.Status)
+
+	history := &entities.HistoryStatusTransaction{
+		TransactionID: transaction.ID,
+		OldStatus:     oldStatus,
+		NewStatus:     status,
+		ChangeTime:    &t,
+		Description:   description,
+		ChangedBy:     actor,
+		CreatedAt:     t,
+		UpdatedAt:     t,
+	}
+
+	err = u.transactionRepo.InsertHistoryStatusTransactionWithTx(tx, history)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert history status transaction: %w", err)
+	}
+
+	return history, nil
 }
 
-func (u *transactionUsecase) ProcessPaymentCallback(request entities.PaymentCallbackRequest) error {
-	// Validate the payment status value
-	validStatuses := map[string]bool{
-		"lunas":       true,
-		"belum lunas": true,
-		"gagal":       true,
-	}
-	
-	if !validStatuses[request.PaymentStatus] {
-		return fmt.Errorf("invalid payment status: %s", request.PaymentStatus)
-	}
-	
-	// Validate the payment method value
-	validPaymentMethods := map[string]bool{
-		"tunai":     true,
-		"transfer":  true,
-		"e-wallet":  true,
-	}
-	
-	if request.PaymentMethod != "" && !validPaymentMethods[request.PaymentMethod] {
-		return fmt.Errorf("invalid payment method: %s", request.PaymentMethod)
-	}
-	
-	// First, check if the transaction exists
-	transaction, err := u.transactionRepo.FindByID(request.TransactionID)
+// CancelTransaction moves a transaksi to dibatalkan and refunds what the customer kept paid, newest payment first.
+// Open gateway charges are marked gagal so a late callback cannot settle them. The refunds are recorded as pending
+// together with the cancellation and only sent to the gateways once it is committed; a refund the gateway did not
+// answer stays pending.
+func (u *transactionUsecase) CancelTransaction(id int, request entities.CancelTransactionRequest, claims jwt.MapClaims, scope entities.AccessScope) (*entities.TransactionCancellationResponse, error) {
+	if strings.TrimSpace(request.Reason) == "" {
+		return nil, ErrCancelReasonRequired
+	}
+	actor, _ := claims["username"].(string)
+
+	if err := checkTransactionScope(u.transactionRepo, id, scope); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.transactionRepo.BeginTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	transaction, err := u.transactionRepo.FindByIDForUpdateWithTx(tx, id)
 	if err != nil {
-		return fmt.Errorf("failed to find transaction: %w", err)
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to find transaction: %w", err)
 	}
-	
 	if transaction == nil {
-		return fmt.Errorf("transaction not found with id: %d", request.TransactionID)
+		tx.Rollback()
+		return nil, ErrTransactionNotFound
+	}
+	if !canTransitionStatus(transaction.Status, statusDibatalkan) {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, transaction.Status, statusDibatalkan)
 	}
-	
-	return u.transactionRepo.UpdatePaymentCallback(request.TransactionID, request)
-}
\ No newline at end of file
+
+	// change handed back at the counter was never kept, so it cannot be refunded
+	refundable := transaction.TotalPaid - transaction.ChangeAmount
+	refundAmount := refundable
+	if request.RefundAmount != nil {
+		refundAmount = *request.RefundAmount
+	}
+	if refundAmount < 0 || refundAmount > refundable {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: refund must be between 0 and %.2f", ErrInvalidRefundAmount, refundable)
+	}
+
+	payments, err := u.paymentRepo.FindByTransactionIDForUpdateWithTx(tx, transaction.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	t := time.Now()
+	response := &entities.TransactionCancellationResponse{}
+	gateways := []gateway.PaymentGateway{}
+	references := []string{}
+	remaining := refundAmount
+	for i := len(payments) - 1; i >= 0; i-- {
+		payment := payments[i]
+		switch {
+		case payment.Status == entities.PaymentStatusPending:
+			payment.Status = entities.PaymentStatusFailed
+			payment.PartnerStatusMessage = "Transaksi dibatalkan"
+			if err := u.paymentRepo.UpdateStatusWithTx(tx, &payment); err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("failed to void pending payment: %w", err)
+			}
+			continue
+		case payment.Status != entities.PaymentStatusSuccess || remaining <= 0:
+			continue
+		}
+
+		paymentMethod, err := u.paymentMethodRepo.FindByID(payment.PaymentMethodID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if paymentMethod == nil {
+			tx.Rollback()
+			return nil, ErrPaymentMethodNotFound
+		}
+
+		amount := math.Min(remaining, payment.Amount)
+		refund := entities.PaymentRefund{
+			PaymentID:     payment.ID,
+			TransactionID: transaction.ID,
+			Amount:        amount,
+			Status:        entities.PaymentStatusPending,
+			Reason:        request.Reason,
+			CreatedAt:     t,
+			CreatedBy:     actor,
+		}
+		if err := u.paymentRepo.CreateRefundWithTx(tx, &refund); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to record refund: %w", err)
+		}
+
+		remaining -= amount
+		response.Refunds = append(response.Refunds, refund)
+		gateways = append(gateways, u.gateways.Resolve(paymentMethod))
+		references = append(references, payment.PartnerReferenceNo)
+	}
+
+	history, err := u.changeStatusWithTx(tx, transaction, statusDibatalkan, "Dibatalkan: "+request.Reason, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	response.History = *history
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for i := range response.Refunds {
+		refund := &response.Refunds[i]
+		result, err := gateways[i].Refund(gateway.RefundRequest{
+			ReferenceNo: references[i],
+			Amount:      refund.Amount,
+			Reason:      refund.Reason,
+		})
+		if err != nil {
+			utils.LoggMsg("CancelTransaction", fmt.Sprintf("Refund %d left pending", refund.ID), err)
+			continue
+		}
+
+		refund.ReferenceNo = result.ReferenceNo
+		refund.Status = result.Status
+		if err := u.paymentRepo.UpdateRefundStatus(refund); err != nil {
+			return nil, fmt.Errorf("failed to record refund %d: %w", refund.ID, err)
+		}
+		if refund.Status == entities.PaymentStatusSuccess {
+			response.TotalRefunded += refund.Amount
+		}
+	}
+
+	return response, nil
+}
+
+// GetRevenueReport sums the orders entered between from and to (inclusive dates), leaving cancelled orders out.
+// Without an outlet it covers every outlet inside scope.
+func (u *transactionUsecase) GetRevenueReport(outletID int, from, to time.Time, scope entities.AccessScope) (*entities.RevenueReport, error) {
+	if outletID != 0 {
+		if err := checkOutletScope(u.outletRepo, outletID, scope); err != nil {
+			return nil, err
+		}
+	}
+	return u.transactionRepo.GetRevenueSummary(outletID, from, to.AddDate(0, 0, 1), scope)
+}
@@ -9,52 +9,86 @@ import (
 
 type userAccessUsecase struct {
 	userAccessRepo repositories.UserAccessRepository
+	brandRepo      repositories.BrandRepository
 	cabangRepo     repositories.CabangRepository
 	outletRepo     repositories.OutletRepository
 	employeeRepo   repositories.EmployeeRepository
-	jwtSecret      string
-	tokenExpiry    time.Duration
+	refreshRepo    repositories.RefreshTokenRepository
+	resetRepo      repositories.PasswordResetRepository
+	tokens         *utils.TokenService
+	refreshExpiry  time.Duration
+	guard          *loginGuard
+	passwordPolicy utils.PasswordConfig
 }
 
 func NewUserAccessUsecase(
 	userAccessRepo repositories.UserAccessRepository,
+	brandRepo repositories.BrandRepository,
 	cabangRepo repositories.CabangRepository,
 	outletRepo repositories.OutletRepository,
 	employeeRepo repositories.EmployeeRepository,
-	jwtSecret string,
-	tokenExpiry time.Duration,
+	refreshRepo repositories.RefreshTokenRepository,
+	loginAuditRepo repositories.LoginAuditRepository,
+	resetRepo repositories.PasswordResetRepository,
+	tokens *utils.TokenService,
+	refreshExpiry time.Duration,
+	loginPolicy utils.LoginConfig,
+	passwordPolicy utils.PasswordConfig,
 ) UserAccessUsecase {
 	return &userAccessUsecase{
 		userAccessRepo: userAccessRepo,
+		brandRepo:      brandRepo,
 		cabangRepo:     cabangRepo,
 		outletRepo:     outletRepo,
 		employeeRepo:   employeeRepo,
-		jwtSecret:      jwtSecret,
-		tokenExpiry:    tokenExpiry,
+		refreshRepo:    refreshRepo,
+		tokens:         tokens,
+		refreshExpiry:  refreshExpiry,
+		resetRepo:      resetRepo,
+		guard:          newLoginGuard(loginAuditRepo, loginPolicy),
+		passwordPolicy: passwordPolicy,
 	}
 }
 
-func (u *userAccessUsecase) CreateUserAccess(request entities.CreateUserAccessRequest) error {
+func (u *userAccessUsecase) CreateUserAccess(request entities.CreateUserAccessRequest, scope entities.AccessScope) error {
+	if err := u.checkGrant(request.Role, request.ReferenceLevel, request.ReferenceID, scope); err != nil {
+		return err
+	}
+	if err := checkPasswordPolicy(u.passwordPolicy, request.Username, request.Password); err != nil {
+		return err
+	}
+
 	access := &entities.UserAccess{
-		Username:       request.Username,
-		Password:       request.Password,
-		Role:           request.Role,
-		IsActive:       request.IsActive,
-		ReferenceLevel: request.ReferenceLevel,
-		ReferenceID:    request.ReferenceID,
+		Username:           request.Username,
+		Password:           request.Password,
+		Role:               request.Role,
+		IsActive:           request.IsActive,
+		ReferenceLevel:     request.ReferenceLevel,
+		ReferenceID:        request.ReferenceID,
+		MustChangePassword: request.MustChangePassword,
 	}
 	return u.userAccessRepo.Create(access)
 }
 
-func (u *userAccessUsecase) GetUserAccessByID(id int) (*entities.UserAccess, error) {
-	return u.userAccessRepo.FindByID(id)
+func (u *userAccessUsecase) GetUserAccessByID(id int, scope entities.AccessScope) (*entities.UserAccess, error) {
+	access, err := u.userAccessRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if access == nil {
+		return nil, ErrUserAccessNotFound
+	}
+	if err := u.checkUserAccessScope(id, scope); err != nil {
+		return nil, err
+	}
+	return access, nil
 }
 
-func (u *userAccessUsecase) GetAllUserAccess() ([]entities.UserAccess, error) {
-	return u.userAccessRepo.FindAll()
+func (u *userAccessUsecase) GetAllUserAccess(scope entities.AccessScope) ([]entities.UserAccess, error) {
+	return u.userAccessRepo.FindAll(scope)
 }
 
-func (u *userAccessUsecase) GetAllUserAccessDataTables(request entities.DataTablesRequest) (*entities.DataTablesResponse, error) {
+func (u *userAccessUsecase) GetAllUserAccessDataTables(request entities.DataTablesRequest, scope entities.AccessScope) (*entities.DataTablesResponse, error) {
 	// For simplicity, we'll use default pagination values
 	// In a real implementation, you would parse these from the request
 	limit := request.Length
@@ -67,7 +101,7 @@ func (u *userAccessUsecase) GetAllUserAccessDataTables(request entities.DataTabl
 		offset = 0
 	}
 
-	accesses, totalCount, err := u.userAccessRepo.FindAllWithPagination(limit, offset)
+	accesses, totalCount, err := u.userAccessRepo.FindAllWithPagination(limit, offset, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -82,14 +116,13 @@ func (u *userAccessUsecase) GetAllUserAccessDataTables(request entities.DataTabl
 	return response, nil
 }
 
-func (u *userAccessUsecase) UpdateUserAccess(id int, request entities.UpdateUserAccessRequest) error {
-	access, err := u.userAccessRepo.FindByID(id)
+func (u *userAccessUsecase) UpdateUserAccess(id int, request entities.UpdateUserAccessRequest, scope entities.AccessScope) error {
+	access, err := u.findManagedUserAccess(id, scope)
 	if err != nil {
 		return err
 	}
-
-	if access == nil {
-		return nil // User access not found
+	if err := u.checkGrant(request.Role, request.ReferenceLevel, request.ReferenceID, scope); err != nil {
+		return err
 	}
 
 	access.Username = request.Username
@@ -98,74 +131,533 @@ func (u *userAccessUsecase) UpdateUserAccess(id int, request entities.UpdateUser
 	access.ReferenceLevel = request.ReferenceLevel
 	access.ReferenceID = request.ReferenceID
 
-	return u.userAccessRepo.Update(access)
+	if err := u.userAccessRepo.Update(access); err != nil {
+		return err
+	}
+
+	// A deactivated user must not be able to refresh their way back in
+	if !access.IsActive {
+		_, err = u.refreshRepo.RevokeAllByAccessID(id)
+	}
+	return err
 }
 
-func (u *userAccessUsecase) UpdateUserPassword(id int, request entities.UpdateUserPasswordRequest) error {
-	// First, get the user access to verify current password
-	access, err := u.userAccessRepo.FindByID(id)
+// UpdateUserPassword changes a password after checking the current one. self is set when the caller owns the account;
+// anyone else must be able to manage it. A wrong current password counts as a failed login of the account.
+func (u *userAccessUsecase) UpdateUserPassword(id int, request entities.UpdateUserPasswordRequest, self bool,
+	scope entities.AccessScope, client entities.LoginClient) error {
+	var access *entities.UserAccess
+	var err error
+	if self {
+		access, err = u.userAccessRepo.FindByID(id)
+	} else {
+		access, err = u.findManagedUserAccess(id, scope)
+	}
 	if err != nil {
 		return err
 	}
-
 	if access == nil {
-		return nil // User access not found
+		return ErrUserAccessNotFound
+	}
+
+	if err := u.guard.checkClient(entities.LoginTypeUserAccess, access.Username, client); err != nil {
+		return err
+	}
+	if err := u.guard.checkLocked(entities.LoginTypeUserAccess, access.Username, access.LockedUntil, client); err != nil {
+		return err
 	}
 
 	// Authenticate with current password
 	authAccess, err := u.userAccessRepo.AuthenticateUser(access.Username, request.CurrentPassword)
-	if err != nil || authAccess == nil {
-		return err // Current password is incorrect
+	if err != nil {
+		return err
+	}
+	if authAccess == nil {
+		if _, err := u.userAccessRepo.RegisterFailedLogin(access.ID, u.guard.policy.MaxAttempts, u.guard.lockout()); err != nil {
+			return err
+		}
+		if err := u.guard.fail(entities.LoginTypeUserAccess, access.Username, client, entities.LoginFailureInvalidCredentials); err != nil {
+			return err
+		}
+		return ErrInvalidCurrentPassword
+	}
+	if err := u.userAccessRepo.ResetFailedLogins(access.ID); err != nil {
+		return err
+	}
+
+	if err := u.checkNewPassword(access, request.NewPassword); err != nil {
+		return err
+	}
+
+	tx, err := u.userAccessRepo.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Update with new password, which also lifts must_change_password, and sign out every session
+	// that may have been opened with the old one
+	if err := u.userAccessRepo.UpdatePasswordWithTx(tx, id, request.NewPassword, false); err != nil {
+		return err
+	}
+	if _, err := u.refreshRepo.RevokeAllByAccessIDWithTx(tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MustChangePassword reports whether the stored user_access may only change its password, which is set by
+// IssuePasswordReset after access tokens without the must_change_password claim were already issued
+func (u *userAccessUsecase) MustChangePassword(id int) (bool, error) {
+	access, err := u.userAccessRepo.FindByID(id)
+	if err != nil {
+		return false, err
+	}
+	if access == nil {
+		return false, ErrUserAccessNotFound
+	}
+	return access.MustChangePassword, nil
+}
+
+// IssuePasswordReset issues a one-time password reset token for a user_access. Earlier reset tokens stop working,
+// every session is revoked and the account is restricted to changing its password until it is reset.
+func (u *userAccessUsecase) IssuePasswordReset(id int, scope entities.AccessScope) (*entities.PasswordResetResponse, error) {
+	if _, err := u.findManagedUserAccess(id, scope); err != nil {
+		return nil, err
+	}
+
+	value, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	token := &entities.PasswordResetToken{
+		AccessID:  id,
+		TokenHash: utils.HashToken(value),
+		ExpiredAt: time.Now().Add(time.Duration(u.passwordPolicy.ResetExpire) * time.Minute),
+	}
+
+	tx, err := u.userAccessRepo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := u.resetRepo.InvalidateByAccessIDWithTx(tx, id); err != nil {
+		return nil, err
+	}
+	if err := u.resetRepo.CreateWithTx(tx, token); err != nil {
+		return nil, err
+	}
+	if err := u.userAccessRepo.SetMustChangePasswordWithTx(tx, id, true); err != nil {
+		return nil, err
+	}
+	if _, err := u.refreshRepo.RevokeAllByAccessIDWithTx(tx, id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &entities.PasswordResetResponse{
+		ResetToken: value,
+		ExpiredAt:  token.ExpiredAt,
+	}, nil
+}
+
+// ResetPassword sets a new password with a reset token issued by IssuePasswordReset; the token is spent
+func (u *userAccessUsecase) ResetPassword(request entities.ResetPasswordRequest) error {
+	if request.ResetToken == "" {
+		return ErrInvalidResetToken
+	}
+
+	tx, err := u.userAccessRepo.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	token, err := u.resetRepo.FindByHashForUpdateWithTx(tx, utils.HashToken(request.ResetToken))
+	if err != nil {
+		return err
+	}
+	if token == nil || token.UsedAt != nil || time.Now().After(token.ExpiredAt) {
+		return ErrInvalidResetToken
+	}
+
+	access, err := u.userAccessRepo.FindByID(token.AccessID)
+	if err != nil {
+		return err
+	}
+	if access == nil {
+		return ErrInvalidResetToken
 	}
 
-	// Update with new password
-	return u.userAccessRepo.UpdatePassword(id, request.NewPassword)
+	if err := u.checkNewPassword(access, request.NewPassword); err != nil {
+		return err
+	}
+
+	if err := u.userAccessRepo.UpdatePasswordWithTx(tx, access.ID, request.NewPassword, false); err != nil {
+		return err
+	}
+	if err := u.resetRepo.MarkUsedWithTx(tx, token.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (u *userAccessUsecase) DeleteUserAccess(id int) error {
+// checkNewPassword applies the password policy and rejects the last passwords of the account
+func (u *userAccessUsecase) checkNewPassword(access *entities.UserAccess, password string) error {
+	if err := checkPasswordPolicy(u.passwordPolicy, access.Username, password); err != nil {
+		return err
+	}
+
+	hashes, err := u.userAccessRepo.FindPasswordHashes(access.ID, u.passwordPolicy.HistorySize)
+	if err != nil {
+		return err
+	}
+	return checkPasswordReuse(hashes, password)
+}
+
+func (u *userAccessUsecase) DeleteUserAccess(id int, scope entities.AccessScope) error {
+	if _, err := u.findManagedUserAccess(id, scope); err != nil {
+		return err
+	}
 	return u.userAccessRepo.Delete(id)
 }
 
-func (u *userAccessUsecase) AuthenticateUser(request entities.UserLoginRequest) (response *entities.UserLoginResponse, err error) {
-	var (
-		id int
-	)
+func (u *userAccessUsecase) AuthenticateUser(request entities.UserLoginRequest, client entities.LoginClient) (response *entities.UserLoginResponse, err error) {
+	if err := u.guard.checkClient(entities.LoginTypeUserAccess, request.Username, client); err != nil {
+		return nil, err
+	}
+
+	account, err := u.userAccessRepo.FindByUsername(request.Username)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil {
+		if err := u.guard.checkLocked(entities.LoginTypeUserAccess, request.Username, account.LockedUntil, client); err != nil {
+			return nil, err
+		}
+	}
+
 	// Authenticate the user
 	userAccess, err := u.userAccessRepo.AuthenticateUser(request.Username, request.Password)
-	if err != nil || userAccess == nil {
-		return nil, err // Authentication failed
+	if err != nil {
+		return nil, err
 	}
+	if userAccess == nil {
+		if account != nil {
+			if _, err := u.userAccessRepo.RegisterFailedLogin(account.ID, u.guard.policy.MaxAttempts, u.guard.lockout()); err != nil {
+				return nil, err
+			}
+		}
+		if err := u.guard.fail(entities.LoginTypeUserAccess, request.Username, client, entities.LoginFailureInvalidCredentials); err != nil {
+			return nil, err
+		}
+		return nil, nil // Authentication failed
+	}
+
+	if err := u.userAccessRepo.ResetFailedLogins(userAccess.ID); err != nil {
+		return nil, err
+	}
+	if err := u.guard.succeed(entities.LoginTypeUserAccess, request.Username, client); err != nil {
+		return nil, err
+	}
+	userAccess.FailedLoginAttempts = 0
+	userAccess.LockedUntil = nil
+
+	accessToken, err := u.generateAccessToken(userAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := u.newRefreshToken(userAccess.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.refreshRepo.Create(refreshToken.record); err != nil {
+		return nil, err
+	}
+
+	response = &entities.UserLoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken.value,
+		User:         *userAccess,
+	}
+
+	return response, nil
+}
+
+// RefreshToken rotates a refresh token: the presented token is revoked and a new access/refresh token pair is issued.
+// Presenting a token that was already rotated means it leaked, so every session of the user is revoked.
+func (u *userAccessUsecase) RefreshToken(request entities.RefreshTokenRequest) (*entities.UserLoginResponse, error) {
+	if request.RefreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	tx, err := u.refreshRepo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	current, err := u.refreshRepo.FindByHashForUpdateWithTx(tx, utils.HashToken(request.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if current.RevokedAt != nil {
+		if current.ReplacedBy != nil {
+			if _, err := u.refreshRepo.RevokeAllByAccessIDWithTx(tx, current.AccessID); err != nil {
+				return nil, err
+			}
+			if err := tx.Commit(); err != nil {
+				return nil, err
+			}
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if time.Now().After(current.ExpiredAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	userAccess, err := u.userAccessRepo.FindByID(current.AccessID)
+	if err != nil {
+		return nil, err
+	}
+	if userAccess == nil || !userAccess.IsActive {
+		if _, err := u.refreshRepo.RevokeAllByAccessIDWithTx(tx, current.AccessID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	accessToken, err := u.generateAccessToken(userAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := u.newRefreshToken(userAccess.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.refreshRepo.CreateWithTx(tx, next.record); err != nil {
+		return nil, err
+	}
+	if err := u.refreshRepo.RevokeWithTx(tx, current.ID, &next.record.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &entities.UserLoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: next.value,
+		User:         *userAccess,
+	}, nil
+}
+
+// Logout revokes the refresh token of the session; the access token stays valid until it expires
+func (u *userAccessUsecase) Logout(request entities.RefreshTokenRequest) error {
+	if request.RefreshToken == "" {
+		return ErrInvalidRefreshToken
+	}
+
+	revoked, err := u.refreshRepo.RevokeByHash(utils.HashToken(request.RefreshToken))
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrInvalidRefreshToken
+	}
+
+	return nil
+}
+
+// RevokeSessions revokes every refresh token of a user_access, signing them out once their access tokens expire
+func (u *userAccessUsecase) RevokeSessions(id int, scope entities.AccessScope) (*entities.RevokeSessionsResponse, error) {
+	if _, err := u.findManagedUserAccess(id, scope); err != nil {
+		return nil, err
+	}
+
+	revoked, err := u.refreshRepo.RevokeAllByAccessID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.RevokeSessionsResponse{RevokedSessions: revoked}, nil
+}
+
+// UnlockUserAccess lifts a lockout and clears the failed attempt count of a user_access
+func (u *userAccessUsecase) UnlockUserAccess(id int, scope entities.AccessScope) error {
+	if _, err := u.findManagedUserAccess(id, scope); err != nil {
+		return err
+	}
+
+	return u.userAccessRepo.ResetFailedLogins(id)
+}
+
+// roleRanks orders the roles a user_access may hold; an unknown role ranks below all of them
+var roleRanks = map[string]int{
+	entities.RoleStaff:     1,
+	entities.RoleKaryawan:  1,
+	entities.RoleCashier:   2,
+	entities.RoleWarehouse: 2,
+	entities.RoleManager:   3,
+	entities.RoleOwner:     4,
+	entities.RoleAdmin:     5,
+}
+
+// levelRanks orders the reference levels from a single pegawai up to a whole brand
+var levelRanks = map[string]int{
+	"":                              1,
+	entities.ReferenceLevelKaryawan: 1,
+	entities.ReferenceLevelOutlet:   2,
+	entities.ReferenceLevelCabang:   3,
+	entities.ReferenceLevelBrand:    4,
+}
+
+// checkUserAccessScope returns ErrUserAccessNotFound unless the user_access references a row inside scope
+func (u *userAccessUsecase) checkUserAccessScope(id int, scope entities.AccessScope) error {
+	inScope, err := u.userAccessRepo.ExistsInScope(id, scope)
+	if err != nil {
+		return err
+	}
+	if !inScope {
+		return ErrUserAccessNotFound
+	}
+	return nil
+}
+
+// findManagedUserAccess returns a user_access the caller may change: inside scope and not holding a role above theirs
+func (u *userAccessUsecase) findManagedUserAccess(id int, scope entities.AccessScope) (*entities.UserAccess, error) {
+	access, err := u.GetUserAccessByID(id, scope)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.Global && roleRanks[access.Role] > roleRanks[scope.Role] {
+		return nil, ErrUserAccessForbidden
+	}
+	return access, nil
+}
+
+// checkGrant refuses a role or reference_level above the caller's own, and a reference outside scope
+func (u *userAccessUsecase) checkGrant(role, level string, referenceID int, scope entities.AccessScope) error {
+	if scope.Global {
+		return nil
+	}
+	if roleRanks[role] > roleRanks[scope.Role] || levelRanks[level] > levelRanks[scope.Level] {
+		return ErrUserAccessForbidden
+	}
+
+	switch level {
+	case entities.ReferenceLevelBrand:
+		if referenceID != scope.ReferenceID {
+			return ErrBrandNotFound
+		}
+	case entities.ReferenceLevelCabang:
+		inScope, err := u.cabangRepo.ExistsInScope(referenceID, scope)
+		if err != nil {
+			return err
+		}
+		if !inScope {
+			return ErrCabangNotFound
+		}
+	case entities.ReferenceLevelOutlet:
+		return checkOutletScope(u.outletRepo, referenceID, scope)
+	default:
+		inScope, err := u.employeeRepo.ExistsInScope(referenceID, scope)
+		if err != nil {
+			return err
+		}
+		if !inScope {
+			return ErrEmployeeNotFound
+		}
+	}
+	return nil
+}
+
+// generateAccessToken signs a staff JWT carrying the id of the hierarchy row the user is attached to
+func (u *userAccessUsecase) generateAccessToken(userAccess *entities.UserAccess) (string, error) {
+	referenceID, err := u.resolveReferenceID(userAccess)
+	if err != nil {
+		return "", err
+	}
+
+	return u.tokens.GenerateJWT(userAccess.ID, referenceID, userAccess.Username, userAccess.Role, userAccess.ReferenceLevel, utils.AudienceStaff, userAccess.MustChangePassword)
+}
+
+// resolveReferenceID looks up the brand, cabang, outlet or pegawai the user_access references
+func (u *userAccessUsecase) resolveReferenceID(userAccess *entities.UserAccess) (int, error) {
 	//get data hirarki by reference
 	switch userAccess.ReferenceLevel {
+	case entities.ReferenceLevelBrand:
+		brand, err := u.brandRepo.FindByID(userAccess.ReferenceID)
+		if err != nil {
+			return 0, err
+		}
+		if brand == nil {
+			return 0, ErrBrandNotFound
+		}
+		return brand.ID, nil
 	case "cabang":
 		cabang, err := u.cabangRepo.FindByID(userAccess.ReferenceID)
 		if err != nil {
-			return response, err
+			return 0, err
+		}
+		if cabang == nil {
+			return 0, ErrCabangNotFound
 		}
-		id = cabang.ID
+		return cabang.ID, nil
 	case "outlet":
 		outlet, err := u.outletRepo.FindByID(userAccess.ReferenceID)
 		if err != nil {
-			return response, err
+			return 0, err
 		}
-		id = outlet.ID
+		if outlet == nil {
+			return 0, ErrOutletNotFound
+		}
+		return outlet.ID, nil
 	default: //karyawan
 		employee, err := u.employeeRepo.FindByID(userAccess.ReferenceID)
 		if err != nil {
-			return response, err
+			return 0, err
+		}
+		if employee == nil {
+			return 0, ErrEmployeeNotFound
 		}
-		id = employee.ID
+		return employee.ID, nil
 	}
-	// Generate JWT token
-	tokenString, err := utils.GenerateJWT(userAccess.ID, id, userAccess.Username, userAccess.Role, userAccess.ReferenceLevel)
+}
+
+// issuedRefreshToken pairs the token handed to the client with the hashed row that is stored
+type issuedRefreshToken struct {
+	value  string
+	record *entities.RefreshToken
+}
+
+func (u *userAccessUsecase) newRefreshToken(accessID int) (*issuedRefreshToken, error) {
+	value, err := utils.GenerateOpaqueToken()
 	if err != nil {
 		return nil, err
 	}
 
-	response = &entities.UserLoginResponse{
-		AccessToken: tokenString,
-		User:        *userAccess,
-	}
-
-	return response, nil
+	return &issuedRefreshToken{
+		value: value,
+		record: &entities.RefreshToken{
+			AccessID:  accessID,
+			TokenHash: utils.HashToken(value),
+			ExpiredAt: time.Now().Add(u.refreshExpiry),
+		},
+	}, nil
 }
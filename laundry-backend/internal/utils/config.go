@@ -3,23 +3,32 @@ package utils
 import (
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Log      LogConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	Secret      SecretConfig
+	Login       LoginConfig
+	Password    PasswordConfig
+	Idempotency IdempotencyConfig
+	Log         LogConfig
 }
 
+// ServerConfig configures the HTTP server.
+// TrustedProxies is "cidr,cidr" of the reverse proxies whose X-Forwarded-For is believed; empty trusts none.
 type ServerConfig struct {
-	Address      string
-	ReadTimeout  int
-	WriteTimeout int
+	Address        string
+	ReadTimeout    int
+	WriteTimeout   int
+	TrustedProxies string
 }
 
 type DatabaseConfig struct {
@@ -30,8 +39,48 @@ type DatabaseConfig struct {
 	Name     string
 }
 
+// JWTConfig configures the token service.
+// Secret signs new access tokens under KeyID; PreviousKeys is "id:secret,id:secret" of retired keys
+// that still validate the tokens they signed. Expire is in minutes.
 type JWTConfig struct {
-	Secret string
+	Secret       string
+	KeyID        string
+	PreviousKeys string
+	Expire       int
+	// RefreshExpire is the lifetime of a refresh token in hours
+	RefreshExpire int
+}
+
+// SecretConfig holds the master keys used to encrypt secrets at rest.
+// Keys is "id:base64key,id:base64key"; ActiveKeyID picks the key new values are encrypted with.
+type SecretConfig struct {
+	Keys        string
+	ActiveKeyID string
+}
+
+// LoginConfig throttles password guessing on both logins.
+// A username is locked for LockoutMinutes after MaxAttempts wrong passwords in a row;
+// a client IP is refused once it has IPMaxAttempts failed logins within the last LockoutMinutes.
+type LoginConfig struct {
+	MaxAttempts    int
+	IPMaxAttempts  int
+	LockoutMinutes int
+}
+
+// PasswordConfig is the password policy of user_access accounts.
+// HistorySize is how many previous passwords cannot be reused; ResetExpire is the reset token lifetime in minutes.
+type PasswordConfig struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	HistorySize   int
+	ResetExpire   int
+}
+
+// IdempotencyConfig sets how long, in hours, the response of a request with an Idempotency-Key is replayed
+type IdempotencyConfig struct {
 	Expire int
 }
 
@@ -58,14 +107,27 @@ func LoadConfig() (*Config, error) {
 
 	// Debug: Print all config values
 	// log.Printf("All config values: %+v", viper.AllSettings())
-	exp, _ := strconv.Atoi(GetEnv("JWT_EXPIRE"))
+	exp, _ := strconv.Atoi(GetEnv("JWT_EXPIRE", "30"))
+	refreshExp, _ := strconv.Atoi(GetEnv("JWT_REFRESH_EXPIRE", "168"))
+	maxAttempts, _ := strconv.Atoi(GetEnv("LOGIN_MAX_ATTEMPTS", "5"))
+	ipMaxAttempts, _ := strconv.Atoi(GetEnv("LOGIN_IP_MAX_ATTEMPTS", "20"))
+	lockoutMinutes, _ := strconv.Atoi(GetEnv("LOGIN_LOCKOUT_MINUTES", "15"))
+	passwordMinLength, _ := strconv.Atoi(GetEnv("PASSWORD_MIN_LENGTH", "8"))
+	passwordUpper, _ := strconv.ParseBool(GetEnv("PASSWORD_REQUIRE_UPPER", "true"))
+	passwordLower, _ := strconv.ParseBool(GetEnv("PASSWORD_REQUIRE_LOWER", "true"))
+	passwordDigit, _ := strconv.ParseBool(GetEnv("PASSWORD_REQUIRE_DIGIT", "true"))
+	passwordSymbol, _ := strconv.ParseBool(GetEnv("PASSWORD_REQUIRE_SYMBOL", "false"))
+	passwordHistory, _ := strconv.Atoi(GetEnv("PASSWORD_HISTORY", "5"))
+	passwordResetExpire, _ := strconv.Atoi(GetEnv("PASSWORD_RESET_EXPIRE", "60"))
+	idempotencyExpire, _ := strconv.Atoi(GetEnv("IDEMPOTENCY_EXPIRE", "24"))
 	readTimeout, _ := strconv.Atoi(GetEnv("SERVER_READ_TIMEOUT"))
 	writeTimeout, _ := strconv.Atoi(GetEnv("SERVER_WRITE_TIMEOUT"))
 	config := &Config{
 		Server: ServerConfig{
-			Address:      GetEnv("APP_PORT"),
-			ReadTimeout:  readTimeout,
-			WriteTimeout: writeTimeout,
+			Address:        GetEnv("APP_PORT"),
+			ReadTimeout:    readTimeout,
+			WriteTimeout:   writeTimeout,
+			TrustedProxies: GetEnv("TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Host:     GetEnv("DB_HOST"),
@@ -74,9 +136,34 @@ func LoadConfig() (*Config, error) {
 			Password: GetEnv("DB_PASSWORD"),
 			Name:     GetEnv("DB_NAME"),
 		},
+		// Secrets are read directly: GetEnv echoes values to the log, .env is already loaded by the calls above
 		JWT: JWTConfig{
-			Secret: GetEnv("JWT_SECRET"),
-			Expire: exp,
+			Secret:        os.Getenv("JWT_SECRET"),
+			KeyID:         GetEnv("JWT_KEY_ID", "v1"),
+			PreviousKeys:  os.Getenv("JWT_PREVIOUS_KEYS"),
+			Expire:        exp,
+			RefreshExpire: refreshExp,
+		},
+		Secret: SecretConfig{
+			Keys:        os.Getenv("SECRET_KEYS"),
+			ActiveKeyID: os.Getenv("SECRET_ACTIVE_KEY"),
+		},
+		Login: LoginConfig{
+			MaxAttempts:    maxAttempts,
+			IPMaxAttempts:  ipMaxAttempts,
+			LockoutMinutes: lockoutMinutes,
+		},
+		Password: PasswordConfig{
+			MinLength:     passwordMinLength,
+			RequireUpper:  passwordUpper,
+			RequireLower:  passwordLower,
+			RequireDigit:  passwordDigit,
+			RequireSymbol: passwordSymbol,
+			HistorySize:   passwordHistory,
+			ResetExpire:   passwordResetExpire,
+		},
+		Idempotency: IdempotencyConfig{
+			Expire: idempotencyExpire,
 		},
 		Log: LogConfig{
 			Level: GetEnv("LOG_LEVEL"),
@@ -112,3 +199,32 @@ func GetEnv(key string, value ...string) string {
 		return ""
 	}
 }
+
+// ParseTrustedProxies parses "cidr,cidr" into IP ranges; a bare IP address is a range of one
+func ParseTrustedProxies(value string) ([]*net.IPNet, error) {
+	var ranges []*net.IPNet
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			ranges = append(ranges, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		ranges = append(ranges, ipNet)
+	}
+	return ranges, nil
+}
@@ -2,114 +2,125 @@ package utils
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte("laundry-secret-key")
+// Token audiences; each route group only accepts tokens issued for its audience
+const (
+	// AudienceAdmin is issued by the users table login and required by the brand/cabang administration routes
+	AudienceAdmin = "laundry-admin"
+	// AudienceStaff is issued by the user_access login
+	AudienceStaff = "laundry-staff"
+)
+
+// MinJWTSecretLength is the shortest signing secret accepted, 256 bits for HS256
+const MinJWTSecretLength = 32
 
-// Claims represents the structure of our JWT claims
-type Claims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
-	jwt.RegisteredClaims
+// TokenService issues and validates the access tokens of both login flows.
+// Tokens are signed with the active key and carry its id in the kid header; the previous keys
+// still validate the tokens they signed, so a secret can be rotated without logging everyone out.
+type TokenService struct {
+	keys        map[string][]byte
+	activeKeyID string
+	expiry      time.Duration
 }
 
-// GenerateJWT creates a new JWT token with the specified claims
-func GenerateJWT(userID, referenceID int, username, role, referenceLevel string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id":         userID,         //userAccess.ID,
-		"reference_id":    referenceID,    //userAccess.ReferenceID,
-		"reference_level": referenceLevel, //userAccess.ReferenceID,
-		"username":        username,       //userAccess.Username,
-		"role":            role,
-		"exp":             float64(time.Now().Add(30 * time.Minute).Unix()),
+// NewTokenService builds the token service from the JWT configuration and refuses a missing or short secret
+func NewTokenService(config JWTConfig) (*TokenService, error) {
+	if config.Secret == "" {
+		return nil, errors.New("JWT_SECRET is not configured")
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
-	if err != nil {
-		return "", err
+	if config.KeyID == "" {
+		return nil, errors.New("JWT_KEY_ID is not configured")
+	}
+	if config.Expire <= 0 {
+		return nil, errors.New("JWT_EXPIRE must be a positive number of minutes")
 	}
 
-	return tokenString, nil
-}
-
-// ValidateJWT validates a JWT token and returns the claims
-func ValidateJWT(tokenString string) (*Claims, error) {
-	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}))
-
+	keys, err := ParseJWTKeys(config.PreviousKeys)
 	if err != nil {
 		return nil, err
 	}
+	keys[config.KeyID] = []byte(config.Secret)
 
-	if !token.Valid {
-		return nil, errors.New("invalid token")
+	for id, key := range keys {
+		if len(key) < MinJWTSecretLength {
+			return nil, fmt.Errorf("JWT key %s must be at least %d bytes", id, MinJWTSecretLength)
+		}
 	}
 
-	return claims, nil
+	return &TokenService{
+		keys:        keys,
+		activeKeyID: config.KeyID,
+		expiry:      time.Duration(config.Expire) * time.Minute,
+	}, nil
 }
 
-// ParseJWTClaims parses a JWT token and returns all claims as a map
-// This is useful when you want to access custom claims that aren't defined in the Claims struct
-func ParseJWTClaims(tokenString string) (jwt.MapClaims, error) {
-	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
-	if err != nil {
-		return nil, err
+// ParseJWTKeys parses "id:secret,id:secret" as used by the JWT_PREVIOUS_KEYS variable
+func ParseJWTKeys(value string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid JWT key entry %q, expected id:secret", pair)
+		}
+		keys[id] = []byte(secret)
 	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		return claims, nil
-	}
-
-	return nil, errors.New("invalid token claims")
+	return keys, nil
 }
 
-// GetClaimValue extracts a specific claim value from a JWT token
-// This is a convenience function for accessing individual claims
-func GetClaimValue(tokenString string, claimName string) (interface{}, bool) {
-	claims, err := ParseJWTClaims(tokenString)
+// GenerateJWT creates a new JWT token with the specified claims for the given audience.
+// mustChangePassword restricts the token to the change-password endpoint.
+func (s *TokenService) GenerateJWT(userID, referenceID int, username, role, referenceLevel, audience string, mustChangePassword bool) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":              userID,         //userAccess.ID,
+		"reference_id":         referenceID,    //userAccess.ReferenceID,
+		"reference_level":      referenceLevel, //userAccess.ReferenceID,
+		"username":             username,       //userAccess.Username,
+		"role":                 role,
+		"aud":                  audience,
+		"must_change_password": mustChangePassword,
+		"exp":                  float64(time.Now().Add(s.expiry).Unix()),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = s.activeKeyID
+	tokenString, err := token.SignedString(s.keys[s.activeKeyID])
 	if err != nil {
-		return nil, false
+		return "", err
 	}
 
-	value, exists := claims[claimName]
-	return value, exists
+	return tokenString, nil
 }
 
-// GetStringClaim extracts a string claim value from a JWT token
-func GetStringClaim(tokenString string, claimName string) (string, bool) {
-	value, exists := GetClaimValue(tokenString, claimName)
-	if !exists {
-		return "", false
+// Parse validates the signature and expiry of a token and returns its claims
+func (s *TokenService) Parse(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.keyFunc, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}))
+	if err != nil {
+		return nil, err
 	}
 
-	if str, ok := value.(string); ok {
-		return str, true
+	if !token.Valid {
+		return nil, errors.New("invalid token")
 	}
 
-	return "", false
+	return claims, nil
 }
 
-// GetIntClaim extracts an integer claim value from a JWT token
-func GetIntClaim(tokenString string, claimName string) (int, bool) {
-	value, exists := GetClaimValue(tokenString, claimName)
-	if !exists {
-		return 0, false
-	}
-
-	switch v := value.(type) {
-	case float64:
-		return int(v), true
-	case int:
-		return v, true
-	case int64:
-		return int(v), true
-	default:
-		return 0, false
+// keyFunc picks the verification key by the kid header; tokens without a kid are rejected
+func (s *TokenService) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown JWT key id %q", kid)
 	}
+	return key, nil
 }
@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedSecretPrefix marks a value written by SecretCipher; anything else is a legacy plain text secret
+const encryptedSecretPrefix = "enc:v1:"
+
+// SecretCipher envelope-encrypts secrets stored in the database.
+// Each value gets its own random data key; only that data key is encrypted with a master key,
+// so rotating the master key re-wraps the data key without touching the secret itself.
+// Stored format: enc:v1:<master key id>:<wrapped data key>:<ciphertext>, both base64 encoded.
+type SecretCipher struct {
+	keys        map[string][]byte
+	activeKeyID string
+}
+
+// NewSecretCipher builds a cipher from master keys indexed by key id; activeKeyID encrypts new values
+func NewSecretCipher(keys map[string][]byte, activeKeyID string) (*SecretCipher, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no secret encryption keys configured")
+	}
+	for id, key := range keys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("secret encryption key %s must be 32 bytes", id)
+		}
+	}
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active secret encryption key %s is not configured", activeKeyID)
+	}
+	return &SecretCipher{keys: keys, activeKeyID: activeKeyID}, nil
+}
+
+// ParseSecretKeys parses "id:base64key,id:base64key" as used by the SECRET_KEYS variable
+func ParseSecretKeys(value string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(pair, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid secret key entry %q, expected id:base64key", pair)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret key %s: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// Encrypt seals plain with a fresh data key wrapped by the active master key; empty values stay empty
+func (c *SecretCipher) Encrypt(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataKey, []byte(plain))
+	if err != nil {
+		return "", err
+	}
+	wrappedKey, err := seal(c.keys[c.activeKeyID], dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedSecretPrefix + c.activeKeyID + ":" +
+		base64.StdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt opens a value written by Encrypt; legacy plain text values are returned unchanged
+func (c *SecretCipher) Decrypt(value string) (string, error) {
+	if !IsEncryptedSecret(value) {
+		return value, nil
+	}
+
+	_, dataKey, ciphertext, err := c.unwrap(value)
+	if err != nil {
+		return "", err
+	}
+	plain, err := open(dataKey, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plain), nil
+}
+
+// NeedsRotation reports whether value is plain text or wrapped by a master key other than the active one
+func (c *SecretCipher) NeedsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+	if !IsEncryptedSecret(value) {
+		return true
+	}
+	keyID, _, _ := strings.Cut(strings.TrimPrefix(value, encryptedSecretPrefix), ":")
+	return keyID != c.activeKeyID
+}
+
+// Rotate re-wraps the data key of value with the active master key; plain text values are encrypted
+func (c *SecretCipher) Rotate(value string) (string, error) {
+	if !IsEncryptedSecret(value) {
+		return c.Encrypt(value)
+	}
+
+	_, dataKey, ciphertext, err := c.unwrap(value)
+	if err != nil {
+		return "", err
+	}
+	wrappedKey, err := seal(c.keys[c.activeKeyID], dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedSecretPrefix + c.activeKeyID + ":" +
+		base64.StdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// unwrap splits an encrypted value and decrypts its data key with the master key it names
+func (c *SecretCipher) unwrap(value string) (keyID string, dataKey, ciphertext []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(value, encryptedSecretPrefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, errors.New("malformed encrypted secret")
+	}
+	keyID = parts[0]
+	masterKey, ok := c.keys[keyID]
+	if !ok {
+		return "", nil, nil, fmt.Errorf("secret encryption key %s is not configured", keyID)
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("malformed encrypted secret: %w", err)
+	}
+	ciphertext, err = base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("malformed encrypted secret: %w", err)
+	}
+	dataKey, err = open(masterKey, wrappedKey)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to unwrap secret data key: %w", err)
+	}
+
+	return keyID, dataKey, ciphertext, nil
+}
+
+// IsEncryptedSecret reports whether value was written by SecretCipher
+func IsEncryptedSecret(value string) bool {
+	return strings.HasPrefix(value, encryptedSecretPrefix)
+}
+
+// MaskSecret hides all but the last 4 characters of a secret for API responses
+func MaskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) <= 4 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", 8) + value[len(value)-4:]
+}
+
+// seal encrypts plain with AES-256-GCM and prepends the nonce
+func seal(key, plain []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+// open reverses seal
+func open(key, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
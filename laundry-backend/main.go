@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	"laundry-backend/internal/delivery"
+	"laundry-backend/internal/gateway"
 	"laundry-backend/internal/middleware"
 	"laundry-backend/internal/repositories"
 	"laundry-backend/internal/usecases"
@@ -36,60 +38,125 @@ func main() {
 	}
 	defer db.Close()
 
+	// Secrets stored in the database are encrypted with the configured master keys
+	secretKeys, err := utils.ParseSecretKeys(config.Secret.Keys)
+	if err != nil {
+		log.Fatal("Cannot load secret keys:", err)
+	}
+	secretCipher, err := utils.NewSecretCipher(secretKeys, config.Secret.ActiveKeyID)
+	if err != nil {
+		log.Fatal("Cannot load secret keys:", err)
+	}
+
+	// Access tokens of both logins are signed and validated with the configured JWT keys
+	tokenService, err := utils.NewTokenService(config.JWT)
+	if err != nil {
+		log.Fatal("Cannot load JWT keys:", err)
+	}
+
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
 	brandRepo := repositories.NewBrandRepository(db)
 	cabangRepo := repositories.NewCabangRepository(db)
 	outletRepo := repositories.NewOutletRepository(db)
+	calendarRepo := repositories.NewCalendarRepository(db)
 	employeeRepo := repositories.NewEmployeeRepository(db)
 	inquiryRepo := repositories.NewInquiryRepository(db, employeeRepo)
 	customerRepo := repositories.NewCustomerRepository(db)
 	serviceRepo := repositories.NewServiceRepository(db)
 	serviceCategoryRepo := repositories.NewServiceCategoryRepository(db)
+	modifierRepo := repositories.NewModifierRepository(db)
+	pricingRuleRepo := repositories.NewPricingRuleRepository(db)
 	userAccessRepo := repositories.NewUserAccessRepository(db)
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(db)
+	loginAuditRepo := repositories.NewLoginAuditRepository(db)
+	passwordResetRepo := repositories.NewPasswordResetRepository(db)
 	transactionRepo := repositories.NewTransactionRepository(db)
-	paymentMethodRepo := repositories.NewPaymentMethodRepository(db)
+	paymentMethodRepo := repositories.NewPaymentMethodRepository(db, secretCipher)
+	paymentRepo := repositories.NewPaymentRepository(db)
+	idempotencyKeyRepo := repositories.NewIdempotencyKeyRepository(db)
+
+	// Initialize payment gateway adapters
+	paymentGateways := gateway.NewResolver(nil)
 
 	// Initialize usecases
-	authUsecase := usecases.NewAuthUsecase(userRepo)
+	authUsecase := usecases.NewAuthUsecase(userRepo, loginAuditRepo, tokenService, config.Login)
 	brandUsecase := usecases.NewBrandUsecase(brandRepo)
 	cabangUsecase := usecases.NewCabangUsecase(cabangRepo)
-	outletUsecase := usecases.NewOutletUsecase(outletRepo)
+	outletUsecase := usecases.NewOutletUsecase(outletRepo, cabangRepo)
+	calendarUsecase := usecases.NewCalendarUsecase(calendarRepo, outletRepo)
 	inquiryUsecase := usecases.NewInquiryUsecase(inquiryRepo, userAccessRepo, cabangRepo,
-		outletRepo,
-		employeeRepo, paymentMethodRepo, serviceRepo)
-	employeeUsecase := usecases.NewEmployeeUsecase(employeeRepo)
-	customerUsecase := usecases.NewCustomerUsecase(customerRepo)
-	serviceUsecase := usecases.NewServiceUsecase(serviceRepo)
+		outletRepo, calendarRepo,
+		employeeRepo, customerRepo, paymentMethodRepo, serviceRepo, modifierRepo, pricingRuleRepo, paymentGateways)
+	employeeUsecase := usecases.NewEmployeeUsecase(employeeRepo, outletRepo)
+	customerUsecase := usecases.NewCustomerUsecase(customerRepo, outletRepo)
+	serviceUsecase := usecases.NewServiceUsecase(serviceRepo, brandRepo)
 	serviceCategoryUsecase := usecases.NewServiceCategoryUsecase(serviceCategoryRepo)
+	modifierUsecase := usecases.NewModifierUsecase(modifierRepo, brandRepo, serviceRepo, serviceCategoryRepo)
+	pricingRuleUsecase := usecases.NewPricingRuleUsecase(pricingRuleRepo, serviceRepo, outletRepo, brandRepo)
 	userAccessUsecase := usecases.NewUserAccessUsecase(
 		userAccessRepo,
+		brandRepo,
 		cabangRepo,
 		outletRepo,
 		employeeRepo,
-		"laundry-secret-key",
-		24*60*60,
-	) // 24 hours
-	transactionUsecase := usecases.NewTransactionUsecase(transactionRepo)
+		refreshTokenRepo,
+		loginAuditRepo,
+		passwordResetRepo,
+		tokenService,
+		time.Duration(config.JWT.RefreshExpire)*time.Hour,
+		config.Login,
+		config.Password,
+	)
+	transactionUsecase := usecases.NewTransactionUsecase(transactionRepo, outletRepo, paymentRepo, paymentMethodRepo,
+		paymentGateways)
 	paymentMethodUsecase := usecases.NewPaymentMethodUsecase(paymentMethodRepo)
+	paymentUsecase := usecases.NewPaymentUsecase(paymentRepo, transactionRepo, paymentMethodRepo, paymentGateways)
+	idempotencyUsecase := usecases.NewIdempotencyUsecase(idempotencyKeyRepo, time.Duration(config.Idempotency.Expire)*time.Hour)
 
 	// Initialize handlers
 	authHandler := delivery.NewAuthHandler(authUsecase)
 	brandHandler := delivery.NewBrandHandler(brandUsecase)
 	cabangHandler := delivery.NewCabangHandler(cabangUsecase)
 	outletHandler := delivery.NewOutletHandler(outletUsecase)
+	calendarHandler := delivery.NewCalendarHandler(calendarUsecase)
 	inquiryHandler := delivery.NewInquiryHandler(inquiryUsecase)
 	employeeHandler := delivery.NewEmployeeHandler(employeeUsecase)
 	customerHandler := delivery.NewCustomerHandler(customerUsecase)
 	serviceHandler := delivery.NewServiceHandler(serviceUsecase)
 	serviceCategoryHandler := delivery.NewServiceCategoryHandler(serviceCategoryUsecase)
-	userAccessHandler := delivery.NewUserAccessHandler(userAccessUsecase, "laundry-secret-key")
+	modifierHandler := delivery.NewModifierHandler(modifierUsecase)
+	pricingRuleHandler := delivery.NewPricingRuleHandler(pricingRuleUsecase)
+	userAccessHandler := delivery.NewUserAccessHandler(userAccessUsecase)
 	transactionHandler := delivery.NewTransactionHandler(transactionUsecase)
 	paymentMethodHandler := delivery.NewPaymentMethodHandler(paymentMethodUsecase)
+	paymentHandler := delivery.NewPaymentHandler(paymentUsecase)
 
 	// Initialize Echo instance
 	e := echo.New()
 
+	requestValidator, err := delivery.NewRequestValidator()
+	if err != nil {
+		log.Fatal("Cannot initialize request validator:", err)
+	}
+	e.Validator = requestValidator
+	e.HTTPErrorHandler = delivery.HTTPErrorHandler
+
+	// The client IP of the login audit and throttling is the peer address, or the X-Forwarded-For entry
+	// added by a configured reverse proxy; anything else a client sends in the header is ignored
+	trustedProxies, err := utils.ParseTrustedProxies(config.Server.TrustedProxies)
+	if err != nil {
+		log.Fatal("Cannot load trusted proxies:", err)
+	}
+	e.IPExtractor = echo.ExtractIPDirect()
+	if len(trustedProxies) > 0 {
+		options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+		for _, proxy := range trustedProxies {
+			options = append(options, echo.TrustIPRange(proxy))
+		}
+		e.IPExtractor = echo.ExtractIPFromXFFHeader(options...)
+	}
+
 	// Middleware
 	e.Use(echoMiddleware.Logger())
 	e.Use(echoMiddleware.Recover())
@@ -106,95 +173,145 @@ func main() {
 
 	// Routes
 	// Auth routes
+	// Super admin login, the token it issues is the only one accepted by /api/v1/admin
 	e.POST("/api/v1/login", authHandler.Login)
 	e.POST("/api/v1/employee/login", userAccessHandler.UserLogin)
-	// administrator
+	// Refresh tokens are their own credential, so these stay outside the JWT protected group
+	e.POST("/api/v1/token/refresh", userAccessHandler.RefreshToken)
+	e.POST("/api/v1/logout", userAccessHandler.Logout)
+	e.POST("/api/v1/password/reset", userAccessHandler.ResetPassword)
+	// Payment gateway callback, signed with the metode_pembayaran keys instead of a staff JWT
+	e.POST("/api/v1/transactions/payment-callback", paymentHandler.ProcessPaymentCallback)
+	// Administrator routes, only for users table accounts logged in through /api/v1/login
+	admin := e.Group("/api/v1/admin")
 	{
+		admin.Use(middleware.JWT(tokenService))
+		admin.Use(middleware.RequireAudience(utils.AudienceAdmin))
 		// Brand routes
-		e.POST("/brands", brandHandler.CreateBrand)
-		e.GET("/brands/:id", brandHandler.GetBrandByID)
-		e.GET("/brands", brandHandler.GetAllBrands)
-		e.PUT("/brands/:id", brandHandler.UpdateBrand)
-		e.DELETE("/brands/:id", brandHandler.DeleteBrand)
+		admin.POST("/brands", brandHandler.CreateBrand, middleware.Authorize(middleware.ResourceBrand, middleware.ActionCreate))
+		admin.GET("/brands/:id", brandHandler.GetBrandByID, middleware.Authorize(middleware.ResourceBrand, middleware.ActionRead))
+		admin.GET("/brands", brandHandler.GetAllBrands, middleware.Authorize(middleware.ResourceBrand, middleware.ActionRead))
+		admin.PUT("/brands/:id", brandHandler.UpdateBrand, middleware.Authorize(middleware.ResourceBrand, middleware.ActionUpdate))
+		admin.DELETE("/brands/:id", brandHandler.DeleteBrand, middleware.Authorize(middleware.ResourceBrand, middleware.ActionDelete))
 
 		// Cabang routes
-		e.POST("/cabangs", cabangHandler.CreateCabang)
-		e.GET("/cabangs/:id", cabangHandler.GetCabangByID)
-		e.GET("/cabangs/brand/:brand_id", cabangHandler.GetCabangsByBrandID)
-		e.GET("/cabangs", cabangHandler.GetAllCabangs)
-		e.PUT("/cabangs/:id", cabangHandler.UpdateCabang)
-		e.DELETE("/cabangs/:id", cabangHandler.DeleteCabang)
-
+		admin.POST("/cabangs", cabangHandler.CreateCabang, middleware.Authorize(middleware.ResourceCabang, middleware.ActionCreate))
+		admin.GET("/cabangs/:id", cabangHandler.GetCabangByID, middleware.Authorize(middleware.ResourceCabang, middleware.ActionRead))
+		admin.GET("/cabangs/brand/:brand_id", cabangHandler.GetCabangsByBrandID, middleware.Authorize(middleware.ResourceCabang, middleware.ActionRead))
+		admin.GET("/cabangs", cabangHandler.GetAllCabangs, middleware.Authorize(middleware.ResourceCabang, middleware.ActionRead))
+		admin.PUT("/cabangs/:id", cabangHandler.UpdateCabang, middleware.Authorize(middleware.ResourceCabang, middleware.ActionUpdate))
+		admin.DELETE("/cabangs/:id", cabangHandler.DeleteCabang, middleware.Authorize(middleware.ResourceCabang, middleware.ActionDelete))
 	}
 
 	// Protected routes
 	api := e.Group("/api/v1")
 	{
-		api.Use(echoMiddleware.JWT([]byte("laundry-secret-key")))
+		api.Use(middleware.JWT(tokenService))
+		api.Use(middleware.RequireAudience(utils.AudienceStaff, utils.AudienceAdmin))
+		api.Use(middleware.RequirePasswordChanged(userAccessUsecase, "/api/v1/user-access/:id/password"))
+
+		// Retried order and payment requests with the same Idempotency-Key get the first response back
+		idempotent := middleware.Idempotency(idempotencyUsecase)
+
 		// Outlet routes
-		api.POST("/outlets", outletHandler.CreateOutlet)
-		api.GET("/outlets/:id", outletHandler.GetOutletByID)
-		api.GET("/outlets/cabang/:cabang_id", outletHandler.GetOutletsByCabangID)
-		api.GET("/outlets", outletHandler.GetAllOutlets)
-		api.PUT("/outlets/:id", outletHandler.UpdateOutlet)
-		api.DELETE("/outlets/:id", outletHandler.DeleteOutlet)
+		api.POST("/outlets", outletHandler.CreateOutlet, middleware.Authorize(middleware.ResourceOutlet, middleware.ActionCreate))
+		api.GET("/outlets/:id", outletHandler.GetOutletByID, middleware.Authorize(middleware.ResourceOutlet, middleware.ActionRead))
+		api.GET("/outlets/cabang/:cabang_id", outletHandler.GetOutletsByCabangID, middleware.Authorize(middleware.ResourceOutlet, middleware.ActionRead))
+		api.GET("/outlets", outletHandler.GetAllOutlets, middleware.Authorize(middleware.ResourceOutlet, middleware.ActionRead))
+		api.PUT("/outlets/:id", outletHandler.UpdateOutlet, middleware.Authorize(middleware.ResourceOutlet, middleware.ActionUpdate))
+		api.DELETE("/outlets/:id", outletHandler.DeleteOutlet, middleware.Authorize(middleware.ResourceOutlet, middleware.ActionDelete))
+
+		// Outlet calendar routes
+		api.GET("/outlets/:id/opening-hours", calendarHandler.GetOpeningHours, middleware.Authorize(middleware.ResourceOutlet, middleware.ActionRead))
+		api.PUT("/outlets/:id/opening-hours", calendarHandler.UpdateOpeningHours, middleware.Authorize(middleware.ResourceOutlet, middleware.ActionUpdate))
+		api.GET("/outlets/:id/holidays", calendarHandler.GetOutletHolidays, middleware.Authorize(middleware.ResourceOutlet, middleware.ActionRead))
+		api.GET("/outlets/:id/availability", calendarHandler.GetAvailability, middleware.Authorize(middleware.ResourceOutlet, middleware.ActionRead))
+		api.POST("/holidays", calendarHandler.CreateHoliday, middleware.Authorize(middleware.ResourceHoliday, middleware.ActionCreate))
+		api.DELETE("/holidays/:id", calendarHandler.DeleteHoliday, middleware.Authorize(middleware.ResourceHoliday, middleware.ActionDelete))
 
 		// Inquiry routes
-		api.POST("/inquiry", inquiryHandler.ProcessInquiry)
+		api.POST("/inquiry", inquiryHandler.ProcessInquiry, middleware.Authorize(middleware.ResourceInquiry, middleware.ActionCreate), idempotent)
+		api.POST("/inquiry/quote", inquiryHandler.QuoteInquiry, middleware.Authorize(middleware.ResourceInquiry, middleware.ActionCreate))
 
 		// Employee routes
-		api.POST("/pegawai", employeeHandler.CreateEmployee)
-		api.GET("/pegawai/:id", employeeHandler.GetEmployeeByID)
-		api.GET("/pegawai", employeeHandler.GetAllEmployees)
-		api.PUT("/pegawai/:id", employeeHandler.UpdateEmployee)
-		api.DELETE("/pegawai/:id", employeeHandler.DeleteEmployee)
+		api.POST("/pegawai", employeeHandler.CreateEmployee, middleware.Authorize(middleware.ResourceEmployee, middleware.ActionCreate))
+		api.GET("/pegawai/:id", employeeHandler.GetEmployeeByID, middleware.Authorize(middleware.ResourceEmployee, middleware.ActionRead))
+		api.GET("/pegawai", employeeHandler.GetAllEmployees, middleware.Authorize(middleware.ResourceEmployee, middleware.ActionRead))
+		api.PUT("/pegawai/:id", employeeHandler.UpdateEmployee, middleware.Authorize(middleware.ResourceEmployee, middleware.ActionUpdate))
+		api.DELETE("/pegawai/:id", employeeHandler.DeleteEmployee, middleware.Authorize(middleware.ResourceEmployee, middleware.ActionDelete))
 
 		// Customer routes
-		api.POST("/pelanggan", customerHandler.CreateCustomer)
-		api.GET("/pelanggan/:id", customerHandler.GetCustomerByID)
-		api.GET("/pelanggan/outlet/:outlet_id", customerHandler.GetCustomersByOutletID)
-		api.GET("/pelanggan", customerHandler.GetAllCustomers)
-		api.PUT("/pelanggan/:id", customerHandler.UpdateCustomer)
-		api.DELETE("/pelanggan/:id", customerHandler.DeleteCustomer)
+		api.POST("/pelanggan", customerHandler.CreateCustomer, middleware.Authorize(middleware.ResourceCustomer, middleware.ActionCreate))
+		api.GET("/pelanggan/:id", customerHandler.GetCustomerByID, middleware.Authorize(middleware.ResourceCustomer, middleware.ActionRead))
+		api.GET("/pelanggan/outlet/:outlet_id", customerHandler.GetCustomersByOutletID, middleware.Authorize(middleware.ResourceCustomer, middleware.ActionRead))
+		api.GET("/pelanggan", customerHandler.GetAllCustomers, middleware.Authorize(middleware.ResourceCustomer, middleware.ActionRead))
+		api.PUT("/pelanggan/:id", customerHandler.UpdateCustomer, middleware.Authorize(middleware.ResourceCustomer, middleware.ActionUpdate))
+		api.DELETE("/pelanggan/:id", customerHandler.DeleteCustomer, middleware.Authorize(middleware.ResourceCustomer, middleware.ActionDelete))
 
 		// Service routes
-		api.POST("/services", serviceHandler.CreateService)
-		api.GET("/services/:id", serviceHandler.GetServiceByID)
-		api.GET("/services", serviceHandler.GetAllServices)
-		api.PUT("/services/:id", serviceHandler.UpdateService)
-		api.DELETE("/services/:id", serviceHandler.DeleteService)
-		api.GET("/services/category/:category_id", serviceHandler.GetServicesByCategoryID)
+		api.POST("/services", serviceHandler.CreateService, middleware.Authorize(middleware.ResourceService, middleware.ActionCreate))
+		api.GET("/services/:id", serviceHandler.GetServiceByID, middleware.Authorize(middleware.ResourceService, middleware.ActionRead))
+		api.GET("/services", serviceHandler.GetAllServices, middleware.Authorize(middleware.ResourceService, middleware.ActionRead))
+		api.PUT("/services/:id", serviceHandler.UpdateService, middleware.Authorize(middleware.ResourceService, middleware.ActionUpdate))
+		api.DELETE("/services/:id", serviceHandler.DeleteService, middleware.Authorize(middleware.ResourceService, middleware.ActionDelete))
+		api.GET("/services/category/:category_id", serviceHandler.GetServicesByCategoryID, middleware.Authorize(middleware.ResourceService, middleware.ActionRead))
+
+		// Pricing rule routes (minimum charge, weight rounding and tiered prices)
+		api.GET("/services/:id/pricing-rules", pricingRuleHandler.GetPricingRules, middleware.Authorize(middleware.ResourceService, middleware.ActionRead))
+		api.PUT("/services/:id/pricing-rules", pricingRuleHandler.SavePricingRule, middleware.Authorize(middleware.ResourceService, middleware.ActionUpdate))
+		api.DELETE("/pricing-rules/:id", pricingRuleHandler.DeletePricingRule, middleware.Authorize(middleware.ResourceService, middleware.ActionDelete))
 
 		// Service Category routes
-		api.POST("/service-categories", serviceCategoryHandler.CreateServiceCategory)
-		api.GET("/service-categories/:id", serviceCategoryHandler.GetServiceCategoryByID)
-		api.GET("/service-categories", serviceCategoryHandler.GetAllServiceCategories)
-		api.PUT("/service-categories/:id", serviceCategoryHandler.UpdateServiceCategory)
-		api.DELETE("/service-categories/:id", serviceCategoryHandler.DeleteServiceCategory)
+		api.POST("/service-categories", serviceCategoryHandler.CreateServiceCategory, middleware.Authorize(middleware.ResourceServiceCategory, middleware.ActionCreate))
+		api.GET("/service-categories/:id", serviceCategoryHandler.GetServiceCategoryByID, middleware.Authorize(middleware.ResourceServiceCategory, middleware.ActionRead))
+		api.GET("/service-categories", serviceCategoryHandler.GetAllServiceCategories, middleware.Authorize(middleware.ResourceServiceCategory, middleware.ActionRead))
+		api.PUT("/service-categories/:id", serviceCategoryHandler.UpdateServiceCategory, middleware.Authorize(middleware.ResourceServiceCategory, middleware.ActionUpdate))
+		api.DELETE("/service-categories/:id", serviceCategoryHandler.DeleteServiceCategory, middleware.Authorize(middleware.ResourceServiceCategory, middleware.ActionDelete))
+
+		// Modifier routes (surcharges and add-ons)
+		api.POST("/modifiers", modifierHandler.CreateModifier, middleware.Authorize(middleware.ResourceModifier, middleware.ActionCreate))
+		api.GET("/modifiers/:id", modifierHandler.GetModifierByID, middleware.Authorize(middleware.ResourceModifier, middleware.ActionRead))
+		api.GET("/modifiers", modifierHandler.GetModifiers, middleware.Authorize(middleware.ResourceModifier, middleware.ActionRead))
+		api.PUT("/modifiers/:id", modifierHandler.UpdateModifier, middleware.Authorize(middleware.ResourceModifier, middleware.ActionUpdate))
+		api.DELETE("/modifiers/:id", modifierHandler.DeleteModifier, middleware.Authorize(middleware.ResourceModifier, middleware.ActionDelete))
 
 		// User Access routes
-		api.POST("/user-access", userAccessHandler.CreateUserAccess)
-		api.GET("/user-access/:id", userAccessHandler.GetUserAccessByID)
-		api.GET("/user-access", userAccessHandler.GetAllUserAccessDataTables)
-		api.PUT("/user-access/:id", userAccessHandler.UpdateUserAccess)
-		api.PUT("/user-access/:id/password", userAccessHandler.UpdateUserPassword)
-		api.DELETE("/user-access/:id", userAccessHandler.DeleteUserAccess)
+		api.POST("/user-access", userAccessHandler.CreateUserAccess, middleware.Authorize(middleware.ResourceUserAccess, middleware.ActionCreate))
+		api.GET("/user-access/:id", userAccessHandler.GetUserAccessByID, middleware.Authorize(middleware.ResourceUserAccess, middleware.ActionRead))
+		api.GET("/user-access", userAccessHandler.GetAllUserAccessDataTables, middleware.Authorize(middleware.ResourceUserAccess, middleware.ActionRead))
+		api.PUT("/user-access/:id", userAccessHandler.UpdateUserAccess, middleware.Authorize(middleware.ResourceUserAccess, middleware.ActionUpdate))
+		api.PUT("/user-access/:id/password", userAccessHandler.UpdateUserPassword, middleware.AuthorizeSelf("id", middleware.ResourceUserAccess, middleware.ActionUpdate))
+		api.DELETE("/user-access/:id", userAccessHandler.DeleteUserAccess, middleware.Authorize(middleware.ResourceUserAccess, middleware.ActionDelete))
+		api.POST("/user-access/:id/password-reset", userAccessHandler.IssuePasswordReset, middleware.Authorize(middleware.ResourceUserAccess, middleware.ActionResetPassword))
+		api.POST("/user-access/:id/unlock", userAccessHandler.UnlockUserAccess, middleware.Authorize(middleware.ResourceUserAccess, middleware.ActionUnlock))
+		api.POST("/user-access/:id/revoke-sessions", userAccessHandler.RevokeSessions, middleware.Authorize(middleware.ResourceUserAccess, middleware.ActionRevokeSessions))
 
 		// Transaction routes
-		api.GET("/transactions", transactionHandler.GetAllTransactions)
-		api.GET("/transactions/:id", transactionHandler.GetTransactionByID)
-		api.GET("/transactions/outlet/:outlet_id", transactionHandler.GetTransactionsByOutletID)
-		api.GET("/transactions/:id/details", transactionHandler.GetTransactionDetails)
-		api.PUT("/transactions/:id/status", transactionHandler.UpdateTransactionStatus)
-		api.PUT("/transactions/:id/payment-status", transactionHandler.UpdatePaymentStatus)
-		api.POST("/transactions/payment-callback", transactionHandler.ProcessPaymentCallback)
+		api.GET("/transactions", transactionHandler.GetAllTransactions, middleware.Authorize(middleware.ResourceTransaction, middleware.ActionRead))
+		api.GET("/transactions/:id", transactionHandler.GetTransactionByID, middleware.Authorize(middleware.ResourceTransaction, middleware.ActionRead))
+		api.GET("/transactions/outlet/:outlet_id", transactionHandler.GetTransactionsByOutletID, middleware.Authorize(middleware.ResourceTransaction, middleware.ActionRead))
+		api.GET("/transactions/:id/details", transactionHandler.GetTransactionDetails, middleware.Authorize(middleware.ResourceTransaction, middleware.ActionRead))
+		api.GET("/transactions/:id/history", transactionHandler.GetTransactionHistory, middleware.Authorize(middleware.ResourceTransaction, middleware.ActionRead))
+		api.PUT("/transactions/:id/status", transactionHandler.UpdateTransactionStatus, middleware.Authorize(middleware.ResourceTransaction, middleware.ActionUpdate))
+		api.POST("/transactions/:id/cancel", transactionHandler.CancelTransaction, middleware.Authorize(middleware.ResourceTransaction, middleware.ActionCancel))
+
+		// Report routes
+		api.GET("/reports/revenue", transactionHandler.GetRevenueReport, middleware.Authorize(middleware.ResourceReport, middleware.ActionRead))
+
+		// Payment routes
+		api.POST("/transactions/:id/payments", paymentHandler.CreatePayment, middleware.Authorize(middleware.ResourcePayment, middleware.ActionCreate), idempotent)
+		api.GET("/transactions/:id/payments", paymentHandler.GetPaymentsByTransactionID, middleware.Authorize(middleware.ResourcePayment, middleware.ActionRead))
+		api.PUT("/payments/:id/status", paymentHandler.UpdatePaymentStatus, middleware.Authorize(middleware.ResourcePayment, middleware.ActionUpdate), idempotent)
+		api.POST("/payments/:id/sync", paymentHandler.SyncPaymentStatus, middleware.Authorize(middleware.ResourcePayment, middleware.ActionUpdate), idempotent)
 
 		// Payment Method routes
-		api.POST("/payment-methods", paymentMethodHandler.CreatePaymentMethod)
-		api.GET("/payment-methods/:id", paymentMethodHandler.GetPaymentMethodByID)
-		api.GET("/payment-methods", paymentMethodHandler.GetAllPaymentMethods)
-		api.PUT("/payment-methods/:id", paymentMethodHandler.UpdatePaymentMethod)
-		api.DELETE("/payment-methods/:id", paymentMethodHandler.DeletePaymentMethod)
+		api.POST("/payment-methods", paymentMethodHandler.CreatePaymentMethod, middleware.Authorize(middleware.ResourcePaymentMethod, middleware.ActionCreate))
+		api.GET("/payment-methods/:id", paymentMethodHandler.GetPaymentMethodByID, middleware.Authorize(middleware.ResourcePaymentMethod, middleware.ActionRead))
+		api.GET("/payment-methods", paymentMethodHandler.GetAllPaymentMethods, middleware.Authorize(middleware.ResourcePaymentMethod, middleware.ActionRead))
+		api.PUT("/payment-methods/:id", paymentMethodHandler.UpdatePaymentMethod, middleware.Authorize(middleware.ResourcePaymentMethod, middleware.ActionUpdate))
+		api.PUT("/payment-methods/:id/secrets", paymentMethodHandler.UpdatePaymentMethodSecrets, middleware.Authorize(middleware.ResourcePaymentMethod, middleware.ActionManageSecrets))
+		api.POST("/payment-methods/secrets/rotate", paymentMethodHandler.RotatePaymentMethodSecrets, middleware.Authorize(middleware.ResourcePaymentMethod, middleware.ActionManageSecrets))
+		api.DELETE("/payment-methods/:id", paymentMethodHandler.DeletePaymentMethod, middleware.Authorize(middleware.ResourcePaymentMethod, middleware.ActionDelete))
 	}
 	// Start server
 	// e.Logger.Fatal(e.Start(config.Server.Address))
@@ -15,6 +15,8 @@ CREATE TABLE IF NOT EXISTS users (
     password VARCHAR(255) NOT NULL,
     name VARCHAR(100) NOT NULL,
     role VARCHAR(50) DEFAULT 'admin',
+    failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -29,6 +31,8 @@ CREATE TABLE brand (
     pic_email VARCHAR(100),
     pic_telepon VARCHAR(20),
     logo_url VARCHAR(255),
+    kode_brand VARCHAR(10) NOT NULL DEFAULT '',
+    format_invoice VARCHAR(100) NOT NULL DEFAULT '{BRAND}-{OUTLET}-{YYMM}-{SEQ:5}',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -70,6 +74,9 @@ CREATE TABLE outlet (
     pic_nama VARCHAR(100),
     pic_email VARCHAR(100),
     pic_telepon VARCHAR(20),
+    wajib_lunas_saat_diambil BOOLEAN DEFAULT true,
+    kode_outlet VARCHAR(10) NOT NULL DEFAULT '',
+    zona_waktu VARCHAR(50) NOT NULL DEFAULT 'Asia/Jakarta',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (id_cabang) REFERENCES cabang(id_cabang)
@@ -148,12 +155,17 @@ CREATE TABLE transaksi (
     tanggal_masuk TIMESTAMP,
     tanggal_selesai TIMESTAMP,
     tanggal_diambil TIMESTAMP,
+    estimasi_selesai TIMESTAMP,
     -- berat_laundry DECIMAL(5, 2),
     total_harga DECIMAL(15, 2),
+    subtotal_layanan DECIMAL(15, 2),
+    biaya_admin DECIMAL(15, 2) DEFAULT 0,
+    biaya_merchant DECIMAL(15, 2) DEFAULT 0,
+    biaya_ditanggung VARCHAR(10) DEFAULT 'customer' CHECK (biaya_ditanggung IN ('customer', 'merchant')),
     uang_bayar DECIMAL(15, 2),
     uang_kembalian DECIMAL(15, 2),
 
-    status_transaksi VARCHAR(20) DEFAULT 'diterima' CHECK (status_transaksi IN ('diterima', 'diproses', 'selesai', 'diambil')),
+    status_transaksi VARCHAR(20) DEFAULT 'diterima' CHECK (status_transaksi IN ('diterima', 'diproses', 'selesai', 'diambil', 'dibatalkan')),
     -- status_pembayaran VARCHAR(20) DEFAULT 'belum lunas' CHECK (status_pembayaran IN ('lunas', 'belum lunas')),
     -- metode_pembayaran VARCHAR(20) DEFAULT 'tunai' CHECK (metode_pembayaran IN ('tunai', 'transfer', 'e-wallet')),
     catatan TEXT,
@@ -177,11 +189,16 @@ CREATE TABLE detail_transaksi (
     id_transaksi INTEGER NOT NULL,
     id_layanan INTEGER NOT NULL,
     kuantitas DECIMAL(5, 2),
+    kuantitas_ditagih DECIMAL(7, 2),
     harga_satuan DECIMAL(10, 2),
+    harga_dasar DECIMAL(15, 2),
+    total_biaya_tambahan DECIMAL(15, 2) NOT NULL DEFAULT 0,
     subtotal DECIMAL(15, 2),
+    estimasi_selesai TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     status_pengerjaan VARCHAR(100),
+    catatan TEXT,
     created_by VARCHAR(100),
     updated_by VARCHAR(100),
     FOREIGN KEY (id_transaksi) REFERENCES transaksi(id_transaksi) ON DELETE CASCADE,
@@ -251,10 +268,11 @@ CREATE TABLE presensi (
 CREATE TABLE history_status_transaksi (
     id_history SERIAL PRIMARY KEY,
     id_transaksi INTEGER NOT NULL,
-    status_lama VARCHAR(20) CHECK (status_lama IN ('diterima', 'diproses', 'selesai', 'diambil')),
-    status_baru VARCHAR(20) NOT NULL CHECK (status_baru IN ('diterima', 'diproses', 'selesai', 'diambil')),
+    status_lama VARCHAR(20) CHECK (status_lama IN ('diterima', 'diproses', 'selesai', 'diambil', 'dibatalkan')),
+    status_baru VARCHAR(20) NOT NULL CHECK (status_baru IN ('diterima', 'diproses', 'selesai', 'diambil', 'dibatalkan')),
     waktu_perubahan TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     keterangan TEXT,
+    diubah_oleh VARCHAR(100),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (id_transaksi) REFERENCES transaksi(id_transaksi) ON DELETE CASCADE
@@ -270,11 +288,26 @@ CREATE TABLE IF NOT EXISTS pembayaran (
     nomor_referensi_partner VARCHAR(50),
     status_code_partner VARCHAR(5),
     status_message_partner VARCHAR(100),
+    status_pembayaran VARCHAR(20) DEFAULT 'pending' CHECK (status_pembayaran IN ('gagal', 'pending', 'sukses')),
     catatan TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (id_transaksi) REFERENCES transaksi(id_transaksi) ON DELETE CASCADE
 );
+-- Tabel Refund Pembayaran (refund penuh/sebagian saat transaksi dibatalkan)
+CREATE TABLE IF NOT EXISTS refund_pembayaran (
+    id_refund SERIAL PRIMARY KEY,
+    id_pembayaran INTEGER NOT NULL,
+    id_transaksi INTEGER NOT NULL,
+    nomor_referensi_partner VARCHAR(50),
+    jumlah_refund DECIMAL(12, 2) NOT NULL CHECK (jumlah_refund >= 0),
+    status_refund VARCHAR(20) DEFAULT 'pending' CHECK (status_refund IN ('gagal', 'pending', 'sukses')),
+    alasan TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(100),
+    FOREIGN KEY (id_pembayaran) REFERENCES pembayaran(id_pembayaran) ON DELETE CASCADE,
+    FOREIGN KEY (id_transaksi) REFERENCES transaksi(id_transaksi) ON DELETE CASCADE
+);
 CREATE TABLE IF NOT EXISTS user_access (
     id_access SERIAL PRIMARY KEY,
     username VARCHAR(50) UNIQUE NOT NULL,
@@ -284,6 +317,9 @@ CREATE TABLE IF NOT EXISTS user_access (
     last_login TIMESTAMP,
     reference_level VARCHAR(10),
     reference_id INTEGER,
+    failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    must_change_password BOOLEAN NOT NULL DEFAULT false,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -295,6 +331,8 @@ CREATE TABLE IF NOT EXISTS metode_pembayaran (
     m_key TEXT,
     merchant_fee DECIMAL(10,2) DEFAULT 0.00,
     admin_fee DECIMAL(10,2) DEFAULT 0.00,
+    merchant_fee_type VARCHAR(10) DEFAULT 'flat' CHECK (merchant_fee_type IN ('flat', 'percent')),
+    fee_bearer VARCHAR(10) DEFAULT 'customer' CHECK (fee_bearer IN ('customer', 'merchant')),
     status VARCHAR(20) DEFAULT 'active',     -- active/inactive
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -302,6 +340,184 @@ CREATE TABLE IF NOT EXISTS metode_pembayaran (
     updated_by VARCHAR(100)
 );
 
+-- Tabel Refresh Token (sesi login user_access, token disimpan sebagai hash SHA-256)
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id_refresh_token SERIAL PRIMARY KEY,
+    id_access INTEGER NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expired_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    replaced_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_access) REFERENCES user_access(id_access) ON DELETE CASCADE,
+    FOREIGN KEY (replaced_by) REFERENCES refresh_token(id_refresh_token)
+);
+
+-- Tabel Riwayat Password (hash password lama user_access, untuk menolak pemakaian ulang)
+CREATE TABLE IF NOT EXISTS password_history (
+    id_password_history SERIAL PRIMARY KEY,
+    id_access INTEGER NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_access) REFERENCES user_access(id_access) ON DELETE CASCADE
+);
+
+-- Tabel Token Reset Password (sekali pakai, diterbitkan admin, disimpan sebagai hash SHA-256)
+CREATE TABLE IF NOT EXISTS password_reset_token (
+    id_password_reset SERIAL PRIMARY KEY,
+    id_access INTEGER NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expired_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_access) REFERENCES user_access(id_access) ON DELETE CASCADE
+);
+
+-- Tabel Audit Login (setiap percobaan login users dan user_access)
+CREATE TABLE IF NOT EXISTS login_audit (
+    id_login_audit SERIAL PRIMARY KEY,
+    login_type VARCHAR(20) NOT NULL CHECK (login_type IN ('users', 'user_access')),
+    username VARCHAR(100) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tabel Callback Pembayaran (audit callback mentah dari payment gateway)
+CREATE TABLE IF NOT EXISTS callback_pembayaran (
+    id_callback SERIAL PRIMARY KEY,
+    id_metode_pembayaran INTEGER,
+    id_transaksi INTEGER,
+    nomor_referensi_pembayaran VARCHAR(50),
+    payload TEXT NOT NULL,
+    signature VARCHAR(128),
+    signature_valid BOOLEAN DEFAULT false,
+    hasil VARCHAR(20) NOT NULL CHECK (hasil IN ('diproses', 'duplikat', 'ditolak')),
+    pesan TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tabel Urutan Invoice (nomor urut invoice terakhir per outlet per periode)
+CREATE TABLE IF NOT EXISTS urutan_invoice (
+    id_outlet INTEGER NOT NULL,
+    periode VARCHAR(8) NOT NULL,
+    nomor_terakhir INTEGER NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_outlet, periode),
+    FOREIGN KEY (id_outlet) REFERENCES outlet(id_outlet) ON DELETE CASCADE
+);
+
+-- Tabel Idempotency Key (response tersimpan untuk retry request dengan header Idempotency-Key)
+CREATE TABLE IF NOT EXISTS idempotency_key (
+    id_idempotency_key SERIAL PRIMARY KEY,
+    owner VARCHAR(100) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INTEGER,
+    response_body TEXT,
+    expired_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (owner, idempotency_key)
+);
+
+-- Jam operasional outlet per hari (0 = Minggu ... 6 = Sabtu)
+CREATE TABLE IF NOT EXISTS jam_operasional_outlet (
+    id_outlet INTEGER NOT NULL,
+    hari SMALLINT NOT NULL CHECK (hari BETWEEN 0 AND 6),
+    jam_buka TIME,
+    jam_tutup TIME,
+    tutup BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_outlet, hari),
+    CHECK (tutup OR (jam_buka IS NOT NULL AND jam_tutup IS NOT NULL)),
+    FOREIGN KEY (id_outlet) REFERENCES outlet(id_outlet) ON DELETE CASCADE
+);
+
+-- Hari libur nasional, per brand atau per outlet
+CREATE TABLE IF NOT EXISTS hari_libur (
+    id_libur SERIAL PRIMARY KEY,
+    jenis VARCHAR(10) NOT NULL DEFAULT 'outlet' CHECK (jenis IN ('nasional', 'brand', 'outlet')),
+    id_brand INTEGER,
+    id_outlet INTEGER,
+    tanggal DATE NOT NULL,
+    keterangan VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT hari_libur_jenis_referensi_check CHECK (
+        (jenis = 'nasional' AND id_brand IS NULL AND id_outlet IS NULL) OR
+        (jenis = 'brand' AND id_brand IS NOT NULL AND id_outlet IS NULL) OR
+        (jenis = 'outlet' AND id_outlet IS NOT NULL AND id_brand IS NULL)
+    ),
+    FOREIGN KEY (id_brand) REFERENCES brand(id_brand) ON DELETE CASCADE,
+    FOREIGN KEY (id_outlet) REFERENCES outlet(id_outlet) ON DELETE CASCADE
+);
+
+-- Modifier layanan: surcharge (express) dan add-on (parfum, hanger, plastik) milik brand
+CREATE TABLE IF NOT EXISTS modifier_layanan (
+    id_modifier SERIAL PRIMARY KEY,
+    id_brand INTEGER NOT NULL,
+    id_layanan INTEGER,
+    id_kategori INTEGER,
+    nama VARCHAR(100) NOT NULL,
+    jenis VARCHAR(10) NOT NULL CHECK (jenis IN ('surcharge', 'addon')),
+    tipe_nilai VARCHAR(10) NOT NULL CHECK (tipe_nilai IN ('persen', 'flat')),
+    nilai DECIMAL(10, 2) NOT NULL CHECK (nilai >= 0),
+    durasi_pengerjaan INTEGER,
+    satuan_durasi VARCHAR(10) CHECK (satuan_durasi IN ('jam', 'hari')),
+    aktif BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (jenis = 'surcharge' OR tipe_nilai = 'flat'),
+    CHECK (id_layanan IS NULL OR id_kategori IS NULL),
+    FOREIGN KEY (id_brand) REFERENCES brand(id_brand) ON DELETE CASCADE,
+    FOREIGN KEY (id_layanan) REFERENCES paket_layanan(id_layanan) ON DELETE CASCADE,
+    FOREIGN KEY (id_kategori) REFERENCES kategori_layanan(id_kategori) ON DELETE CASCADE
+);
+
+-- Salinan modifier yang dipilih pada detail transaksi
+CREATE TABLE IF NOT EXISTS detail_transaksi_modifier (
+    id SERIAL PRIMARY KEY,
+    id_detail INTEGER NOT NULL,
+    id_modifier INTEGER,
+    nama VARCHAR(100) NOT NULL,
+    jenis VARCHAR(10) NOT NULL,
+    tipe_nilai VARCHAR(10) NOT NULL,
+    nilai DECIMAL(10, 2) NOT NULL,
+    kuantitas DECIMAL(5, 2) NOT NULL DEFAULT 1,
+    jumlah DECIMAL(15, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_detail) REFERENCES detail_transaksi(id_detail) ON DELETE CASCADE,
+    FOREIGN KEY (id_modifier) REFERENCES modifier_layanan(id_modifier) ON DELETE SET NULL
+);
+
+-- Aturan harga per layanan (dan per outlet): harga minimum, pembulatan kuantitas dan harga bertingkat
+CREATE TABLE IF NOT EXISTS aturan_harga (
+    id_aturan SERIAL PRIMARY KEY,
+    id_layanan INTEGER NOT NULL,
+    id_outlet INTEGER,
+    harga_satuan DECIMAL(10, 2) CHECK (harga_satuan >= 0),
+    kuantitas_minimum DECIMAL(7, 2) NOT NULL DEFAULT 0 CHECK (kuantitas_minimum >= 0),
+    pembulatan DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK (pembulatan >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_layanan) REFERENCES paket_layanan(id_layanan) ON DELETE CASCADE,
+    FOREIGN KEY (id_outlet) REFERENCES outlet(id_outlet) ON DELETE CASCADE
+);
+
+-- Tingkat harga dari aturan harga
+CREATE TABLE IF NOT EXISTS tingkat_harga (
+    id_tingkat SERIAL PRIMARY KEY,
+    id_aturan INTEGER NOT NULL,
+    kuantitas_dari DECIMAL(7, 2) NOT NULL CHECK (kuantitas_dari > 0),
+    harga_satuan DECIMAL(10, 2) NOT NULL CHECK (harga_satuan >= 0),
+    UNIQUE (id_aturan, kuantitas_dari),
+    FOREIGN KEY (id_aturan) REFERENCES aturan_harga(id_aturan) ON DELETE CASCADE
+);
+
 
 
 -- Index untuk optimasi query
@@ -315,6 +531,23 @@ CREATE INDEX idx_cabang_brand ON cabang(id_brand);
 CREATE INDEX idx_paket_brand ON paket_layanan(id_brand);
 CREATE INDEX idx_layanan_kategori ON paket_layanan(id_kategori);
 CREATE INDEX idx_pembayaran_transaksi ON pembayaran(id_transaksi);
+CREATE INDEX idx_pembayaran_referensi ON pembayaran(nomor_referensi_partner);
+CREATE UNIQUE INDEX uq_pembayaran_referensi_metode ON pembayaran(id_metode_pembayaran, nomor_referensi_partner) WHERE nomor_referensi_partner <> '';
+CREATE INDEX idx_callback_pembayaran_referensi ON callback_pembayaran(nomor_referensi_pembayaran);
+CREATE INDEX idx_refund_pembayaran_transaksi ON refund_pembayaran(id_transaksi);
+CREATE INDEX idx_history_status_transaksi ON history_status_transaksi(id_transaksi);
+CREATE INDEX idx_refresh_token_access ON refresh_token(id_access);
+CREATE INDEX idx_login_audit_ip ON login_audit(ip_address, created_at);
+CREATE INDEX idx_login_audit_username ON login_audit(username, created_at);
+CREATE INDEX idx_password_history_access ON password_history(id_access, created_at);
+CREATE INDEX idx_password_reset_token_access ON password_reset_token(id_access);
+CREATE INDEX idx_idempotency_key_expired ON idempotency_key(expired_at);
+CREATE UNIQUE INDEX uq_outlet_kode ON outlet(kode_outlet) WHERE kode_outlet <> '';
+CREATE UNIQUE INDEX uq_hari_libur ON hari_libur(jenis, COALESCE(id_brand, 0), COALESCE(id_outlet, 0), tanggal);
+CREATE INDEX idx_hari_libur_tanggal ON hari_libur(tanggal);
+CREATE INDEX idx_modifier_layanan_brand ON modifier_layanan(id_brand);
+CREATE INDEX idx_detail_transaksi_modifier_detail ON detail_transaksi_modifier(id_detail);
+CREATE UNIQUE INDEX uq_aturan_harga ON aturan_harga(id_layanan, COALESCE(id_outlet, 0));
 -- Add indexes for faster queries
 CREATE INDEX IF NOT EXISTS idx_employee_access_username ON user_access(username);
 CREATE INDEX IF NOT EXISTS idx_employee_access_active ON user_access(is_active);
@@ -331,30 +564,8 @@ $ LANGUAGE plpgsql;
 -- Sequence untuk nomor invoice
 CREATE SEQUENCE IF NOT EXISTS invoice_seq START 1;
 
--- Trigger function untuk mencatat history status transaksi
-CREATE OR REPLACE FUNCTION trigger_history_status_transaksi()
-RETURNS TRIGGER AS $
-BEGIN
-    -- Mencatat history jika status transaksi berubah
-    IF OLD.status_transaksi IS DISTINCT FROM NEW.status_transaksi THEN
-        INSERT INTO history_status_transaksi (id_transaksi, status_lama, status_baru)
-        VALUES (NEW.id_transaksi, OLD.status_transaksi, NEW.status_transaksi);
-    END IF;
-    
-    -- Update status pembayaran jika uang bayar mencukupi total harga
-    IF NEW.uang_bayar >= NEW.total_harga AND NEW.status_pembayaran = 'belum lunas' THEN
-        NEW.status_pembayaran = 'lunas';
-    END IF;
-    
-    RETURN NEW;
-END;
-$ LANGUAGE plpgsql;
-
--- Trigger untuk transaksi
-CREATE TRIGGER trigger_transaksi_update
-    BEFORE UPDATE ON transaksi
-    FOR EACH ROW
-    EXECUTE FUNCTION trigger_history_status_transaksi();
+-- History status transaksi dicatat oleh aplikasi di dalam DB transaction yang sama
+-- dengan perubahan status (lihat migrations/transaction_status_history.sql)
 
 -- Function untuk pendaftaran pelanggan baru
 CREATE OR REPLACE FUNCTION sp_daftar_pelanggan_baru(